@@ -25,6 +25,8 @@ type ServerOption struct {
 	Threadiness   int
 	PrintVersion  bool
 	JSONLogFormat bool
+	ConfigFile    string
+	MetricsAddr   string
 }
 
 // NewServerOption creates a new CMServer with a default config.
@@ -46,4 +48,12 @@ func (s *ServerOption) AddFlags(fs *flag.FlagSet) {
 
 	fs.BoolVar(&s.JSONLogFormat, "json-log-format", false,
 		"Set true to use json style log format. Set false to use plaintext style log format")
+
+	fs.StringVar(&s.ConfigFile, "config", "",
+		`Path to the OperatorConfiguration file, reloaded when it changes.
+		 The defaults are used if it is not set.`)
+
+	fs.StringVar(&s.MetricsAddr, "metrics-addr", "",
+		`The address the prometheus metrics are served on, e.g. :8080.
+		 They are not served if it is not set.`)
 }
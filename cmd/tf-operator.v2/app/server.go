@@ -16,9 +16,10 @@ package app
 
 import (
 	"fmt"
+	"net/http"
 	"os"
-	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,6 +37,9 @@ import (
 	"github.com/kubeflow/tf-operator/pkg/client/clientset/versioned/scheme"
 	tfjobinformers "github.com/kubeflow/tf-operator/pkg/client/informers/externalversions"
 	controller "github.com/kubeflow/tf-operator/pkg/controller.v2"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/config"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/cron"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/sweep"
 	"github.com/kubeflow/tf-operator/pkg/util/signals"
 	"github.com/kubeflow/tf-operator/pkg/version"
 )
@@ -44,14 +48,6 @@ const (
 	apiVersion = "v1alpha2"
 )
 
-var (
-	// leader election config
-	leaseDuration = 15 * time.Second
-	renewDuration = 5 * time.Second
-	retryPeriod   = 3 * time.Second
-	resyncPeriod  = 30 * time.Second
-)
-
 const RecommendedKubeConfigPathEnv = "KUBECONFIG"
 
 func Run(opt *options.ServerOption) error {
@@ -79,6 +75,14 @@ func Run(opt *options.ServerOption) error {
 		opt.Kubeconfig = os.Getenv(RecommendedKubeConfigPathEnv)
 	}
 
+	// Load the configuration file, it is reloaded when it changes.
+	configStore, err := config.NewStore(opt.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("Failed to load the configuration file: %v", err)
+	}
+	go configStore.Run(stopCh)
+	cfg := configStore.Get()
+
 	// Get kubernetes config.
 	kcfg, err := clientcmd.BuildConfigFromFlags(opt.MasterURL, opt.Kubeconfig)
 	if err != nil {
@@ -92,24 +96,50 @@ func Run(opt *options.ServerOption) error {
 	}
 
 	// Create informer factory.
-	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClientSet, resyncPeriod)
-	tfJobInformerFactory := tfjobinformers.NewSharedInformerFactory(tfJobClientSet, resyncPeriod)
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClientSet, cfg.ResyncPeriod.Duration)
+	tfJobInformerFactory := tfjobinformers.NewSharedInformerFactory(tfJobClientSet, cfg.ResyncPeriod.Duration)
 
-	unstructuredInformer := controller.NewUnstructuredTFJobInformer(kcfg)
+	unstructuredInformer := controller.NewUnstructuredTFJobInformer(kcfg, cfg.ResyncPeriod.Duration)
 
 	// Create tf controller.
-	tc := controller.NewTFJobController(unstructuredInformer, kubeClientSet, tfJobClientSet, kubeInformerFactory, tfJobInformerFactory)
+	tc := controller.NewTFJobController(unstructuredInformer, kubeClientSet, tfJobClientSet, kubeInformerFactory, tfJobInformerFactory, configStore)
+
+	// Create tfjobsweep controller, which shares the unstructured informer.
+	sc := sweep.NewTFJobSweepController(tfJobInformerFactory.Kubeflow().V1alpha2().TFJobSweeps(), unstructuredInformer, kubeClientSet, tfJobClientSet)
+
+	// Create crontfjob controller, which shares the unstructured informer.
+	cc := cron.NewCronTFJobController(tfJobInformerFactory.Kubeflow().V1alpha2().CronTFJobs(), unstructuredInformer, kubeClientSet, tfJobClientSet)
 
 	// Start informer goroutines.
 	go kubeInformerFactory.Start(stopCh)
 
-	// We do not use the generated informer because of
+	// We do not use the generated informer for tfjobs because of
 	// https://github.com/kubeflow/tf-operator/issues/561
-	// go tfJobInformerFactory.Start(stopCh)
+	// The factory only starts the tfjobsweep and crontfjob informers.
+	go tfJobInformerFactory.Start(stopCh)
 	go unstructuredInformer.Informer().Run(stopCh)
 
+	// Serve the metrics, of the leader and of the candidates.
+	if opt.MetricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", prometheus.Handler())
+			log.Errorf("Failed to serve the metrics: %v", http.ListenAndServe(opt.MetricsAddr, mux))
+		}()
+	}
+
 	// Set leader election start function.
 	run := func(<-chan struct{}) {
+		go func() {
+			if err := sc.Run(opt.Threadiness, stopCh); err != nil {
+				log.Errorf("Failed to run the tfjobsweep controller: %v", err)
+			}
+		}()
+		go func() {
+			if err := cc.Run(opt.Threadiness, stopCh); err != nil {
+				log.Errorf("Failed to run the crontfjob controller: %v", err)
+			}
+		}()
 		if err := tc.Run(opt.Threadiness, stopCh); err != nil {
 			log.Errorf("Failed to run the controller: %v", err)
 		}
@@ -139,9 +169,9 @@ func Run(opt *options.ServerOption) error {
 	// Start leader election.
 	election.RunOrDie(election.LeaderElectionConfig{
 		Lock:          rl,
-		LeaseDuration: leaseDuration,
-		RenewDeadline: renewDuration,
-		RetryPeriod:   retryPeriod,
+		LeaseDuration: cfg.LeaderElection.LeaseDuration.Duration,
+		RenewDeadline: cfg.LeaderElection.RenewDeadline.Duration,
+		RetryPeriod:   cfg.LeaderElection.RetryPeriod.Duration,
 		Callbacks: election.LeaderCallbacks{
 			OnStartedLeading: run,
 			OnStoppedLeading: func() {
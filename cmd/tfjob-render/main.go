@@ -0,0 +1,126 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// tfjob-render prints the pods and services the v1alpha2 operator creates
+// for a TFJob, without a cluster.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/ghodss/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/validation"
+	controller "github.com/kubeflow/tf-operator/pkg/controller.v2"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/config"
+)
+
+func main() {
+	file := flag.String("f", "-", "Path to the TFJob YAML file, - for the standard input.")
+	configFile := flag.String("config", "",
+		`Path to the OperatorConfiguration file of the operator, for the namespace overlays
+		 and the accelerators. The defaults are used if it is not set.`)
+	namespace := flag.String("namespace", metav1.NamespaceDefault,
+		"Namespace of the TFJob if it does not set one.")
+	flag.Parse()
+
+	if err := run(*file, *configFile, *namespace, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "tfjob-render: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run renders the TFJob of the file and writes the manifests to out, as a
+// stream of YAML documents.
+func run(file, configFile, namespace string, out io.Writer) error {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	tfjob := &tfv1alpha2.TFJob{}
+	if err := yaml.Unmarshal(data, tfjob); err != nil {
+		return fmt.Errorf("failed to parse the TFJob: %v", err)
+	}
+	gvk := tfjob.GroupVersionKind()
+	if gvk != tfv1alpha2.SchemeGroupVersionKind {
+		return fmt.Errorf("expected apiVersion %s and kind %s, got %s and %s",
+			tfv1alpha2.SchemeGroupVersion, tfv1alpha2.Kind, tfjob.APIVersion, tfjob.Kind)
+	}
+	if tfjob.Namespace == "" {
+		tfjob.Namespace = namespace
+	}
+	tfv1alpha2.SetDefaults_TFJob(tfjob)
+	if err := validation.ValidateV1Alpha2TFJobSpec(&tfjob.Spec); err != nil {
+		return fmt.Errorf("invalid TFJob: %v", err)
+	}
+
+	store, err := config.NewStore(configFile)
+	if err != nil {
+		return err
+	}
+	pods, services, err := controller.Render(tfjob, store.Get())
+	if err != nil {
+		return err
+	}
+
+	configMap, err := controller.RenderClusterSpecConfigMap(tfjob)
+	if err != nil {
+		return err
+	}
+	networkPolicy, err := controller.RenderNetworkPolicy(tfjob)
+	if err != nil {
+		return err
+	}
+
+	var objects []interface{}
+	if configMap != nil {
+		objects = append(objects, configMap)
+	}
+	if networkPolicy != nil {
+		objects = append(objects, networkPolicy)
+	}
+	for _, pod := range pods {
+		objects = append(objects, pod)
+	}
+	for _, service := range services {
+		objects = append(objects, service)
+	}
+	var buf bytes.Buffer
+	for i, obj := range objects {
+		manifest, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(manifest)
+	}
+	_, err = out.Write(buf.Bytes())
+	return err
+}
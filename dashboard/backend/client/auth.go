@@ -0,0 +1,137 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+)
+
+// AuthMode selects the identity used for the requests sent to the apiserver.
+type AuthMode string
+
+const (
+	// AuthModeServiceAccount sends every request with the credentials of the dashboard itself.
+	// Anyone who can reach the dashboard gets the permissions of its service account.
+	AuthModeServiceAccount AuthMode = "serviceaccount"
+
+	// AuthModeToken forwards the bearer token from the Authorization header of the incoming request.
+	// The apiserver authenticates the token and enforces RBAC for its user.
+	AuthModeToken AuthMode = "token"
+
+	// AuthModeImpersonate impersonates the user and groups set by an authenticating proxy
+	// in the headers of the incoming request. The service account of the dashboard
+	// must be allowed to impersonate users and groups, and the dashboard must only
+	// be reachable through the proxy since it trusts these headers.
+	AuthModeImpersonate AuthMode = "impersonate"
+
+	// DefaultUserHeader and DefaultGroupHeader are the headers used by the
+	// authenticating proxy to pass the user, following the apiserver's request header authentication.
+	DefaultUserHeader  = "X-Remote-User"
+	DefaultGroupHeader = "X-Remote-Group"
+)
+
+// ErrUnauthenticated is returned when the request does not carry the credentials required by the auth mode.
+var ErrUnauthenticated = errors.New("request is not authenticated")
+
+// AuthOptions configures how requests are authenticated against the apiserver.
+type AuthOptions struct {
+	Mode AuthMode
+	// UserHeader and GroupHeader are the headers read in AuthModeImpersonate.
+	// Groups may be given as several headers or as a comma separated list.
+	UserHeader  string
+	GroupHeader string
+}
+
+// ValidateAuthMode returns an error if the auth mode is unknown.
+func ValidateAuthMode(mode AuthMode) error {
+	switch mode {
+	case AuthModeServiceAccount, AuthModeToken, AuthModeImpersonate:
+		return nil
+	}
+	return fmt.Errorf("unknown auth mode %q, must be one of %s, %s, %s", mode, AuthModeServiceAccount, AuthModeToken, AuthModeImpersonate)
+}
+
+// Clients holds the clientsets used to serve a single request.
+type Clients struct {
+	ClientSet   kubernetes.Interface
+	TFJobClient versioned.Interface
+}
+
+// ClientsFor returns the clientsets to use for the given request.
+// In AuthModeServiceAccount the shared clientsets are returned, otherwise new clientsets
+// acting as the user of the request are built, so that Kubernetes RBAC applies to that user.
+func (c *ClientManager) ClientsFor(req *http.Request) (*Clients, error) {
+	if c.auth.Mode == AuthModeServiceAccount || c.auth.Mode == "" {
+		return &Clients{
+			ClientSet:   c.ClientSet,
+			TFJobClient: c.TFJobClient,
+		}, nil
+	}
+
+	cfg, err := c.configFor(req)
+	if err != nil {
+		return nil, err
+	}
+	clientSet, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tfJobClient, err := versioned.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Clients{
+		ClientSet:   clientSet,
+		TFJobClient: tfJobClient,
+	}, nil
+}
+
+// configFor returns a copy of the rest config authenticating as the user of the request.
+func (c *ClientManager) configFor(req *http.Request) (*rest.Config, error) {
+	switch c.auth.Mode {
+	case AuthModeToken:
+		token := bearerToken(req)
+		if token == "" {
+			return nil, ErrUnauthenticated
+		}
+		// Drop the credentials of the dashboard, keep the server and its CA.
+		cfg := rest.AnonymousClientConfig(c.restCfg)
+		cfg.BearerToken = token
+		return cfg, nil
+	case AuthModeImpersonate:
+		user := req.Header.Get(c.auth.UserHeader)
+		if user == "" {
+			return nil, ErrUnauthenticated
+		}
+		var groups []string
+		for _, value := range req.Header[http.CanonicalHeaderKey(c.auth.GroupHeader)] {
+			for _, group := range strings.Split(value, ",") {
+				if group = strings.TrimSpace(group); group != "" {
+					groups = append(groups, group)
+				}
+			}
+		}
+		cfg := rest.CopyConfig(c.restCfg)
+		cfg.Impersonate = rest.ImpersonationConfig{
+			UserName: user,
+			Groups:   groups,
+		}
+		return cfg, nil
+	}
+	return nil, fmt.Errorf("unknown auth mode %q", c.auth.Mode)
+}
+
+// bearerToken returns the token of the Authorization header, if any.
+func bearerToken(req *http.Request) string {
+	parts := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
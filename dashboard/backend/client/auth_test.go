@@ -0,0 +1,85 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func newTestClientManager(auth AuthOptions) *ClientManager {
+	return &ClientManager{
+		restCfg: &rest.Config{
+			Host:        "https://kubernetes.default.svc",
+			BearerToken: "dashboard-service-account-token",
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData: []byte("ca"),
+			},
+		},
+		auth: auth,
+	}
+}
+
+func TestConfigForToken(t *testing.T) {
+	cm := newTestClientManager(AuthOptions{Mode: AuthModeToken})
+
+	req, _ := http.NewRequest("GET", "/tfjobs/api/tfjob", nil)
+	if _, err := cm.configFor(req); err != ErrUnauthenticated {
+		t.Errorf("Expected %v without a token, got %v", ErrUnauthenticated, err)
+	}
+
+	req.Header.Set("Authorization", "Bearer user-token")
+	cfg, err := cm.configFor(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.BearerToken != "user-token" {
+		t.Errorf("Expected the token of the user, got %q", cfg.BearerToken)
+	}
+	if cfg.Host != cm.restCfg.Host || string(cfg.CAData) != "ca" {
+		t.Errorf("Expected the server and its CA to be kept, got %+v", cfg)
+	}
+	if cm.restCfg.BearerToken != "dashboard-service-account-token" {
+		t.Errorf("Expected the shared config to be left untouched")
+	}
+}
+
+func TestConfigForImpersonate(t *testing.T) {
+	cm := newTestClientManager(AuthOptions{
+		Mode:        AuthModeImpersonate,
+		UserHeader:  DefaultUserHeader,
+		GroupHeader: DefaultGroupHeader,
+	})
+
+	req, _ := http.NewRequest("GET", "/tfjobs/api/tfjob", nil)
+	if _, err := cm.configFor(req); err != ErrUnauthenticated {
+		t.Errorf("Expected %v without a user, got %v", ErrUnauthenticated, err)
+	}
+
+	req.Header.Set(DefaultUserHeader, "alice@example.com")
+	req.Header.Add(DefaultGroupHeader, "ml-team, admins")
+	req.Header.Add(DefaultGroupHeader, "system:authenticated")
+	cfg, err := cm.configFor(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := rest.ImpersonationConfig{
+		UserName: "alice@example.com",
+		Groups:   []string{"ml-team", "admins", "system:authenticated"},
+	}
+	if !reflect.DeepEqual(expected, cfg.Impersonate) {
+		t.Errorf("Expected %+v, got %+v", expected, cfg.Impersonate)
+	}
+}
+
+func TestValidateAuthMode(t *testing.T) {
+	for _, mode := range []AuthMode{AuthModeServiceAccount, AuthModeToken, AuthModeImpersonate} {
+		if err := ValidateAuthMode(mode); err != nil {
+			t.Errorf("Unexpected error for %s: %v", mode, err)
+		}
+	}
+	if err := ValidateAuthMode("oidc"); err == nil {
+		t.Errorf("Expected an error for an unknown auth mode")
+	}
+}
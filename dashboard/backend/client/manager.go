@@ -12,6 +12,7 @@ import (
 // kubernetes apiserver on demand
 type ClientManager struct {
 	restCfg     *rest.Config
+	auth        AuthOptions
 	ClientSet   *kubernetes.Clientset
 	TFJobClient *versioned.Clientset
 }
@@ -36,8 +37,12 @@ func (c *ClientManager) init() {
 }
 
 // NewClientManager creates and init a new instance of ClientManager
-func NewClientManager() (ClientManager, error) {
-	cm := ClientManager{}
+// authenticating the requests as described by the given options.
+func NewClientManager(auth AuthOptions) (ClientManager, error) {
+	if err := ValidateAuthMode(auth.Mode); err != nil {
+		return ClientManager{}, err
+	}
+	cm := ClientManager{auth: auth}
 	cm.init()
 
 	return cm, nil
@@ -3,21 +3,45 @@
 package handler
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/emicklei/go-restful"
 	log "github.com/golang/glog"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/kubeflow/tf-operator/dashboard/backend/client"
+	"github.com/kubeflow/tf-operator/dashboard/backend/stream"
 	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
 )
 
+const (
+	// restartPollInterval and restartTimeout bound how long a restart waits
+	// for the old TFJob and its pods to be removed before recreating it.
+	restartPollInterval = time.Second
+	restartTimeout      = 2 * time.Minute
+)
+
+// Options configures the behavior of the API handler.
+type Options struct {
+	// AllowNamespaceCreation lets handleDeploy create the namespace of
+	// a TFJob if it does not exist yet.
+	AllowNamespaceCreation bool
+	// Hub streams the changes of TFJobs, the watch routes are only served if it is set.
+	Hub *stream.Hub
+}
+
 // APIHandler handles the API calls
 type APIHandler struct {
 	cManager client.ClientManager
+	options  Options
 }
 
 // TFJobDetail describe the specification of a TFJob
@@ -25,6 +49,8 @@ type APIHandler struct {
 type TFJobDetail struct {
 	TFJob *v1alpha2.TFJob `json:"tfJob"`
 	Pods  []v1.Pod        `json:"pods"`
+	// TensorBoardURL is the URL of the TensorBoard of the TFJob, if it has one.
+	TensorBoardURL string `json:"tensorBoardURL,omitempty"`
 }
 
 // TFJobList is a list of TFJobs
@@ -38,9 +64,10 @@ type NamespaceList struct {
 }
 
 // CreateHTTPAPIHandler creates the restful Container and defines the routes the API will serve
-func CreateHTTPAPIHandler(client client.ClientManager) (http.Handler, error) {
+func CreateHTTPAPIHandler(client client.ClientManager, options Options) (http.Handler, error) {
 	apiHandler := APIHandler{
 		cManager: client,
+		options:  options,
 	}
 
 	wsContainer := restful.NewContainer()
@@ -48,8 +75,8 @@ func CreateHTTPAPIHandler(client client.ClientManager) (http.Handler, error) {
 
 	cors := restful.CrossOriginResourceSharing{
 		ExposeHeaders:  []string{"X-My-Header"},
-		AllowedHeaders: []string{"Content-Type", "Accept"},
-		AllowedMethods: []string{"GET", "POST", "DELETE"},
+		AllowedHeaders: []string{"Content-Type", "Accept", "Authorization"},
+		AllowedMethods: []string{"GET", "POST", "PATCH", "DELETE"},
 		CookiesAllowed: false,
 		Container:      wsContainer,
 	}
@@ -76,12 +103,12 @@ func CreateHTTPAPIHandler(client client.ClientManager) (http.Handler, error) {
 		Produces(restful.MIME_JSON)
 
 	apiV1Ws.Route(
-		apiV1Ws.GET("/tfjob").
+		addListParams(apiV1Ws, apiV1Ws.GET("/tfjob")).
 			To(apiHandler.handleGetTFJobs).
 			Writes(TFJobList{}))
 
 	apiV1Ws.Route(
-		apiV1Ws.GET("/tfjob/{namespace}").
+		addListParams(apiV1Ws, apiV1Ws.GET("/tfjob/{namespace}")).
 			To(apiHandler.handleGetTFJobs).
 			Writes(TFJobList{}))
 
@@ -100,6 +127,23 @@ func CreateHTTPAPIHandler(client client.ClientManager) (http.Handler, error) {
 		apiV1Ws.DELETE("/tfjob/{namespace}/{tfjob}").
 			To(apiHandler.handleDeleteTFJob))
 
+	apiV1Ws.Route(
+		apiV1Ws.PATCH("/tfjob/{namespace}/{tfjob}").
+			Consumes(restful.MIME_JSON, string(types.MergePatchType)).
+			To(apiHandler.handlePatchTFJob).
+			Writes(v1alpha2.TFJob{}))
+
+	apiV1Ws.Route(
+		apiV1Ws.POST("/tfjob/{namespace}/{tfjob}/clone").
+			To(apiHandler.handleCloneTFJob).
+			Reads(TFJobCloneRequest{}).
+			Writes(v1alpha2.TFJob{}))
+
+	apiV1Ws.Route(
+		apiV1Ws.POST("/tfjob/{namespace}/{tfjob}/restart").
+			To(apiHandler.handleRestartTFJob).
+			Writes(v1alpha2.TFJob{}))
+
 	apiV1Ws.Route(
 		apiV1Ws.GET("/logs/{namespace}/{podname}").
 			To(apiHandler.handleGetPodLogs).
@@ -111,61 +155,108 @@ func CreateHTTPAPIHandler(client client.ClientManager) (http.Handler, error) {
 			Writes(NamespaceList{}))
 
 	wsContainer.Add(apiV1Ws)
-	return wsContainer, nil
+	if options.Hub == nil {
+		return wsContainer, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/tfjobs/api/watch/", apiHandler.createWatchContainer())
+	mux.Handle("/", wsContainer)
+	return mux, nil
+}
+
+// addListParams documents the query parameters accepted by the TFJob list routes.
+func addListParams(ws *restful.WebService, b *restful.RouteBuilder) *restful.RouteBuilder {
+	return b.
+		Param(ws.QueryParameter(queryCondition, "only return TFJobs whose latest condition is of this type, e.g. Running or Failed")).
+		Param(ws.QueryParameter(queryLabelSelector, "only return TFJobs matching this label selector")).
+		Param(ws.QueryParameter(queryNamePrefix, "only return TFJobs whose name starts with this prefix")).
+		Param(ws.QueryParameter(queryCreatedAfter, "only return TFJobs created at or after this RFC3339 time")).
+		Param(ws.QueryParameter(queryCreatedBefore, "only return TFJobs created before this RFC3339 time")).
+		Param(ws.QueryParameter(querySort, "sort the page by name or creationTimestamp, prefix with - for descending order")).
+		Param(ws.QueryParameter(queryLimit, "maximum number of TFJobs fetched from the API server for this page").DataType("integer")).
+		Param(ws.QueryParameter(queryContinue, "continue token returned in the metadata of the previous page")).
+		Param(ws.QueryParameter(queryView, "set to summary to return TFJobSummary items without pod templates"))
 }
 
 func (apiHandler *APIHandler) handleGetTFJobs(request *restful.Request, response *restful.Response) {
 	namespace := request.PathParameter("namespace")
-	jobs, err := apiHandler.cManager.TFJobClient.KubeflowV1alpha2().TFJobs(namespace).List(metav1.ListOptions{})
 
 	ns := "all"
 	if namespace != "" {
 		ns = namespace
 	}
+
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+
+	query, err := newTFJobListQuery(request.Request.URL.Query())
 	if err != nil {
-		log.Warningf("failed to list TFJobs under %v namespace(s): %v", ns, err)
-		if err2 := response.WriteError(http.StatusInternalServerError, err); err2 != nil {
+		log.Infof("invalid query for listing TFJobs under %v namespace(s): %v", ns, err)
+		if err2 := response.WriteError(http.StatusBadRequest, err); err2 != nil {
 			log.Errorf("Failed to write response: %v", err2)
 		}
-	} else {
-		log.Infof("successfully listed TFJobs under %v namespace(s)", ns)
-		if err = response.WriteHeaderAndEntity(http.StatusOK, jobs); err != nil {
-			log.Errorf("Failed to write response: %v", err)
+		return
+	}
+
+	// Limit and continue are passed to the API server so that large namespaces
+	// are listed in chunks instead of in a single response.
+	jobs, err := clients.TFJobClient.KubeflowV1alpha2().TFJobs(namespace).List(query.listOptions)
+	if err != nil {
+		log.Warningf("failed to list TFJobs under %v namespace(s): %v", ns, err)
+		if errors.IsResourceExpired(err) {
+			// The continue token is stale, the client should restart the listing.
+			if err2 := response.WriteError(http.StatusBadRequest, err); err2 != nil {
+				log.Errorf("Failed to write response: %v", err2)
+			}
+			return
+		}
+		writeStatusError(response, err)
+		return
+	}
+
+	log.Infof("successfully listed TFJobs under %v namespace(s)", ns)
+	jobs.Items = query.filter(jobs.Items)
+	var entity interface{} = jobs
+	if query.summary {
+		entity = TFJobSummaryList{
+			ListMeta: jobs.ListMeta,
+			Items:    summarize(jobs.Items),
 		}
 	}
+	if err = response.WriteHeaderAndEntity(http.StatusOK, entity); err != nil {
+		log.Errorf("Failed to write response: %v", err)
+	}
 }
 
 func (apiHandler *APIHandler) handleGetTFJobDetail(request *restful.Request, response *restful.Response) {
 	namespace := request.PathParameter("namespace")
 	name := request.PathParameter("tfjob")
-	job, err := apiHandler.cManager.TFJobClient.KubeflowV1alpha2().TFJobs(namespace).Get(name, metav1.GetOptions{})
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+	job, err := clients.TFJobClient.KubeflowV1alpha2().TFJobs(namespace).Get(name, metav1.GetOptions{})
 	if err != nil {
 		log.Infof("cannot find TFJob %v under namespace %v, error: %v", name, namespace, err)
-		if errors.IsNotFound(err) {
-			if err2 := response.WriteError(http.StatusNotFound, err); err2 != nil {
-				log.Errorf("Failed to write response: %v", err2)
-			}
-		} else {
-			if err2 := response.WriteError(http.StatusInternalServerError, err); err2 != nil {
-				log.Errorf("Failed to write response: %v", err2)
-			}
-		}
+		writeStatusError(response, err)
 		return
 	}
 
 	tfJobDetail := TFJobDetail{
-		TFJob: job,
+		TFJob:          job,
+		TensorBoardURL: job.Status.TensorBoardURL,
 	}
 
 	// Get associated pods
-	pods, err := apiHandler.cManager.ClientSet.CoreV1().Pods(namespace).List(metav1.ListOptions{
+	pods, err := clients.ClientSet.CoreV1().Pods(namespace).List(metav1.ListOptions{
 	// LabelSelector: fmt.Sprintf("kubeflow.org=,runtime_id=%s", job.Spec.RuntimeId),
 	})
 	if err != nil {
 		log.Warningf("failed to list pods for TFJob %v under namespace %v: %v", name, namespace, err)
-		if err2 := response.WriteError(http.StatusInternalServerError, err); err2 != nil {
-			log.Errorf("Failed to write response: %v", err2)
-		}
+		writeStatusError(response, err)
 	} else {
 		log.Infof("successfully listed pods for TFJob %v under namespace %v", name, namespace)
 		tfJobDetail.Pods = pods.Items
@@ -176,7 +267,11 @@ func (apiHandler *APIHandler) handleGetTFJobDetail(request *restful.Request, res
 }
 
 func (apiHandler *APIHandler) handleDeploy(request *restful.Request, response *restful.Response) {
-	clt := apiHandler.cManager.TFJobClient
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+	clt := clients.TFJobClient
 	tfJob := new(v1alpha2.TFJob)
 	if err := request.ReadEntity(tfJob); err != nil {
 		if err2 := response.WriteError(http.StatusBadRequest, err); err2 != nil {
@@ -185,30 +280,28 @@ func (apiHandler *APIHandler) handleDeploy(request *restful.Request, response *r
 		return
 	}
 
-	_, err := apiHandler.cManager.ClientSet.CoreV1().Namespaces().Get(tfJob.Namespace, metav1.GetOptions{})
+	if apiHandler.options.AllowNamespaceCreation {
+		_, err := clients.ClientSet.CoreV1().Namespaces().Get(tfJob.Namespace, metav1.GetOptions{})
 
-	if errors.IsNotFound(err) {
-		// If namespace doesn't exist we create it
-		_, nsErr := apiHandler.cManager.ClientSet.CoreV1().Namespaces().Create(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tfJob.Namespace}})
-		if nsErr != nil {
-			log.Warningf("failed to create namespace %v for TFJob %v: %v", tfJob.Namespace, tfJob.Name, nsErr)
-			if err2 := response.WriteError(http.StatusInternalServerError, nsErr); err2 != nil {
-				log.Errorf("Failed to write response: %v", err2)
+		if errors.IsNotFound(err) {
+			// If namespace doesn't exist we create it
+			_, nsErr := clients.ClientSet.CoreV1().Namespaces().Create(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tfJob.Namespace}})
+			if nsErr != nil {
+				log.Warningf("failed to create namespace %v for TFJob %v: %v", tfJob.Namespace, tfJob.Name, nsErr)
+				writeStatusError(response, nsErr)
+				return
 			}
-		}
-	} else if err != nil {
-		log.Warningf("failed to deploy TFJob %v under namespace %v: %v", tfJob.Name, tfJob.Namespace, err)
-		if err2 := response.WriteError(http.StatusInternalServerError, err); err2 != nil {
-			log.Errorf("Failed to write response: %v", err2)
+		} else if err != nil {
+			log.Warningf("failed to deploy TFJob %v under namespace %v: %v", tfJob.Name, tfJob.Namespace, err)
+			writeStatusError(response, err)
+			return
 		}
 	}
 
 	j, err := clt.KubeflowV1alpha2().TFJobs(tfJob.Namespace).Create(tfJob)
 	if err != nil {
 		log.Warningf("failed to deploy TFJob %v under namespace %v: %v", tfJob.Name, tfJob.Namespace, err)
-		if err2 := response.WriteError(http.StatusInternalServerError, err); err2 != nil {
-			log.Errorf("Failed to write response: %v", err2)
-		}
+		writeStatusError(response, err)
 	} else {
 		log.Infof("successfully deployed TFJob %v under namespace %v", tfJob.Name, tfJob.Namespace)
 		if err = response.WriteHeaderAndEntity(http.StatusCreated, j); err != nil {
@@ -220,28 +313,235 @@ func (apiHandler *APIHandler) handleDeploy(request *restful.Request, response *r
 func (apiHandler *APIHandler) handleDeleteTFJob(request *restful.Request, response *restful.Response) {
 	namespace := request.PathParameter("namespace")
 	name := request.PathParameter("tfjob")
-	clt := apiHandler.cManager.TFJobClient
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+	clt := clients.TFJobClient
 	err := clt.KubeflowV1alpha2().TFJobs(namespace).Delete(name, &metav1.DeleteOptions{})
 	if err != nil {
 		log.Warningf("failed to delete TFJob %v under namespace %v: %v", name, namespace, err)
-		if err2 := response.WriteError(http.StatusInternalServerError, err); err2 != nil {
-			log.Errorf("Failed to write response: %v", err2)
-		}
+		writeStatusError(response, err)
 	} else {
 		log.Infof("successfully deleted TFJob %v under namespace %v", name, namespace)
 		response.WriteHeader(http.StatusNoContent)
 	}
 }
 
+func (apiHandler *APIHandler) handleCloneTFJob(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("tfjob")
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+	clt := clients.TFJobClient
+
+	cloneRequest := new(TFJobCloneRequest)
+	if request.Request.ContentLength != 0 {
+		if err := request.ReadEntity(cloneRequest); err != nil {
+			if err2 := response.WriteError(http.StatusBadRequest, err); err2 != nil {
+				log.Errorf("Failed to write response: %v", err2)
+			}
+			return
+		}
+	}
+
+	job, err := clt.KubeflowV1alpha2().TFJobs(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		log.Warningf("failed to get TFJob %v under namespace %v: %v", name, namespace, err)
+		writeStatusError(response, err)
+		return
+	}
+
+	clone, err := cloneTFJob(job, cloneRequest)
+	if err != nil {
+		log.Infof("invalid clone of TFJob %v under namespace %v: %v", name, namespace, err)
+		if err2 := response.WriteError(http.StatusBadRequest, err); err2 != nil {
+			log.Errorf("Failed to write response: %v", err2)
+		}
+		return
+	}
+
+	j, err := clt.KubeflowV1alpha2().TFJobs(namespace).Create(clone)
+	if err != nil {
+		log.Warningf("failed to clone TFJob %v under namespace %v: %v", name, namespace, err)
+		writeStatusError(response, err)
+	} else {
+		log.Infof("successfully cloned TFJob %v to %v under namespace %v", name, j.Name, namespace)
+		if err = response.WriteHeaderAndEntity(http.StatusCreated, j); err != nil {
+			log.Errorf("Failed to write response: %v", err)
+		}
+	}
+}
+
+func (apiHandler *APIHandler) handleRestartTFJob(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("tfjob")
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+	tfJobs := clients.TFJobClient.KubeflowV1alpha2().TFJobs(namespace)
+
+	job, err := tfJobs.Get(name, metav1.GetOptions{})
+	if err != nil {
+		log.Warningf("failed to get TFJob %v under namespace %v: %v", name, namespace, err)
+		writeStatusError(response, err)
+		return
+	}
+
+	// Validate before deleting, so that we never delete a TFJob we can not recreate.
+	restarted := newTFJobFrom(job, job.Name)
+	if err = prepareTFJob(restarted); err != nil {
+		log.Infof("TFJob %v under namespace %v can not be restarted: %v", name, namespace, err)
+		if err2 := response.WriteError(http.StatusBadRequest, err); err2 != nil {
+			log.Errorf("Failed to write response: %v", err2)
+		}
+		return
+	}
+
+	// The TFJob is only removed once its pods and services are, whose names
+	// are reused by the new one.
+	propagation := metav1.DeletePropagationForeground
+	err = tfJobs.Delete(name, &metav1.DeleteOptions{
+		Preconditions:     &metav1.Preconditions{UID: &job.UID},
+		PropagationPolicy: &propagation,
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		log.Warningf("failed to delete TFJob %v under namespace %v: %v", name, namespace, err)
+		writeStatusError(response, err)
+		return
+	}
+
+	// The TFJob is recreated within the request once the old one is gone, so
+	// that the client learns whether it has been. The wait stops if the client
+	// goes away, a shutdown of the dashboard waits for the request.
+	ctx, cancel := context.WithTimeout(request.Request.Context(), restartTimeout)
+	defer cancel()
+	err = wait.PollUntil(restartPollInterval, func() (bool, error) {
+		old, err := tfJobs.Get(name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return old.UID != job.UID, nil
+	}, ctx.Done())
+	if err != nil {
+		log.Warningf("failed to wait for the deletion of TFJob %v under namespace %v: %v", name, namespace, err)
+		err = fmt.Errorf("TFJob %v has been deleted but not recreated, its pods are still being deleted: %v", name, err)
+		if err2 := response.WriteError(http.StatusGatewayTimeout, err); err2 != nil {
+			log.Errorf("Failed to write response: %v", err2)
+		}
+		return
+	}
+
+	j, err := tfJobs.Create(restarted)
+	if err != nil {
+		log.Warningf("failed to recreate TFJob %v under namespace %v: %v", name, namespace, err)
+		writeStatusError(response, err)
+		return
+	}
+	log.Infof("successfully restarted TFJob %v under namespace %v", name, namespace)
+	if err = response.WriteHeaderAndEntity(http.StatusCreated, j); err != nil {
+		log.Errorf("Failed to write response: %v", err)
+	}
+}
+
+func (apiHandler *APIHandler) handlePatchTFJob(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("tfjob")
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+	tfJobs := clients.TFJobClient.KubeflowV1alpha2().TFJobs(namespace)
+
+	patch, err := ioutil.ReadAll(request.Request.Body)
+	if err != nil {
+		if err2 := response.WriteError(http.StatusBadRequest, err); err2 != nil {
+			log.Errorf("Failed to write response: %v", err2)
+		}
+		return
+	}
+
+	job, err := tfJobs.Get(name, metav1.GetOptions{})
+	if err != nil {
+		log.Warningf("failed to get TFJob %v under namespace %v: %v", name, namespace, err)
+		writeStatusError(response, err)
+		return
+	}
+
+	patched, err := patchTFJob(job, patch)
+	if err == nil {
+		err = validateTFJobUpdate(job, patched)
+	}
+	if err == nil {
+		err = prepareTFJob(patched)
+	}
+	if err != nil {
+		log.Infof("invalid patch for TFJob %v under namespace %v: %v", name, namespace, err)
+		if err2 := response.WriteError(http.StatusBadRequest, err); err2 != nil {
+			log.Errorf("Failed to write response: %v", err2)
+		}
+		return
+	}
+
+	// The resource version of the TFJob we patched guards against concurrent updates.
+	j, err := tfJobs.Update(patched)
+	if err != nil {
+		log.Warningf("failed to update TFJob %v under namespace %v: %v", name, namespace, err)
+		writeStatusError(response, err)
+	} else {
+		log.Infof("successfully updated TFJob %v under namespace %v", name, namespace)
+		if err = response.WriteHeaderAndEntity(http.StatusOK, j); err != nil {
+			log.Errorf("Failed to write response: %v", err)
+		}
+	}
+}
+
+// clientsFor returns the clientsets acting on behalf of the user of the request.
+// It writes an error response and returns false if they can not be built.
+func (apiHandler *APIHandler) clientsFor(request *restful.Request, response *restful.Response) (*client.Clients, bool) {
+	clients, err := apiHandler.cManager.ClientsFor(request.Request)
+	if err == nil {
+		return clients, true
+	}
+	code := http.StatusInternalServerError
+	if err == client.ErrUnauthenticated {
+		code = http.StatusUnauthorized
+	}
+	log.Infof("failed to build clients for %s %s: %v", request.Request.Method, request.Request.URL.Path, err)
+	if err2 := response.WriteError(code, err); err2 != nil {
+		log.Errorf("Failed to write response: %v", err2)
+	}
+	return nil, false
+}
+
+// writeStatusError writes the error with the HTTP status code matching the API error.
+func writeStatusError(response *restful.Response, err error) {
+	code := http.StatusInternalServerError
+	if status, ok := err.(errors.APIStatus); ok && status.Status().Code != 0 {
+		code = int(status.Status().Code)
+	}
+	if err2 := response.WriteError(code, err); err2 != nil {
+		log.Errorf("Failed to write response: %v", err2)
+	}
+}
+
 func (apiHandler *APIHandler) handleGetPodLogs(request *restful.Request, response *restful.Response) {
 	namespace := request.PathParameter("namespace")
 	name := request.PathParameter("podname")
-	logs, err := apiHandler.cManager.ClientSet.CoreV1().Pods(namespace).GetLogs(name, &v1.PodLogOptions{}).Do().Raw()
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+	logs, err := clients.ClientSet.CoreV1().Pods(namespace).GetLogs(name, &v1.PodLogOptions{}).Do().Raw()
 	if err != nil {
 		log.Warningf("failed to get pod logs for TFJob %v under namespace %v: %v", name, namespace, err)
-		if err2 := response.WriteError(http.StatusInternalServerError, err); err2 != nil {
-			log.Errorf("Failed to write response: %v", err2)
-		}
+		writeStatusError(response, err)
 	} else {
 		log.Infof("successfully get pod logs for TFJob %v under namespace %v", name, namespace)
 		if err = response.WriteHeaderAndEntity(http.StatusOK, string(logs)); err != nil {
@@ -251,12 +551,14 @@ func (apiHandler *APIHandler) handleGetPodLogs(request *restful.Request, respons
 }
 
 func (apiHandler *APIHandler) handleGetNamespaces(request *restful.Request, response *restful.Response) {
-	l, err := apiHandler.cManager.ClientSet.CoreV1().Namespaces().List(metav1.ListOptions{})
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+	l, err := clients.ClientSet.CoreV1().Namespaces().List(metav1.ListOptions{})
 	if err != nil {
 		log.Warningf("failed to list namespaces.")
-		if err2 := response.WriteError(http.StatusInternalServerError, err); err2 != nil {
-			log.Errorf("Failed to write response: %v", err2)
-		}
+		writeStatusError(response, err)
 	} else {
 		log.Infof("successfully listed namespaces")
 		if err = response.WriteHeaderAndEntity(http.StatusOK, l); err != nil {
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/validation"
+)
+
+// TFJobCloneRequest describes how to clone a TFJob.
+type TFJobCloneRequest struct {
+	// Name of the new TFJob. If empty, a name is generated from the source TFJob.
+	Name string `json:"name,omitempty"`
+	// Patch is a JSON merge patch applied to the copy before it is created,
+	// e.g. {"spec":{"tfReplicaSpecs":{"Worker":{"replicas":4}}}}.
+	Patch json.RawMessage `json:"patch,omitempty"`
+}
+
+// prepareTFJob applies the v1alpha2 defaults to the TFJob and validates it,
+// so that invalid TFJobs are rejected before they are submitted.
+func prepareTFJob(job *v1alpha2.TFJob) error {
+	v1alpha2.SetObjectDefaults_TFJob(job)
+	return validation.ValidateV1Alpha2TFJobSpec(&job.Spec)
+}
+
+// newTFJobFrom returns a TFJob which can be created from the spec, labels and
+// annotations of the given TFJob. Status and server populated fields are dropped.
+func newTFJobFrom(job *v1alpha2.TFJob, name string) *v1alpha2.TFJob {
+	copied := job.DeepCopy()
+	return &v1alpha2.TFJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha2.SchemeGroupVersion.String(),
+			Kind:       v1alpha2.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   copied.Namespace,
+			Labels:      copied.Labels,
+			Annotations: copied.Annotations,
+		},
+		Spec: copied.Spec,
+	}
+}
+
+// cloneTFJob builds a copy of the TFJob as described by the clone request.
+func cloneTFJob(job *v1alpha2.TFJob, req *TFJobCloneRequest) (*v1alpha2.TFJob, error) {
+	clone := newTFJobFrom(job, req.Name)
+	if req.Name == "" {
+		clone.GenerateName = job.Name + "-"
+	}
+
+	if len(req.Patch) != 0 {
+		patched, err := patchTFJob(clone, req.Patch)
+		if err != nil {
+			return nil, err
+		}
+		// The patch may not move the clone to another namespace.
+		patched.Namespace = clone.Namespace
+		clone = patched
+	}
+
+	if err := prepareTFJob(clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// patchTFJob applies a JSON merge patch (RFC 7386) to a copy of the TFJob.
+// As with any merge patch, lists such as containers are replaced as a whole.
+func patchTFJob(job *v1alpha2.TFJob, patch []byte) (*v1alpha2.TFJob, error) {
+	original, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	var target, patchObj interface{}
+	if err := json.Unmarshal(original, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &patchObj); err != nil {
+		return nil, fmt.Errorf("failed to decode the patch: %v", err)
+	}
+	if _, ok := patchObj.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("the patch must be a JSON object")
+	}
+
+	patchedJSON, err := json.Marshal(mergePatch(target, patchObj))
+	if err != nil {
+		return nil, err
+	}
+	patched := &v1alpha2.TFJob{}
+	if err := json.Unmarshal(patchedJSON, patched); err != nil {
+		return nil, fmt.Errorf("failed to apply the patch: %v", err)
+	}
+	return patched, nil
+}
+
+// mergePatch merges the patch into the target as described in RFC 7386:
+// objects are merged recursively, null removes a key, anything else replaces the target.
+func mergePatch(target, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetMap, ok := target.(map[string]interface{})
+	if !ok {
+		targetMap = make(map[string]interface{})
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+		} else {
+			targetMap[key] = mergePatch(targetMap[key], value)
+		}
+	}
+	return targetMap
+}
+
+// validateTFJobUpdate checks that only mutable fields differ between the two TFJobs.
+// Labels and annotations, as well as the replicas and restart policy of
+// existing replica types, may be changed. Everything else is immutable.
+func validateTFJobUpdate(old, cur *v1alpha2.TFJob) error {
+	if cur.Name != old.Name || cur.Namespace != old.Namespace || cur.UID != old.UID {
+		return fmt.Errorf("metadata.name, metadata.namespace and metadata.uid are immutable")
+	}
+	if !equality.Semantic.DeepEqual(cur.Status, old.Status) {
+		return fmt.Errorf("status can not be changed")
+	}
+	if len(cur.Spec.TFReplicaSpecs) != len(old.Spec.TFReplicaSpecs) {
+		return fmt.Errorf("replica types can not be added or removed")
+	}
+	for rtype, oldSpec := range old.Spec.TFReplicaSpecs {
+		curSpec, ok := cur.Spec.TFReplicaSpecs[rtype]
+		if !ok || curSpec == nil {
+			return fmt.Errorf("replica types can not be added or removed")
+		}
+		if oldSpec == nil {
+			continue
+		}
+		if !equality.Semantic.DeepEqual(curSpec.Template, oldSpec.Template) {
+			return fmt.Errorf("template of replica type %v is immutable", rtype)
+		}
+	}
+	return nil
+}
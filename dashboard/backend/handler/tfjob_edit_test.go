@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+func newEditTestTFJob() *v1alpha2.TFJob {
+	return &v1alpha2.TFJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "mnist",
+			Namespace:       "kubeflow",
+			UID:             "uid",
+			ResourceVersion: "42",
+			Labels:          map[string]string{"team": "ml"},
+		},
+		Spec: v1alpha2.TFJobSpec{
+			TFReplicaSpecs: map[v1alpha2.TFReplicaType]*v1alpha2.TFReplicaSpec{
+				v1alpha2.TFReplicaTypeWorker: {
+					Replicas: v1alpha2.Int32(2),
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{Name: v1alpha2.DefaultContainerName, Image: "mnist:v1"},
+							},
+						},
+					},
+				},
+			},
+		},
+		Status: v1alpha2.TFJobStatus{
+			Conditions: []v1alpha2.TFJobCondition{
+				{Type: v1alpha2.TFJobRunning, Status: v1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func TestCloneTFJob(t *testing.T) {
+	job := newEditTestTFJob()
+
+	clone, err := cloneTFJob(job, &TFJobCloneRequest{
+		Name:  "mnist-v2",
+		Patch: []byte(`{"spec":{"tfReplicaSpecs":{"Worker":{"replicas":4,"template":{"spec":{"containers":[{"name":"tensorflow","image":"mnist:v2"}]}}}}}}`),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if clone.Name != "mnist-v2" || clone.Namespace != job.Namespace {
+		t.Errorf("Expected kubeflow/mnist-v2, got %s/%s", clone.Namespace, clone.Name)
+	}
+	if clone.UID != "" || clone.ResourceVersion != "" || len(clone.Status.Conditions) != 0 {
+		t.Errorf("Expected server populated fields to be dropped, got %+v", clone)
+	}
+	if clone.Labels["team"] != "ml" {
+		t.Errorf("Expected labels to be copied, got %v", clone.Labels)
+	}
+	spec := clone.Spec.TFReplicaSpecs[v1alpha2.TFReplicaTypeWorker]
+	if *spec.Replicas != 4 {
+		t.Errorf("Expected 4 replicas, got %d", *spec.Replicas)
+	}
+	if containers := spec.Template.Spec.Containers; len(containers) != 1 || containers[0].Image != "mnist:v2" {
+		t.Errorf("Expected the tensorflow container to be patched, got %+v", containers)
+	}
+	if spec.RestartPolicy != v1alpha2.DefaultRestartPolicy {
+		t.Errorf("Expected defaults to be applied, got restart policy %q", spec.RestartPolicy)
+	}
+	if *job.Spec.TFReplicaSpecs[v1alpha2.TFReplicaTypeWorker].Replicas != 2 {
+		t.Errorf("Expected the source TFJob to be left untouched")
+	}
+
+	clone, err = cloneTFJob(job, &TFJobCloneRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if clone.Name != "" || clone.GenerateName != "mnist-" {
+		t.Errorf("Expected a generated name, got name %q and generateName %q", clone.Name, clone.GenerateName)
+	}
+
+	_, err = cloneTFJob(job, &TFJobCloneRequest{
+		Patch: []byte(`{"spec":{"tfReplicaSpecs":{"Worker":{"template":{"spec":{"containers":[{"name":"tensorflow"}]}}}}}}`),
+	})
+	if err == nil {
+		t.Errorf("Expected an invalid clone to be rejected")
+	}
+}
+
+func TestValidateTFJobUpdate(t *testing.T) {
+	testCases := map[string]struct {
+		patch          string
+		expectingError bool
+	}{
+		"replicas": {
+			patch: `{"spec":{"tfReplicaSpecs":{"Worker":{"replicas":8}}}}`,
+		},
+		"restart policy and labels": {
+			patch: `{"metadata":{"labels":{"team":"infra"}},"spec":{"tfReplicaSpecs":{"Worker":{"restartPolicy":"ExitCode"}}}}`,
+		},
+		"template": {
+			patch:          `{"spec":{"tfReplicaSpecs":{"Worker":{"template":{"spec":{"containers":[{"name":"tensorflow","image":"mnist:v2"}]}}}}}}`,
+			expectingError: true,
+		},
+		"new replica type": {
+			patch:          `{"spec":{"tfReplicaSpecs":{"PS":{"replicas":1}}}}`,
+			expectingError: true,
+		},
+		"status": {
+			patch:          `{"status":{"conditions":null}}`,
+			expectingError: true,
+		},
+		"name": {
+			patch:          `{"metadata":{"name":"other"}}`,
+			expectingError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		job := newEditTestTFJob()
+		patched, err := patchTFJob(job, []byte(tc.patch))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if err := validateTFJobUpdate(job, patched); (err != nil) != tc.expectingError {
+			t.Errorf("%s: unexpected validation result: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+const (
+	// Query parameters accepted by the TFJob list endpoints.
+	queryCondition     = "condition"
+	queryLabelSelector = "labelSelector"
+	queryNamePrefix    = "namePrefix"
+	queryCreatedAfter  = "createdAfter"
+	queryCreatedBefore = "createdBefore"
+	querySort          = "sort"
+	queryLimit         = "limit"
+	queryContinue      = "continue"
+	queryView          = "view"
+
+	// viewSummary is the value of the view parameter selecting TFJobSummary items.
+	viewSummary = "summary"
+
+	sortByName              = "name"
+	sortByCreationTimestamp = "creationTimestamp"
+)
+
+// TFJobSummary is a lightweight description of a TFJob.
+// It omits the pod templates, which make up most of the size of a TFJob.
+type TFJobSummary struct {
+	Name              string                           `json:"name"`
+	Namespace         string                           `json:"namespace"`
+	UID               string                           `json:"uid"`
+	Labels            map[string]string                `json:"labels,omitempty"`
+	CreationTimestamp metav1.Time                      `json:"creationTimestamp"`
+	Replicas          map[v1alpha2.TFReplicaType]int32 `json:"replicas"`
+	Condition         v1alpha2.TFJobConditionType      `json:"condition,omitempty"`
+	Status            v1alpha2.TFJobStatus             `json:"status"`
+}
+
+// TFJobSummaryList is a list of TFJobSummary, returned when view=summary.
+type TFJobSummaryList struct {
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TFJobSummary `json:"items"`
+}
+
+// tfJobListQuery holds the parsed query parameters of a TFJob list request.
+// Label selector, limit and continue are handled by the API server,
+// everything else is applied by the dashboard to the page it gets back.
+type tfJobListQuery struct {
+	listOptions   metav1.ListOptions
+	condition     v1alpha2.TFJobConditionType
+	namePrefix    string
+	createdAfter  *time.Time
+	createdBefore *time.Time
+	sortBy        string
+	descending    bool
+	summary       bool
+}
+
+// newTFJobListQuery parses and validates the query parameters of a TFJob list request.
+func newTFJobListQuery(params url.Values) (*tfJobListQuery, error) {
+	q := &tfJobListQuery{
+		condition:  v1alpha2.TFJobConditionType(params.Get(queryCondition)),
+		namePrefix: params.Get(queryNamePrefix),
+	}
+
+	if s := params.Get(queryLabelSelector); s != "" {
+		if _, err := labels.Parse(s); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", queryLabelSelector, s, err)
+		}
+		q.listOptions.LabelSelector = s
+	}
+
+	if s := params.Get(queryLimit); s != "" {
+		limit, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a non-negative integer", queryLimit, s)
+		}
+		q.listOptions.Limit = limit
+	}
+	q.listOptions.Continue = params.Get(queryContinue)
+
+	var err error
+	if q.createdAfter, err = parseTime(params, queryCreatedAfter); err != nil {
+		return nil, err
+	}
+	if q.createdBefore, err = parseTime(params, queryCreatedBefore); err != nil {
+		return nil, err
+	}
+
+	if s := params.Get(querySort); s != "" {
+		q.sortBy = strings.TrimPrefix(s, "-")
+		q.descending = strings.HasPrefix(s, "-")
+		if q.sortBy != sortByName && q.sortBy != sortByCreationTimestamp {
+			return nil, fmt.Errorf("invalid %s %q: must be one of %s, %s, optionally prefixed by -",
+				querySort, s, sortByName, sortByCreationTimestamp)
+		}
+	}
+
+	switch v := params.Get(queryView); v {
+	case "":
+	case viewSummary:
+		q.summary = true
+	default:
+		return nil, fmt.Errorf("invalid %s %q: only %s is supported", queryView, v, viewSummary)
+	}
+
+	return q, nil
+}
+
+// parseTime parses an RFC3339 timestamp from the given query parameter, if present.
+func parseTime(params url.Values, name string) (*time.Time, error) {
+	s := params.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: must be an RFC3339 timestamp", name, s)
+	}
+	return &t, nil
+}
+
+// matches returns true if the TFJob passes all the filters of the query.
+func (q *tfJobListQuery) matches(job *v1alpha2.TFJob) bool {
+	if q.namePrefix != "" && !strings.HasPrefix(job.Name, q.namePrefix) {
+		return false
+	}
+	created := job.CreationTimestamp.Time
+	if q.createdAfter != nil && created.Before(*q.createdAfter) {
+		return false
+	}
+	if q.createdBefore != nil && !created.Before(*q.createdBefore) {
+		return false
+	}
+	if q.condition != "" && !strings.EqualFold(string(currentCondition(job)), string(q.condition)) {
+		return false
+	}
+	return true
+}
+
+// filter returns the TFJobs matching the query, sorted as requested.
+// Sorting only applies to the current page when the list is paginated.
+func (q *tfJobListQuery) filter(jobs []v1alpha2.TFJob) []v1alpha2.TFJob {
+	result := make([]v1alpha2.TFJob, 0, len(jobs))
+	for i := range jobs {
+		if q.matches(&jobs[i]) {
+			result = append(result, jobs[i])
+		}
+	}
+
+	var less func(i, j int) bool
+	switch q.sortBy {
+	case sortByName:
+		less = func(i, j int) bool {
+			return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+		}
+	case sortByCreationTimestamp:
+		less = func(i, j int) bool {
+			return result[i].CreationTimestamp.Before(&result[j].CreationTimestamp)
+		}
+	default:
+		return result
+	}
+	if q.descending {
+		sort.SliceStable(result, func(i, j int) bool { return less(j, i) })
+	} else {
+		sort.SliceStable(result, less)
+	}
+	return result
+}
+
+// currentCondition returns the type of the latest true condition of the TFJob.
+func currentCondition(job *v1alpha2.TFJob) v1alpha2.TFJobConditionType {
+	for i := len(job.Status.Conditions) - 1; i >= 0; i-- {
+		if job.Status.Conditions[i].Status == v1.ConditionTrue {
+			return job.Status.Conditions[i].Type
+		}
+	}
+	return ""
+}
+
+// summarize converts the TFJobs to their summary form.
+func summarize(jobs []v1alpha2.TFJob) []TFJobSummary {
+	summaries := make([]TFJobSummary, 0, len(jobs))
+	for i := range jobs {
+		job := &jobs[i]
+		replicas := make(map[v1alpha2.TFReplicaType]int32, len(job.Spec.TFReplicaSpecs))
+		for rtype, spec := range job.Spec.TFReplicaSpecs {
+			if spec != nil && spec.Replicas != nil {
+				replicas[rtype] = *spec.Replicas
+			} else {
+				replicas[rtype] = 1
+			}
+		}
+		summaries = append(summaries, TFJobSummary{
+			Name:              job.Name,
+			Namespace:         job.Namespace,
+			UID:               string(job.UID),
+			Labels:            job.Labels,
+			CreationTimestamp: job.CreationTimestamp,
+			Replicas:          replicas,
+			Condition:         currentCondition(job),
+			Status:            job.Status,
+		})
+	}
+	return summaries
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+func newTestTFJob(name string, created time.Time, conditions ...v1alpha2.TFJobConditionType) v1alpha2.TFJob {
+	job := v1alpha2.TFJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         metav1.NamespaceDefault,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+	for _, c := range conditions {
+		job.Status.Conditions = append(job.Status.Conditions, v1alpha2.TFJobCondition{
+			Type:   c,
+			Status: v1.ConditionTrue,
+		})
+	}
+	return job
+}
+
+func TestNewTFJobListQuery(t *testing.T) {
+	testCases := map[string]struct {
+		params      url.Values
+		expectError bool
+	}{
+		"empty": {
+			params: url.Values{},
+		},
+		"all parameters": {
+			params: url.Values{
+				queryCondition:     {"Running"},
+				queryLabelSelector: {"team=ml,env!=dev"},
+				queryNamePrefix:    {"mnist"},
+				queryCreatedAfter:  {"2018-01-01T00:00:00Z"},
+				queryCreatedBefore: {"2018-02-01T00:00:00Z"},
+				querySort:          {"-creationTimestamp"},
+				queryLimit:         {"500"},
+				queryContinue:      {"token"},
+				queryView:          {viewSummary},
+			},
+		},
+		"invalid limit": {
+			params:      url.Values{queryLimit: {"-1"}},
+			expectError: true,
+		},
+		"invalid label selector": {
+			params:      url.Values{queryLabelSelector: {"a=b=c"}},
+			expectError: true,
+		},
+		"invalid time": {
+			params:      url.Values{queryCreatedAfter: {"yesterday"}},
+			expectError: true,
+		},
+		"invalid sort": {
+			params:      url.Values{querySort: {"replicas"}},
+			expectError: true,
+		},
+		"invalid view": {
+			params:      url.Values{queryView: {"full"}},
+			expectError: true,
+		},
+	}
+	for name, tc := range testCases {
+		_, err := newTFJobListQuery(tc.params)
+		if tc.expectError && err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if !tc.expectError && err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
+
+func TestFilterTFJobs(t *testing.T) {
+	base := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	jobs := []v1alpha2.TFJob{
+		newTestTFJob("mnist-a", base, v1alpha2.TFJobCreated, v1alpha2.TFJobRunning),
+		newTestTFJob("mnist-b", base.Add(2*time.Hour), v1alpha2.TFJobCreated, v1alpha2.TFJobRunning, v1alpha2.TFJobFailed),
+		newTestTFJob("resnet", base.Add(time.Hour), v1alpha2.TFJobCreated),
+	}
+
+	testCases := map[string]struct {
+		params   url.Values
+		expected []string
+	}{
+		"no filter keeps the order": {
+			params:   url.Values{},
+			expected: []string{"mnist-a", "mnist-b", "resnet"},
+		},
+		"condition": {
+			params:   url.Values{queryCondition: {"running"}},
+			expected: []string{"mnist-a"},
+		},
+		"name prefix": {
+			params:   url.Values{queryNamePrefix: {"mnist"}},
+			expected: []string{"mnist-a", "mnist-b"},
+		},
+		"creation time range": {
+			params: url.Values{
+				queryCreatedAfter:  {"2018-01-01T00:30:00Z"},
+				queryCreatedBefore: {"2018-01-01T02:00:00Z"},
+			},
+			expected: []string{"resnet"},
+		},
+		"sort by creation timestamp descending": {
+			params:   url.Values{querySort: {"-creationTimestamp"}},
+			expected: []string{"mnist-b", "resnet", "mnist-a"},
+		},
+		"sort by name": {
+			params:   url.Values{querySort: {"name"}, queryNamePrefix: {"mnist"}},
+			expected: []string{"mnist-a", "mnist-b"},
+		},
+	}
+	for name, tc := range testCases {
+		q, err := newTFJobListQuery(tc.params)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		actual := []string{}
+		for _, job := range q.filter(jobs) {
+			actual = append(actual, job.Name)
+		}
+		if !reflect.DeepEqual(tc.expected, actual) {
+			t.Errorf("%s: expected %v, got %v", name, tc.expected, actual)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	job := newTestTFJob("mnist", time.Now(), v1alpha2.TFJobCreated, v1alpha2.TFJobRunning)
+	job.Spec.TFReplicaSpecs = map[v1alpha2.TFReplicaType]*v1alpha2.TFReplicaSpec{
+		v1alpha2.TFReplicaTypeWorker: {Replicas: v1alpha2.Int32(4)},
+		v1alpha2.TFReplicaTypePS:     {},
+	}
+	summaries := summarize([]v1alpha2.TFJob{job})
+	if len(summaries) != 1 {
+		t.Fatalf("Expected 1 summary, got %d", len(summaries))
+	}
+	s := summaries[0]
+	if s.Condition != v1alpha2.TFJobRunning {
+		t.Errorf("Expected condition %s, got %s", v1alpha2.TFJobRunning, s.Condition)
+	}
+	expectedReplicas := map[v1alpha2.TFReplicaType]int32{
+		v1alpha2.TFReplicaTypeWorker: 4,
+		v1alpha2.TFReplicaTypePS:     1,
+	}
+	if !reflect.DeepEqual(expectedReplicas, s.Replicas) {
+		t.Errorf("Expected replicas %v, got %v", expectedReplicas, s.Replicas)
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	log "github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeflow/tf-operator/dashboard/backend/client"
+	"github.com/kubeflow/tf-operator/dashboard/backend/stream"
+)
+
+const (
+	// queryResourceVersion resumes a stream after the event with this resource version.
+	// Browsers reconnecting an EventSource send it as the Last-Event-ID header instead.
+	queryResourceVersion = "resourceVersion"
+	headerLastEventID    = "Last-Event-ID"
+
+	// keepaliveInterval is how often a comment is sent on idle streams,
+	// so that proxies do not close them.
+	keepaliveInterval = 30 * time.Second
+
+	mimeEventStream = "text/event-stream"
+)
+
+// createWatchContainer creates the container serving the event streams.
+// It is separate from the API container since the streams must be flushed
+// after each event, which the compressing writer of that container does not support.
+func (apiHandler *APIHandler) createWatchContainer() *restful.Container {
+	watchContainer := restful.NewContainer()
+
+	cors := restful.CrossOriginResourceSharing{
+		AllowedHeaders: []string{"Accept", "Authorization", headerLastEventID},
+		AllowedMethods: []string{"GET"},
+		CookiesAllowed: false,
+		Container:      watchContainer,
+	}
+	watchContainer.Filter(cors.Filter)
+	watchContainer.Filter(watchContainer.OPTIONSFilter)
+
+	watchWs := new(restful.WebService)
+	watchWs.Path("/tfjobs/api/watch").
+		Produces(mimeEventStream)
+
+	for _, path := range []string{"/tfjob", "/tfjob/{namespace}", "/tfjob/{namespace}/{tfjob}"} {
+		watchWs.Route(
+			watchWs.GET(path).
+				To(apiHandler.handleWatchTFJobs).
+				Param(watchWs.QueryParameter(queryResourceVersion, "resume the stream after the event with this resource version")).
+				Writes(stream.Event{}))
+	}
+
+	watchContainer.Add(watchWs)
+	return watchContainer
+}
+
+// handleWatchTFJobs streams the changes of TFJobs and of the phases of their
+// pods as server-sent events. The id of each event is its resource version.
+func (apiHandler *APIHandler) handleWatchTFJobs(request *restful.Request, response *restful.Response) {
+	filter := stream.Filter{
+		Namespace: request.PathParameter("namespace"),
+		TFJobName: request.PathParameter("tfjob"),
+	}
+	flusher, ok := response.ResponseWriter.(http.Flusher)
+	if !ok {
+		if err := response.WriteError(http.StatusInternalServerError, fmt.Errorf("streaming is not supported")); err != nil {
+			log.Errorf("Failed to write response: %v", err)
+		}
+		return
+	}
+
+	clients, ok := apiHandler.clientsFor(request, response)
+	if !ok {
+		return
+	}
+	// The events come from informers shared by all users, check that this user may see them.
+	if err := checkWatchAccess(clients, filter); err != nil {
+		log.Infof("denied watch of TFJobs %+v: %v", filter, err)
+		writeStatusError(response, err)
+		return
+	}
+
+	resourceVersion := request.QueryParameter(queryResourceVersion)
+	if resourceVersion == "" {
+		resourceVersion = request.HeaderParameter(headerLastEventID)
+	}
+	sub, err := apiHandler.options.Hub.Subscribe(filter, resourceVersion)
+	if err != nil {
+		log.Warningf("failed to watch TFJobs %+v: %v", filter, err)
+		writeStatusError(response, err)
+		return
+	}
+	defer sub.Close()
+
+	response.Header().Set("Content-Type", mimeEventStream)
+	response.Header().Set("Cache-Control", "no-cache")
+	response.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+	closed := request.Request.Context().Done()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-keepalive.C:
+			if _, err := io.WriteString(response, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.Events:
+			if !ok {
+				// The subscriber fell behind, the client reconnects with its last event id.
+				return
+			}
+			if err := writeEvent(response, &e); err != nil {
+				log.Infof("failed to write event: %v", err)
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes the event in the server-sent events format.
+func writeEvent(w io.Writer, e *stream.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", e.ResourceVersion, data)
+	return err
+}
+
+// checkWatchAccess returns an error if the user of the clients can not read
+// the TFJobs and pods selected by the filter.
+func checkWatchAccess(clients *client.Clients, filter stream.Filter) error {
+	if filter.TFJobName != "" {
+		if _, err := clients.TFJobClient.KubeflowV1alpha2().TFJobs(filter.Namespace).Get(filter.TFJobName, metav1.GetOptions{}); err != nil {
+			return err
+		}
+	} else if _, err := clients.TFJobClient.KubeflowV1alpha2().TFJobs(filter.Namespace).List(metav1.ListOptions{Limit: 1}); err != nil {
+		return err
+	}
+	_, err := clients.ClientSet.CoreV1().Pods(filter.Namespace).List(metav1.ListOptions{Limit: 1})
+	return err
+}
@@ -1,21 +1,48 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 
 	"github.com/kubeflow/tf-operator/dashboard/backend/client"
 	"github.com/kubeflow/tf-operator/dashboard/backend/handler"
+	"github.com/kubeflow/tf-operator/dashboard/backend/stream"
+	"github.com/kubeflow/tf-operator/pkg/util/signals"
 )
 
 func main() {
+	var authMode string
+	auth := client.AuthOptions{}
+	opts := handler.Options{}
+
+	flag.StringVar(&authMode, "auth-mode", string(client.AuthModeServiceAccount),
+		`How requests to the apiserver are authenticated. One of
+		 serviceaccount: use the service account of the dashboard for every request,
+		 token: forward the bearer token of the incoming request,
+		 impersonate: impersonate the user set in the headers by an authenticating proxy.`)
+	flag.StringVar(&auth.UserHeader, "user-header", client.DefaultUserHeader,
+		"Header holding the user name when --auth-mode=impersonate")
+	flag.StringVar(&auth.GroupHeader, "group-header", client.DefaultGroupHeader,
+		"Header holding the groups of the user when --auth-mode=impersonate")
+	flag.BoolVar(&opts.AllowNamespaceCreation, "allow-namespace-creation", false,
+		"Create the namespace of a deployed TFJob if it does not exist")
+	watchHistory := flag.Int("watch-history-size", stream.DefaultHistorySize,
+		"Number of TFJob and pod events kept to resume the event streams")
+	flag.Parse()
+	auth.Mode = client.AuthMode(authMode)
+
 	log.SetOutput(os.Stdout)
-	cm, err := client.NewClientManager()
+	cm, err := client.NewClientManager(auth)
 	if err != nil {
 		log.Fatalf("Error while initializing connection to Kubernetes apiserver: %v", err)
 	}
-	apiHandler, err := handler.CreateHTTPAPIHandler(cm)
+	// The informers of the event stream stop on the first shutdown signal.
+	stopCh := signals.SetupSignalHandler()
+	opts.Hub = stream.NewHub(cm.ClientSet, cm.TFJobClient, *watchHistory, stopCh)
+	apiHandler, err := handler.CreateHTTPAPIHandler(cm, opts)
 	if err != nil {
 		log.Fatalf("Error while creating the API Handler: %v", err)
 	}
@@ -26,7 +53,13 @@ func main() {
 	p := ":8080"
 	log.Println("Dashboard available at /tfjobs/ui/ on port", p)
 
-	if err = http.ListenAndServe(p, nil); err != nil {
+	server := &http.Server{Addr: p}
+	go func() {
+		<-stopCh
+		log.Println("Shutting down the dashboard")
+		server.Shutdown(context.Background())
+	}()
+	if err = server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Failed to serve: %v", err)
 	}
 }
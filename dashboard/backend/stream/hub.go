@@ -0,0 +1,391 @@
+// Package stream pushes changes of TFJobs and of their pods to the dashboard.
+// A single Hub watches the apiserver through shared informers and fans the
+// events out to every connected client, so that the number of watches does
+// not grow with the number of open dashboards.
+package stream
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	tfjobinformers "github.com/kubeflow/tf-operator/pkg/client/informers/externalversions/kubeflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+)
+
+const (
+	// DefaultHistorySize is the default number of events kept to resume streams.
+	DefaultHistorySize = 1000
+
+	// KindTFJob and KindPod are the kinds of objects carried by events.
+	KindTFJob = "TFJob"
+	KindPod   = "Pod"
+
+	// subscriberBufferSize is the number of events queued for a subscriber.
+	// A subscriber which falls further behind is dropped and has to resume.
+	subscriberBufferSize = 256
+
+	// labels set by the operator on the pods of a TFJob.
+	tfReplicaTypeLabel  = "tf-replica-type"
+	tfReplicaIndexLabel = "tf-replica-index"
+)
+
+// cacheSyncTimeout bounds the wait of a subscription for the informers.
+var cacheSyncTimeout = time.Minute
+
+// Event is a change of a TFJob, or of the phase of one of its pods.
+type Event struct {
+	// Type is one of ADDED, MODIFIED or DELETED.
+	Type watch.EventType `json:"type"`
+	// Kind is either TFJob or Pod.
+	Kind string `json:"kind"`
+	// Object is a *v1alpha2.TFJob or a *PodStatus.
+	Object interface{} `json:"object"`
+	// ResourceVersion of the object, used to resume a stream after this event.
+	ResourceVersion string `json:"-"`
+
+	namespace string
+	tfJobName string
+}
+
+// PodStatus is the part of a pod of a TFJob which is streamed.
+type PodStatus struct {
+	Name         string      `json:"name"`
+	Namespace    string      `json:"namespace"`
+	TFJobName    string      `json:"tfJobName"`
+	ReplicaType  string      `json:"replicaType"`
+	ReplicaIndex string      `json:"replicaIndex"`
+	Phase        v1.PodPhase `json:"phase"`
+	NodeName     string      `json:"nodeName,omitempty"`
+}
+
+// Filter selects the events sent to a subscriber.
+type Filter struct {
+	// Namespace of the TFJobs, all namespaces if empty.
+	Namespace string
+	// TFJobName restricts the events to a single TFJob and its pods.
+	TFJobName string
+}
+
+func (f Filter) matches(e *Event) bool {
+	if f.Namespace != "" && f.Namespace != e.namespace {
+		return false
+	}
+	return f.TFJobName == "" || f.TFJobName == e.tfJobName
+}
+
+// Subscription receives the events matching its filter.
+type Subscription struct {
+	// Events is closed when the subscription is closed, or when the
+	// subscriber fell behind. In the latter case it should resume from the
+	// resource version of the last event it received.
+	Events <-chan Event
+
+	hub    *Hub
+	events chan Event
+}
+
+// Close stops the delivery of events to the subscription.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.unsubscribeLocked(s)
+}
+
+// Hub watches TFJobs and their pods and dispatches the changes to subscribers.
+type Hub struct {
+	kubeClient  kubernetes.Interface
+	tfJobClient versioned.Interface
+	historySize int
+	stopCh      <-chan struct{}
+
+	// startMu guards running and synced. A subscription waits for the caches
+	// to sync, the next one waits again if they did not in time.
+	startMu       sync.Mutex
+	running       bool
+	synced        bool
+	tfJobInformer cache.SharedIndexInformer
+	podInformer   cache.SharedIndexInformer
+
+	// mu guards history and subscribers. Events are published and
+	// subscriptions are set up under mu, so that no event is lost in between.
+	mu          sync.Mutex
+	history     []Event
+	subscribers map[*Subscription]Filter
+}
+
+// NewHub creates a Hub. Its informers are started on the first subscription
+// and run until stopCh is closed.
+func NewHub(kubeClient kubernetes.Interface, tfJobClient versioned.Interface, historySize int, stopCh <-chan struct{}) *Hub {
+	h := &Hub{
+		kubeClient:  kubeClient,
+		tfJobClient: tfJobClient,
+		historySize: historySize,
+		stopCh:      stopCh,
+		subscribers: make(map[*Subscription]Filter),
+	}
+
+	indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+	h.tfJobInformer = tfjobinformers.NewTFJobInformer(tfJobClient, metav1.NamespaceAll, 0, indexers)
+	// Only watch the pods created by the operator.
+	h.podInformer = coreinformers.NewFilteredPodInformer(kubeClient, metav1.NamespaceAll, 0, indexers, func(options *metav1.ListOptions) {
+		options.LabelSelector = labels.SelectorFromSet(labels.Set{generator.LabelGroupName: v1alpha2.GroupName}).String()
+	})
+
+	h.tfJobInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			h.publishTFJob(watch.Added, obj)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			if old.(*v1alpha2.TFJob).ResourceVersion != cur.(*v1alpha2.TFJob).ResourceVersion {
+				h.publishTFJob(watch.Modified, cur)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			h.publishTFJob(watch.Deleted, obj)
+		},
+	})
+	h.podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			h.publishPod(watch.Added, obj)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			// Only the phase of the pods is streamed.
+			if old.(*v1.Pod).Status.Phase != cur.(*v1.Pod).Status.Phase {
+				h.publishPod(watch.Modified, cur)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			h.publishPod(watch.Deleted, obj)
+		},
+	})
+	return h
+}
+
+// start runs the informers once and waits for their caches to be synced.
+func (h *Hub) start() error {
+	h.startMu.Lock()
+	defer h.startMu.Unlock()
+	if h.synced {
+		return nil
+	}
+	select {
+	case <-h.stopCh:
+		return fmt.Errorf("the event stream is stopped")
+	default:
+	}
+	if !h.running {
+		log.Info("Starting the informers of the event stream")
+		go h.tfJobInformer.Run(h.stopCh)
+		go h.podInformer.Run(h.stopCh)
+		h.running = true
+	}
+
+	// Give up after cacheSyncTimeout, or when the hub is stopped. Whoever
+	// stops the timer first closes stopCh.
+	stopCh := make(chan struct{})
+	timer := time.AfterFunc(cacheSyncTimeout, func() { close(stopCh) })
+	defer func() {
+		if timer.Stop() {
+			close(stopCh)
+		}
+	}()
+	go func() {
+		select {
+		case <-h.stopCh:
+			if timer.Stop() {
+				close(stopCh)
+			}
+		case <-stopCh:
+		}
+	}()
+	if !cache.WaitForCacheSync(stopCh, h.tfJobInformer.HasSynced, h.podInformer.HasSynced) {
+		return fmt.Errorf("failed to wait for the tfjob and pod caches to sync")
+	}
+	h.synced = true
+	return nil
+}
+
+// Subscribe returns a subscription to the events matching the filter.
+// If resourceVersion is the version of an event still in the history, the
+// events following it are replayed. Otherwise the current state of every
+// matching object is sent first as ADDED events.
+func (h *Hub) Subscribe(filter Filter, resourceVersion string) (*Subscription, error) {
+	if err := h.start(); err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	backlog, ok := h.replayLocked(filter, resourceVersion)
+	if !ok {
+		backlog = h.snapshot(filter)
+	}
+
+	size := subscriberBufferSize
+	if len(backlog) > size {
+		size = len(backlog)
+	}
+	s := &Subscription{
+		hub:    h,
+		events: make(chan Event, size),
+	}
+	s.Events = s.events
+	for _, e := range backlog {
+		s.events <- e
+	}
+	h.subscribers[s] = filter
+	return s, nil
+}
+
+// replayLocked returns the events of the history following resourceVersion.
+// It returns false if resourceVersion is not in the history anymore.
+func (h *Hub) replayLocked(filter Filter, resourceVersion string) ([]Event, bool) {
+	if resourceVersion == "" {
+		return nil, false
+	}
+	for i := len(h.history) - 1; i >= 0; i-- {
+		if h.history[i].ResourceVersion != resourceVersion {
+			continue
+		}
+		var events []Event
+		for _, e := range h.history[i+1:] {
+			if filter.matches(&e) {
+				events = append(events, e)
+			}
+		}
+		return events, true
+	}
+	return nil, false
+}
+
+// snapshot returns ADDED events for all the TFJobs and pods matching the filter.
+func (h *Hub) snapshot(filter Filter) []Event {
+	var events []Event
+	for _, obj := range listFromIndexer(h.tfJobInformer.GetIndexer(), filter.Namespace) {
+		if e, ok := newTFJobEvent(watch.Added, obj); ok && filter.matches(&e) {
+			events = append(events, e)
+		}
+	}
+	for _, obj := range listFromIndexer(h.podInformer.GetIndexer(), filter.Namespace) {
+		if e, ok := newPodEvent(watch.Added, obj); ok && filter.matches(&e) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func listFromIndexer(indexer cache.Indexer, namespace string) []interface{} {
+	if namespace == "" {
+		return indexer.List()
+	}
+	objs, err := indexer.ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		log.Warningf("failed to list objects under namespace %v: %v", namespace, err)
+	}
+	return objs
+}
+
+func (h *Hub) publishTFJob(eventType watch.EventType, obj interface{}) {
+	if e, ok := newTFJobEvent(eventType, obj); ok {
+		h.publish(e)
+	}
+}
+
+func (h *Hub) publishPod(eventType watch.EventType, obj interface{}) {
+	if e, ok := newPodEvent(eventType, obj); ok {
+		h.publish(e)
+	}
+}
+
+// publish records the event in the history and sends it to the matching subscribers.
+func (h *Hub) publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.history = append(h.history, e)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+
+	for s, filter := range h.subscribers {
+		if !filter.matches(&e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			log.Infof("dropping a subscriber of the event stream which fell behind")
+			h.unsubscribeLocked(s)
+		}
+	}
+}
+
+func (h *Hub) unsubscribeLocked(s *Subscription) {
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.events)
+	}
+}
+
+// newTFJobEvent builds the event of a TFJob, obj may be a tombstone.
+func newTFJobEvent(eventType watch.EventType, obj interface{}) (Event, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	job, ok := obj.(*v1alpha2.TFJob)
+	if !ok {
+		return Event{}, false
+	}
+	return Event{
+		Type:            eventType,
+		Kind:            KindTFJob,
+		Object:          job,
+		ResourceVersion: job.ResourceVersion,
+		namespace:       job.Namespace,
+		tfJobName:       job.Name,
+	}, true
+}
+
+// newPodEvent builds the event of a pod owned by a TFJob, obj may be a tombstone.
+func newPodEvent(eventType watch.EventType, obj interface{}) (Event, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return Event{}, false
+	}
+	controllerRef := metav1.GetControllerOf(pod)
+	if controllerRef == nil || controllerRef.Kind != v1alpha2.Kind {
+		return Event{}, false
+	}
+	return Event{
+		Type: eventType,
+		Kind: KindPod,
+		Object: &PodStatus{
+			Name:         pod.Name,
+			Namespace:    pod.Namespace,
+			TFJobName:    controllerRef.Name,
+			ReplicaType:  pod.Labels[tfReplicaTypeLabel],
+			ReplicaIndex: pod.Labels[tfReplicaIndexLabel],
+			Phase:        pod.Status.Phase,
+			NodeName:     pod.Spec.NodeName,
+		},
+		ResourceVersion: pod.ResourceVersion,
+		namespace:       pod.Namespace,
+		tfJobName:       controllerRef.Name,
+	}, true
+}
@@ -0,0 +1,204 @@
+package stream
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobfake "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned/fake"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+// newTestHub returns a Hub whose informers are never started,
+// their caches are filled by the tests.
+func newTestHub(historySize int) *Hub {
+	h := NewHub(kubefake.NewSimpleClientset(), tfjobfake.NewSimpleClientset(), historySize, make(chan struct{}))
+	h.running, h.synced = true, true
+	return h
+}
+
+func newTestTFJob(namespace, name, resourceVersion string) *v1alpha2.TFJob {
+	job := testutil.NewTFJob(1, 0)
+	job.Namespace = namespace
+	job.Name = name
+	job.ResourceVersion = resourceVersion
+	return job
+}
+
+func receive(t *testing.T, sub *Subscription, n int) []Event {
+	var events []Event
+	for i := 0; i < n; i++ {
+		select {
+		case e := <-sub.Events:
+			events = append(events, e)
+		default:
+			t.Fatalf("Expected %d events, got %d", n, len(events))
+		}
+	}
+	select {
+	case e := <-sub.Events:
+		t.Fatalf("Unexpected event %+v", e)
+	default:
+	}
+	return events
+}
+
+func TestSubscribeSnapshot(t *testing.T) {
+	h := newTestHub(DefaultHistorySize)
+	job := newTestTFJob("kubeflow", "mnist", "1")
+	other := newTestTFJob("default", "cifar", "2")
+	pod := testutil.NewPod(job, "worker", 0, t)
+	pod.ResourceVersion = "3"
+	pod.Status.Phase = v1.PodRunning
+	h.tfJobInformer.GetIndexer().Add(job)
+	h.tfJobInformer.GetIndexer().Add(other)
+	h.podInformer.GetIndexer().Add(pod)
+
+	sub, err := h.Subscribe(Filter{Namespace: "kubeflow"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer sub.Close()
+
+	events := receive(t, sub, 2)
+	if events[0].Kind != KindTFJob || events[0].Object.(*v1alpha2.TFJob).Name != "mnist" {
+		t.Errorf("Expected the TFJob first, got %+v", events[0])
+	}
+	status, ok := events[1].Object.(*PodStatus)
+	if !ok {
+		t.Fatalf("Expected a pod status, got %+v", events[1])
+	}
+	if status.TFJobName != "mnist" || status.ReplicaType != "worker" || status.ReplicaIndex != "0" || status.Phase != v1.PodRunning {
+		t.Errorf("Unexpected pod status %+v", status)
+	}
+	for _, e := range events {
+		if e.Type != watch.Added {
+			t.Errorf("Expected ADDED events, got %v", e.Type)
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	h := newTestHub(DefaultHistorySize)
+	job := newTestTFJob("kubeflow", "mnist", "1")
+
+	sub, err := h.Subscribe(Filter{Namespace: "kubeflow", TFJobName: "mnist"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer sub.Close()
+
+	h.publishTFJob(watch.Added, job)
+	h.publishTFJob(watch.Added, newTestTFJob("kubeflow", "cifar", "2"))
+	pod := testutil.NewPod(job, "worker", 0, t)
+	pod.ResourceVersion = "3"
+	h.publishPod(watch.Deleted, pod)
+	// Pods which are not owned by a TFJob are ignored.
+	pod = pod.DeepCopy()
+	pod.OwnerReferences = nil
+	h.publishPod(watch.Added, pod)
+
+	events := receive(t, sub, 2)
+	if events[0].ResourceVersion != "1" || events[1].ResourceVersion != "3" || events[1].Type != watch.Deleted {
+		t.Errorf("Unexpected events %+v", events)
+	}
+}
+
+func TestSubscribeResume(t *testing.T) {
+	h := newTestHub(2)
+	for _, rv := range []string{"1", "2", "3"} {
+		h.publishTFJob(watch.Modified, newTestTFJob("kubeflow", "mnist", rv))
+	}
+	current := newTestTFJob("kubeflow", "mnist", "3")
+	h.tfJobInformer.GetIndexer().Add(current)
+
+	// "2" is still in the history, only the following events are replayed.
+	sub, err := h.Subscribe(Filter{}, "2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	events := receive(t, sub, 1)
+	if events[0].ResourceVersion != "3" || events[0].Type != watch.Modified {
+		t.Errorf("Unexpected events %+v", events)
+	}
+	sub.Close()
+
+	// "1" has been evicted from the history, the current state is sent instead.
+	sub, err = h.Subscribe(Filter{}, "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	events = receive(t, sub, 1)
+	if events[0].ResourceVersion != "3" || events[0].Type != watch.Added {
+		t.Errorf("Unexpected events %+v", events)
+	}
+	sub.Close()
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	h := newTestHub(DefaultHistorySize)
+	sub, err := h.Subscribe(Filter{}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i := 0; i <= subscriberBufferSize; i++ {
+		h.publishTFJob(watch.Added, newTestTFJob("kubeflow", "mnist", "1"))
+	}
+	for i := 0; i < subscriberBufferSize; i++ {
+		<-sub.Events
+	}
+	if _, ok := <-sub.Events; ok {
+		t.Errorf("Expected the events of a slow subscriber to be closed")
+	}
+	// Closing a dropped subscription is a no-op.
+	sub.Close()
+}
+
+func TestSubscribeRetriesStart(t *testing.T) {
+	defer func(timeout time.Duration) { cacheSyncTimeout = timeout }(cacheSyncTimeout)
+	cacheSyncTimeout = 100 * time.Millisecond
+
+	// The pods cannot be listed until failing is cleared.
+	failing := int32(1)
+	kubeClient := kubefake.NewSimpleClientset()
+	kubeClient.PrependReactor("list", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		if atomic.LoadInt32(&failing) == 1 {
+			return true, nil, errors.New("apiserver unavailable")
+		}
+		return false, nil, nil
+	})
+	stopCh := make(chan struct{})
+	h := NewHub(kubeClient, tfjobfake.NewSimpleClientset(), DefaultHistorySize, stopCh)
+
+	if _, err := h.Subscribe(Filter{}, ""); err == nil {
+		t.Fatalf("Expected an error while the caches cannot sync")
+	}
+
+	atomic.StoreInt32(&failing, 0)
+	cacheSyncTimeout = wait.ForeverTestTimeout
+	sub, err := h.Subscribe(Filter{}, "")
+	if err != nil {
+		t.Fatalf("Expected the next subscription to start the hub, got %v", err)
+	}
+	sub.Close()
+	close(stopCh)
+}
+
+func TestSubscribeStopped(t *testing.T) {
+	stopCh := make(chan struct{})
+	close(stopCh)
+	h := NewHub(kubefake.NewSimpleClientset(), tfjobfake.NewSimpleClientset(), DefaultHistorySize, stopCh)
+	if _, err := h.Subscribe(Filter{}, ""); err == nil {
+		t.Errorf("Expected an error once the hub is stopped")
+	}
+}
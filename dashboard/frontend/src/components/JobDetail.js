@@ -22,6 +22,9 @@ const JobDetail = ({ tfjob }) => {
             />
             {/* <InfoEntry name="Runtime Id" value={tfjob.spec.RuntimeId} /> */}
             <InfoEntry name="Status" value={status} />
+            {tfjob.status.tensorBoardURL && (
+              <InfoEntry name="TensorBoard" value={tfjob.status.tensorBoardURL} />
+            )}
           </div>
         </CardText>
       </Card>
@@ -27,4 +27,28 @@ const (
 	DefaultPort = 2222
 	// DefaultRestartPolicy is default RestartPolicy for TFReplicaSpec.
 	DefaultRestartPolicy = RestartPolicyNever
+	// DefaultTensorBoardImage is the default image of TensorBoard.
+	DefaultTensorBoardImage = "tensorflow/tensorflow:1.8.0"
+	// DefaultTensorBoardPort is the port TensorBoard listens on.
+	DefaultTensorBoardPort = 6006
+	// DefaultPeerWaitImage is the default image of the init container
+	// waiting for the peers of a pod.
+	DefaultPeerWaitImage = "busybox:1.28"
+	// ClusterSpecMountPath is the directory of the cluster spec file in the
+	// tensorflow container, with ClusterSpecDeliveryConfigMap.
+	ClusterSpecMountPath = "/etc/tf-cluster-spec"
+	// ClusterSpecFileName is the name of the cluster spec file, a JSON
+	// object from the replica types to their addresses.
+	ClusterSpecFileName = "cluster.json"
+	// DefaultHostNetworkMinPort and DefaultHostNetworkMaxPort bound the
+	// ports allocated to the replicas in the host network.
+	DefaultHostNetworkMinPort = 20000
+	DefaultHostNetworkMaxPort = 29999
+	// DefaultPlacementTopologyKey is the default label of the nodes defining
+	// the topology domains of a placement, each node is a domain.
+	DefaultPlacementTopologyKey = "kubernetes.io/hostname"
+	// NotificationWebhooksAnnotation is the annotation of a tfjob with a
+	// comma separated list of URLs notified when its conditions change. Only
+	// the hosts allowed by the operator configuration are notified.
+	NotificationWebhooksAnnotation = "kubeflow.org/notification-webhooks"
 )
@@ -0,0 +1,111 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +resource:path=crontfjob
+
+// CronTFJob runs a TFJob on a cron schedule, e.g. to retrain a model nightly.
+type CronTFJob struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the desired behavior of the CronTFJob.
+	Spec CronTFJobSpec `json:"spec,omitempty"`
+
+	// Most recently observed status of the CronTFJob.
+	// Populated by the system.
+	// Read-only.
+	Status CronTFJobStatus `json:"status,omitempty"`
+}
+
+// CronTFJobSpec is a desired state description of the CronTFJob.
+type CronTFJobSpec struct {
+	// Schedule in the cron format, e.g. "0 2 * * *" for 2am every day.
+	// The schedule is interpreted in the time zone of the operator.
+	Schedule string `json:"schedule"`
+
+	// StartingDeadlineSeconds is how late a TFJob may be started after its
+	// scheduled time, e.g. when the operator was down. The runs missed for
+	// longer are skipped. If unspecified, there is no deadline.
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// ConcurrencyPolicy is what happens when a run is scheduled while the
+	// TFJob of a previous run is still running. Defaults to Allow.
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Suspend stops the following runs, the running TFJobs are not stopped.
+	// Defaults to false.
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// JobTemplate is the TFJob created for each run.
+	JobTemplate TFJobTemplateSpec `json:"jobTemplate"`
+
+	// SuccessfulJobsHistoryLimit is the number of succeeded TFJobs kept.
+	// Defaults to 3.
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit is the number of failed TFJobs kept.
+	// Defaults to 1.
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// ConcurrencyPolicy describes how the runs of a CronTFJob are handled when
+// the TFJob of a previous run is still running.
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyPolicyAllow runs the TFJobs concurrently.
+	ConcurrencyPolicyAllow ConcurrencyPolicy = "Allow"
+
+	// ConcurrencyPolicyForbid skips the new run.
+	ConcurrencyPolicyForbid ConcurrencyPolicy = "Forbid"
+
+	// ConcurrencyPolicyReplace deletes the running TFJobs and starts the new run.
+	ConcurrencyPolicyReplace ConcurrencyPolicy = "Replace"
+)
+
+// CronTFJobStatus represents the current observed state of the CronTFJob.
+type CronTFJobStatus struct {
+	// Active is the list of the running TFJobs.
+	Active []v1.ObjectReference `json:"active,omitempty"`
+
+	// LastScheduleTime is the scheduled time of the last TFJob started.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +resource:path=crontfjobs
+
+// CronTFJobList is a list of CronTFJobs.
+type CronTFJobList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// List of CronTFJobs.
+	Items []CronTFJob `json:"items"`
+}
@@ -27,12 +27,23 @@ func Int32(v int32) *int32 {
 	return &v
 }
 
+// Int64 is a helper routine that allocates a new int64 value
+// to store v and returns a pointer to it.
+func Int64(v int64) *int64 {
+	return &v
+}
+
 func addDefaultingFuncs(scheme *runtime.Scheme) error {
 	return RegisterDefaults(scheme)
 }
 
 // setDefaultPort sets the default ports for tensorflow container.
 func setDefaultPort(spec *v1.PodSpec) {
+	if len(spec.Containers) == 0 {
+		// Nothing to default, validation rejects such a spec.
+		return
+	}
+
 	index := 0
 	for i, container := range spec.Containers {
 		if container.Name == DefaultContainerName {
@@ -92,5 +103,121 @@ func SetDefaults_TFJob(tfjob *TFJob) {
 	for _, spec := range tfjob.Spec.TFReplicaSpecs {
 		setDefaultReplicas(spec)
 		setDefaultPort(&spec.Template.Spec)
+		if spec.Placement != nil {
+			setDefaultPlacement(spec.Placement)
+		}
+	}
+	if tfjob.Spec.TensorBoard != nil && tfjob.Spec.TensorBoard.Image == "" {
+		tfjob.Spec.TensorBoard.Image = DefaultTensorBoardImage
+	}
+	if tfjob.Spec.StartupPolicy != nil {
+		setDefaultStartupPolicy(tfjob.Spec.StartupPolicy)
+	}
+	if tfjob.Spec.HostNetwork != nil {
+		setDefaultHostNetwork(&tfjob.Spec)
+	}
+	if tfjob.Spec.ClusterSpecDelivery == "" {
+		tfjob.Spec.ClusterSpecDelivery = ClusterSpecDeliveryEnv
+	}
+	if tfjob.Spec.UpdateStrategy == "" {
+		tfjob.Spec.UpdateStrategy = UpdateStrategyRecreate
+	}
+}
+
+// setDefaultHostNetwork sets the default port range, and delivers the cluster
+// spec by a ConfigMap as the addresses of the nodes are not known beforehand.
+func setDefaultHostNetwork(spec *TFJobSpec) {
+	if spec.HostNetwork.MinPort == 0 {
+		spec.HostNetwork.MinPort = DefaultHostNetworkMinPort
+	}
+	if spec.HostNetwork.MaxPort == 0 {
+		spec.HostNetwork.MaxPort = DefaultHostNetworkMaxPort
+	}
+	if spec.ClusterSpecDelivery == "" {
+		spec.ClusterSpecDelivery = ClusterSpecDeliveryConfigMap
+	}
+}
+
+// setDefaultStartupPolicy starts the PS first unless an order is specified,
+// and sets the replica types of the order to correct case.
+func setDefaultStartupPolicy(policy *StartupPolicy) {
+	if len(policy.Order) == 0 {
+		policy.Order = []TFReplicaType{TFReplicaTypePS}
+	}
+	for i, t := range policy.Order {
+		for _, typ := range []TFReplicaType{TFReplicaTypePS, TFReplicaTypeWorker, TFReplicaTypeChief, TFReplicaTypeEval} {
+			if strings.ToLower(string(t)) == strings.ToLower(string(typ)) {
+				policy.Order[i] = typ
+			}
+		}
+	}
+	if policy.PeerWaitImage == "" {
+		policy.PeerWaitImage = DefaultPeerWaitImage
 	}
 }
+
+// setDefaultPlacement sets the default topology key and replica type to
+// colocate with, and sets the replica type to correct case.
+func setDefaultPlacement(placement *Placement) {
+	if placement.TopologyKey == "" {
+		placement.TopologyKey = DefaultPlacementTopologyKey
+	}
+	if placement.Policy != PlacementPolicyColocate {
+		return
+	}
+	if placement.ColocateWith == "" {
+		placement.ColocateWith = TFReplicaTypePS
+	}
+	for _, typ := range []TFReplicaType{TFReplicaTypePS, TFReplicaTypeWorker, TFReplicaTypeChief, TFReplicaTypeEval} {
+		if strings.ToLower(string(placement.ColocateWith)) == strings.ToLower(string(typ)) {
+			placement.ColocateWith = typ
+		}
+	}
+}
+
+// SetDefaults_TFJobSweep sets any unspecified values to defaults.
+func SetDefaults_TFJobSweep(sweep *TFJobSweep) {
+	spec := &sweep.Spec
+	if spec.Algorithm == "" {
+		spec.Algorithm = SweepAlgorithmGrid
+	}
+	if spec.Parallelism == nil {
+		spec.Parallelism = Int32(1)
+	}
+	if spec.ParameterInjection == "" {
+		spec.ParameterInjection = ParameterInjectionEnv
+	}
+	for i := range spec.Parameters {
+		if r := spec.Parameters[i].Range; r != nil && r.Scale == "" {
+			r.Scale = ParameterScaleLinear
+		}
+	}
+
+	// The template is defaulted as the TFJobs created from it.
+	tfjob := &TFJob{Spec: spec.Template.Spec}
+	SetDefaults_TFJob(tfjob)
+	spec.Template.Spec = tfjob.Spec
+}
+
+// SetDefaults_CronTFJob sets any unspecified values to defaults.
+func SetDefaults_CronTFJob(cron *CronTFJob) {
+	spec := &cron.Spec
+	if spec.ConcurrencyPolicy == "" {
+		spec.ConcurrencyPolicy = ConcurrencyPolicyAllow
+	}
+	if spec.Suspend == nil {
+		suspend := false
+		spec.Suspend = &suspend
+	}
+	if spec.SuccessfulJobsHistoryLimit == nil {
+		spec.SuccessfulJobsHistoryLimit = Int32(3)
+	}
+	if spec.FailedJobsHistoryLimit == nil {
+		spec.FailedJobsHistoryLimit = Int32(1)
+	}
+
+	// The template is defaulted as the TFJobs created from it.
+	tfjob := &TFJob{Spec: spec.JobTemplate.Spec}
+	SetDefaults_TFJob(tfjob)
+	spec.JobTemplate.Spec = tfjob.Spec
+}
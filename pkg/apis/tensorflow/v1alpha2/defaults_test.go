@@ -69,6 +69,8 @@ func expectedTFJob(restartPolicy RestartPolicy, portName string, port int32) *TF
 					},
 				},
 			},
+			ClusterSpecDelivery: ClusterSpecDeliveryEnv,
+			UpdateStrategy:      UpdateStrategyRecreate,
 		},
 	}
 }
@@ -0,0 +1,78 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+// DefaultExitCodeRules are evaluated when none of the ExitCodeRules of a
+// replica matches. Exit codes which match none of them fail the TFJob.
+//
+// Refers to http://tldp.org/LDP/abs/html/exitcodes.html, the following exit codes
+// are permanent errors:
+//   1: General errors
+//   2: Misuse of shell builtins
+//   126: Command invoked cannot execute
+//   127: Command not found
+//   128: Invalid argument to exit
+//   139(128+11): terminated by SIGSEGV(Invalid memory reference)
+//
+// The container is restarted if it exits due to the following signals,
+// which are usually caused by transient issues(e.g. VM was rescheduled):
+//   130(128+2): Container terminated by Control-C
+//   137(128+9): Container received a SIGKILL
+//   143(128+15): Container received a SIGTERM
+// Users may also exit with SIGUSR1(138 = 128 + 10) for errors which should be retried.
+// More info can be found in:
+//   http://tldp.org/LDP/abs/html/exitcodes.html,
+//   https://stackoverflow.com/questions/31297616/what-is-the-authoritative-list-of-docker-run-exit-codes
+var DefaultExitCodeRules = []ExitCodeRule{
+	{ExitCodes: &ExitCodeRange{Min: 1, Max: 2}, Action: ExitCodeActionFailJob},
+	{ExitCodes: &ExitCodeRange{Min: 126, Max: 128}, Action: ExitCodeActionFailJob},
+	{Signal: Int32(11), Action: ExitCodeActionFailJob},
+	{Signal: Int32(2), Action: ExitCodeActionRestartPod},
+	{Signal: Int32(9), Action: ExitCodeActionRestartPod},
+	{Signal: Int32(10), Action: ExitCodeActionRestartPod},
+	{Signal: Int32(15), Action: ExitCodeActionRestartPod},
+}
+
+// signalExitCodeBase is added to the number of the signal which killed a container.
+const signalExitCodeBase = 128
+
+// Matches returns true if the termination of a container with the given
+// exit code and reason matches the rule.
+func (r *ExitCodeRule) Matches(exitCode int32, reason string) bool {
+	if r.ExitCodes == nil && r.Signal == nil && r.Reason == "" {
+		return false
+	}
+	if r.ExitCodes != nil && (exitCode < r.ExitCodes.Min || exitCode > r.ExitCodes.Max) {
+		return false
+	}
+	if r.Signal != nil && exitCode != signalExitCodeBase+*r.Signal {
+		return false
+	}
+	return r.Reason == "" || r.Reason == reason
+}
+
+// ExitCodeActionFor returns the action of the first of the rules, then of
+// DefaultExitCodeRules, matching the termination of a container.
+// It returns ExitCodeActionFailJob if none matches.
+func ExitCodeActionFor(rules []ExitCodeRule, exitCode int32, reason string) ExitCodeAction {
+	for _, ruleSet := range [][]ExitCodeRule{rules, DefaultExitCodeRules} {
+		for i := range ruleSet {
+			if ruleSet[i].Matches(exitCode, reason) {
+				return ruleSet[i].Action
+			}
+		}
+	}
+	return ExitCodeActionFailJob
+}
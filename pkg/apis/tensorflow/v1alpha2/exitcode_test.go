@@ -0,0 +1,63 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"testing"
+)
+
+func TestExitCodeActionFor(t *testing.T) {
+	rules := []ExitCodeRule{
+		{ExitCodes: &ExitCodeRange{Min: 64, Max: 65}, Action: ExitCodeActionIgnore},
+		{Signal: Int32(9), Reason: "OOMKilled", Action: ExitCodeActionRestartJob},
+		{ExitCodes: &ExitCodeRange{Min: 1, Max: 1}, Action: ExitCodeActionRestartPod},
+	}
+
+	type tc struct {
+		rules    []ExitCodeRule
+		exitCode int32
+		reason   string
+		expected ExitCodeAction
+	}
+	testCase := []tc{
+		// The default rules.
+		{nil, 1, "Error", ExitCodeActionFailJob},
+		{nil, 2, "Error", ExitCodeActionFailJob},
+		{nil, 126, "Error", ExitCodeActionFailJob},
+		{nil, 127, "Error", ExitCodeActionFailJob},
+		{nil, 128, "Error", ExitCodeActionFailJob},
+		{nil, 139, "Error", ExitCodeActionFailJob},
+		{nil, 130, "Error", ExitCodeActionRestartPod},
+		{nil, 137, "OOMKilled", ExitCodeActionRestartPod},
+		{nil, 138, "Error", ExitCodeActionRestartPod},
+		{nil, 143, "Error", ExitCodeActionRestartPod},
+		{nil, 3, "Error", ExitCodeActionFailJob},
+		{nil, 255, "Error", ExitCodeActionFailJob},
+		// The rules of the replica are evaluated first.
+		{rules, 64, "Error", ExitCodeActionIgnore},
+		{rules, 65, "Error", ExitCodeActionIgnore},
+		{rules, 137, "OOMKilled", ExitCodeActionRestartJob},
+		{rules, 137, "Error", ExitCodeActionRestartPod},
+		{rules, 1, "Error", ExitCodeActionRestartPod},
+		{rules, 2, "Error", ExitCodeActionFailJob},
+	}
+
+	for _, c := range testCase {
+		actual := ExitCodeActionFor(c.rules, c.exitCode, c.reason)
+		if actual != c.expected {
+			t.Errorf("Expected %s for exit code %d (%s), got %s", c.expected, c.exitCode, c.reason, actual)
+		}
+	}
+}
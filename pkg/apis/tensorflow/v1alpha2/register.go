@@ -39,6 +39,20 @@ const (
 	Plural = "tfjobs"
 	// Singular is the singular for TFJob.
 	Singular = "tfjob"
+
+	// SweepKind is the kind name of TFJobSweep.
+	SweepKind = "TFJobSweep"
+	// SweepPlural is the Plural for TFJobSweep.
+	SweepPlural = "tfjobsweeps"
+	// SweepSingular is the singular for TFJobSweep.
+	SweepSingular = "tfjobsweep"
+
+	// CronKind is the kind name of CronTFJob.
+	CronKind = "CronTFJob"
+	// CronPlural is the Plural for CronTFJob.
+	CronPlural = "crontfjobs"
+	// CronSingular is the singular for CronTFJob.
+	CronSingular = "crontfjob"
 )
 
 var (
@@ -46,6 +60,10 @@ var (
 	SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: GroupVersion}
 	// SchemeGroupVersionKind is the GroupVersionKind of the resource.
 	SchemeGroupVersionKind = SchemeGroupVersion.WithKind(Kind)
+	// SweepGroupVersionKind is the GroupVersionKind of TFJobSweep.
+	SweepGroupVersionKind = SchemeGroupVersion.WithKind(SweepKind)
+	// CronGroupVersionKind is the GroupVersionKind of CronTFJob.
+	CronGroupVersionKind = SchemeGroupVersion.WithKind(CronKind)
 )
 
 func init() {
@@ -66,6 +84,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&TFJob{},
 		&TFJobList{},
+		&TFJobSweep{},
+		&TFJobSweepList{},
+		&CronTFJob{},
+		&CronTFJobList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -0,0 +1,234 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	common "github.com/kubeflow/tf-operator/pkg/apis/common/v1alpha2"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +resource:path=tfjobsweep
+
+// TFJobSweep is a hyperparameter sweep, which runs a TFJob for each trial
+// of a parameter space.
+type TFJobSweep struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the desired behavior of the TFJobSweep.
+	Spec TFJobSweepSpec `json:"spec,omitempty"`
+
+	// Most recently observed status of the TFJobSweep.
+	// Populated by the system.
+	// Read-only.
+	Status TFJobSweepStatus `json:"status,omitempty"`
+}
+
+// TFJobSweepSpec is a desired state description of the TFJobSweep.
+type TFJobSweepSpec struct {
+	// Template is the TFJob run for each trial. The parameters of the trial
+	// are injected into its tensorflow containers.
+	Template TFJobTemplateSpec `json:"template"`
+
+	// Algorithm generating the trials. Defaults to Grid.
+	Algorithm SweepAlgorithm `json:"algorithm,omitempty"`
+
+	// Parameters is the parameter space of the Grid and Random algorithms.
+	Parameters []SweepParameter `json:"parameters,omitempty"`
+
+	// Trials is the list of the parameters of each trial of the List algorithm.
+	Trials []map[string]string `json:"trials,omitempty"`
+
+	// MaxTrials is the number of trials of the Random algorithm.
+	// It caps the number of trials of the other algorithms.
+	MaxTrials *int32 `json:"maxTrials,omitempty"`
+
+	// Seed of the Random algorithm. If unspecified, it is derived from the
+	// UID of the TFJobSweep.
+	Seed *int64 `json:"seed,omitempty"`
+
+	// Parallelism is the maximum number of trials running at once.
+	// Defaults to 1.
+	Parallelism *int32 `json:"parallelism,omitempty"`
+
+	// ParameterInjection is how the parameters are passed to the tensorflow
+	// containers. Defaults to Env.
+	ParameterInjection ParameterInjection `json:"parameterInjection,omitempty"`
+
+	// TargetSucceededTrials stops the sweep once this many trials have
+	// succeeded: the running trials are stopped and the others are not run.
+	// With a training code which only succeeds once it reaches its target
+	// metric, a value of 1 stops the sweep at the first trial reaching it.
+	// If unspecified, all the trials are run.
+	TargetSucceededTrials *int32 `json:"targetSucceededTrials,omitempty"`
+}
+
+// TFJobTemplateSpec describes the TFJobs created from a template.
+type TFJobTemplateSpec struct {
+	// Labels and annotations of the TFJobs.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the TFJobs.
+	Spec TFJobSpec `json:"spec"`
+}
+
+// SweepAlgorithm is the algorithm generating the trials of a sweep.
+type SweepAlgorithm string
+
+const (
+	// SweepAlgorithmGrid runs a trial for each combination of the values of the parameters.
+	SweepAlgorithmGrid SweepAlgorithm = "Grid"
+
+	// SweepAlgorithmRandom runs MaxTrials trials with parameters picked at random.
+	SweepAlgorithmRandom SweepAlgorithm = "Random"
+
+	// SweepAlgorithmList runs a trial for each element of Trials.
+	SweepAlgorithmList SweepAlgorithm = "List"
+)
+
+// ParameterInjection is how the parameters of a trial are passed to its containers.
+type ParameterInjection string
+
+const (
+	// ParameterInjectionEnv sets an environment variable named after each parameter.
+	ParameterInjectionEnv ParameterInjection = "Env"
+
+	// ParameterInjectionArgs appends a --<name>=<value> argument for each parameter.
+	ParameterInjectionArgs ParameterInjection = "Args"
+)
+
+// SweepParameter is a parameter of a sweep.
+type SweepParameter struct {
+	// Name of the parameter, e.g. learning_rate.
+	Name string `json:"name"`
+
+	// Values of the parameter. Grid runs each of them, Random picks one.
+	Values []string `json:"values,omitempty"`
+
+	// Range of a numeric parameter, used if Values is empty.
+	Range *ParameterRange `json:"range,omitempty"`
+}
+
+// ParameterRange is the range of a numeric parameter.
+type ParameterRange struct {
+	// Min is the smallest value of the parameter.
+	Min float64 `json:"min"`
+
+	// Max is the largest value of the parameter.
+	Max float64 `json:"max"`
+
+	// Steps is the number of values run by Grid, evenly spaced from Min to
+	// Max included. It is required by Grid.
+	Steps int32 `json:"steps,omitempty"`
+
+	// Scale of the range. Defaults to Linear.
+	Scale ParameterScale `json:"scale,omitempty"`
+
+	// Integer rounds the values to integers.
+	Integer bool `json:"integer,omitempty"`
+}
+
+// ParameterScale is the scale of a parameter range.
+type ParameterScale string
+
+const (
+	// ParameterScaleLinear spaces the values evenly.
+	ParameterScaleLinear ParameterScale = "Linear"
+
+	// ParameterScaleLog spaces the logarithms of the values evenly, e.g. for a learning rate.
+	ParameterScaleLog ParameterScale = "Log"
+)
+
+// TFJobSweepStatus represents the current observed state of the TFJobSweep.
+type TFJobSweepStatus struct {
+	// Conditions is an array of current observed TFJobSweep conditions.
+	Conditions []common.JobCondition `json:"conditions"`
+
+	// Trials of the sweep, generated when it starts.
+	Trials []SweepTrialStatus `json:"trials"`
+
+	// The number of trials in each phase.
+	Pending   int32 `json:"pending,omitempty"`
+	Running   int32 `json:"running,omitempty"`
+	Succeeded int32 `json:"succeeded,omitempty"`
+	Failed    int32 `json:"failed,omitempty"`
+	Stopped   int32 `json:"stopped,omitempty"`
+
+	// Represents time when the TFJobSweep was acknowledged by the controller.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Represents time when the TFJobSweep was completed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// TrialPhase is the phase of a trial.
+type TrialPhase string
+
+const (
+	// TrialPending means the TFJob of the trial has not been created yet.
+	TrialPending TrialPhase = "Pending"
+
+	// TrialRunning means the TFJob of the trial has been created and has not finished.
+	TrialRunning TrialPhase = "Running"
+
+	// TrialSucceeded means the TFJob of the trial has succeeded.
+	TrialSucceeded TrialPhase = "Succeeded"
+
+	// TrialFailed means the TFJob of the trial has failed.
+	TrialFailed TrialPhase = "Failed"
+
+	// TrialStopped means the trial has been stopped, or never run, since the
+	// sweep reached its target.
+	TrialStopped TrialPhase = "Stopped"
+)
+
+// SweepTrialStatus represents the current observed state of a trial.
+type SweepTrialStatus struct {
+	// Index of the trial.
+	Index int32 `json:"index"`
+
+	// Parameters of the trial.
+	Parameters map[string]string `json:"parameters"`
+
+	// TFJobName is the name of the TFJob of the trial, empty until it is created.
+	TFJobName string `json:"tfJobName,omitempty"`
+
+	// Phase of the trial.
+	Phase TrialPhase `json:"phase"`
+
+	// Represents time when the trial completed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +resource:path=tfjobsweeps
+
+// TFJobSweepList is a list of TFJobSweeps.
+type TFJobSweepList struct {
+	// Standard type metadata.
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// List of TFJobSweeps.
+	Items []TFJobSweep `json:"items"`
+}
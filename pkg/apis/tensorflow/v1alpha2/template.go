@@ -0,0 +1,53 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// MergePodTemplate returns the template of a replica merged over the template
+// common to all the replicas of the tfjob. See TFJobSpec.Template for the merge semantics.
+func MergePodTemplate(common, replica *v1.PodTemplateSpec) (*v1.PodTemplateSpec, error) {
+	if common == nil {
+		return replica.DeepCopy(), nil
+	}
+	original, err := json.Marshal(common)
+	if err != nil {
+		return nil, err
+	}
+	if replica.Spec.Containers == nil {
+		// A null list would delete the containers of the common template.
+		replica = replica.DeepCopy()
+		replica.Spec.Containers = []v1.Container{}
+	}
+	patch, err := json.Marshal(replica)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, patch, v1.PodTemplateSpec{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge the pod template of the replica into the template of the tfjob: %v", err)
+	}
+	podTemplate := &v1.PodTemplateSpec{}
+	if err := json.Unmarshal(merged, podTemplate); err != nil {
+		return nil, err
+	}
+	return podTemplate, nil
+}
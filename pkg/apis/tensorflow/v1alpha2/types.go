@@ -16,7 +16,10 @@ package v1alpha2
 
 import (
 	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	common "github.com/kubeflow/tf-operator/pkg/apis/common/v1alpha2"
 )
 
 // +genclient
@@ -51,6 +54,129 @@ type TFJobSpec struct {
 	//     "Worker": TFReplicaSpec,
 	//   }
 	TFReplicaSpecs map[TFReplicaType]*TFReplicaSpec `json:"tfReplicaSpecs"`
+
+	// Template is merged under the template of every replica when its pods
+	// are created, so that the volumes, env, tolerations and so on shared by
+	// all the replicas are specified once. The merge is a strategic merge
+	// with the template of the replica as the patch, so its values win:
+	//   - containers, volumes, env and volume mounts are matched by name
+	//     and merged field by field;
+	//   - elements only in Template, e.g. a sidecar container, are added
+	//     to every replica;
+	//   - lists without a merge key, like tolerations, args or command, are
+	//     replaced by the ones of the replica if it sets them.
+	Template *v1.PodTemplateSpec `json:"template,omitempty"`
+
+	// SchedulingTimeoutSeconds is how long a pod of the TFJob may stay
+	// unschedulable before the scheduling is considered to have timed out.
+	// If unspecified, the TFJob waits for its pods to be scheduled forever.
+	SchedulingTimeoutSeconds *int64 `json:"schedulingTimeoutSeconds,omitempty"`
+
+	// FailOnSchedulingTimeout fails the TFJob and deletes all of its pods,
+	// releasing the resources they hold, once the scheduling has timed out.
+	// Otherwise the timeout is only reported in the Unschedulable condition.
+	FailOnSchedulingTimeout bool `json:"failOnSchedulingTimeout,omitempty"`
+
+	// TensorBoard, if specified, runs a TensorBoard for the TFJob.
+	// It is deleted together with the TFJob.
+	TensorBoard *TensorBoardSpec `json:"tensorboard,omitempty"`
+
+	// StartupPolicy, if specified, orders the startup of the replica types,
+	// e.g. so that the workers are only created once all the PS are running.
+	StartupPolicy *StartupPolicy `json:"startupPolicy,omitempty"`
+
+	// ClusterSpecDelivery is how the pods get the cluster spec of the TFJob.
+	// One of Env and ConfigMap. Defaults to Env.
+	ClusterSpecDelivery ClusterSpecDelivery `json:"clusterSpecDelivery,omitempty"`
+
+	// HostNetwork, if specified, runs the pods in the network namespace of
+	// their node, e.g. for RDMA. Each replica listens on a distinct port
+	// from the range of the policy, and the cluster spec has the IP
+	// addresses of the nodes instead of the DNS names of the services.
+	// It requires ClusterSpecDeliveryConfigMap, which is then the default,
+	// and does not support StartupPolicy.
+	HostNetwork *HostNetworkPolicy `json:"hostNetwork,omitempty"`
+
+	// NetworkIsolation, if specified, isolates the replicas of the TFJob
+	// with a NetworkPolicy owned by the TFJob. It does not apply to the
+	// pods in the host network.
+	NetworkIsolation *NetworkIsolationPolicy `json:"networkIsolation,omitempty"`
+
+	// UpdateStrategy is what happens to the pods of the TFJob when the
+	// template of a replica, the template of the TFJob or the cluster spec
+	// changes. One of Recreate and OnDelete. Defaults to Recreate.
+	UpdateStrategy UpdateStrategy `json:"updateStrategy,omitempty"`
+}
+
+// UpdateStrategy describes how the pods of a TFJob are updated.
+type UpdateStrategy string
+
+const (
+	// UpdateStrategyRecreate deletes all the pods of the TFJob as soon as
+	// one of them is outdated, so that they are all created again from the
+	// new templates. A TensorFlow cluster can not mix versions.
+	UpdateStrategyRecreate UpdateStrategy = "Recreate"
+
+	// UpdateStrategyOnDelete leaves the running pods untouched, the new
+	// templates are only used by the pods created once they are deleted.
+	UpdateStrategyOnDelete UpdateStrategy = "OnDelete"
+)
+
+// NetworkIsolationPolicy is a description of the pods allowed to connect to the replicas of a TFJob.
+type NetworkIsolationPolicy struct {
+	// AllowFrom are the peers allowed to connect to the ports of the
+	// replicas besides the pods of the TFJob, e.g. a monitoring system.
+	// The replicas accept no other connection, on any port.
+	AllowFrom []networkingv1.NetworkPolicyPeer `json:"allowFrom,omitempty"`
+}
+
+// TensorBoardSpec is a description of the TensorBoard of a TFJob.
+type TensorBoardSpec struct {
+	// LogDir is the directory TensorBoard reads the event files from.
+	// It usually is on one of the volumes, or on a remote storage like GCS.
+	LogDir string `json:"logDir"`
+
+	// Image of TensorBoard. Defaults to DefaultTensorBoardImage.
+	Image string `json:"image,omitempty"`
+
+	// Volumes of the TensorBoard pod.
+	Volumes []v1.Volume `json:"volumes,omitempty"`
+
+	// VolumeMounts of the TensorBoard container, referencing the Volumes.
+	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// SecondsAfterFinished is how long TensorBoard keeps running once the
+	// TFJob has succeeded or failed. If unspecified, it runs until the
+	// TFJob is deleted.
+	SecondsAfterFinished *int64 `json:"secondsAfterFinished,omitempty"`
+}
+
+// StartupPolicy is a description of the order the replicas of a TFJob start in.
+type StartupPolicy struct {
+	// Order is the list of the replica types in the order they start in.
+	// The pods of a replica type are created once all the pods of the
+	// replica types before it are running. The replica types not in the
+	// list start last. Defaults to [PS].
+	Order []TFReplicaType `json:"order,omitempty"`
+
+	// WaitForPeers injects an init container in the pods, which blocks
+	// until the addresses in the cluster spec of the replica types before
+	// theirs in Order resolve and accept connections on their port.
+	WaitForPeers bool `json:"waitForPeers,omitempty"`
+
+	// PeerWaitImage is the image of the init container, it must provide sh,
+	// nslookup and nc. Defaults to DefaultPeerWaitImage.
+	PeerWaitImage string `json:"peerWaitImage,omitempty"`
+}
+
+// HostNetworkPolicy is a description of the ports of a TFJob in the host network.
+type HostNetworkPolicy struct {
+	// MinPort and MaxPort bound the ports allocated to the replicas, one
+	// per replica. Two pods of different TFJobs with the same port are
+	// not scheduled on the same node. Default to DefaultHostNetworkMinPort
+	// and DefaultHostNetworkMaxPort.
+	MinPort int32 `json:"minPort,omitempty"`
+	MaxPort int32 `json:"maxPort,omitempty"`
 }
 
 // TFReplicaSpec is a description of the TFReplica
@@ -68,8 +194,99 @@ type TFReplicaSpec struct {
 	// One of Always, OnFailure, Never and ExitCode.
 	// Default to Never.
 	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+
+	// ExitCodeRules decide what happens when the tensorflow container of a
+	// pod fails, only used with the ExitCode restart policy.
+	// The rules are evaluated in order and the first matching rule applies.
+	// If no rule matches, DefaultExitCodeRules are evaluated.
+	ExitCodeRules []ExitCodeRule `json:"exitCodeRules,omitempty"`
+
+	// Placement, if specified, places the pods of the replica type on the
+	// nodes relative to the other pods of the TFJob. Its affinity terms
+	// are added to the affinity of the template.
+	Placement *Placement `json:"placement,omitempty"`
+}
+
+// Placement is a description of where the pods of a replica type are placed.
+type Placement struct {
+	// Policy is the named placement of the pods.
+	Policy PlacementPolicy `json:"policy"`
+
+	// ColocateWith is the replica type the pods are placed with, only
+	// used by PlacementPolicyColocate. Defaults to PS.
+	ColocateWith TFReplicaType `json:"colocateWith,omitempty"`
+
+	// TopologyKey is the label of the nodes defining the domains the pods
+	// are spread over or packed in. Defaults to DefaultPlacementTopologyKey.
+	TopologyKey string `json:"topologyKey,omitempty"`
+
+	// Required makes the placement a requirement of the scheduling instead
+	// of a preference. The pods which can not be placed stay pending. With
+	// PlacementPolicyColocate, the pods wait for a pod of ColocateWith.
+	Required bool `json:"required,omitempty"`
+}
+
+// PlacementPolicy names a placement of the pods of a replica type.
+type PlacementPolicy string
+
+const (
+	// PlacementPolicySpread places the pods of the replica type in
+	// distinct topology domains, e.g. one PS per node.
+	PlacementPolicySpread PlacementPolicy = "Spread"
+
+	// PlacementPolicyPack places the pods of the replica type in the
+	// topology domains which already have pods of the replica type, so
+	// that they are on as few nodes as possible.
+	PlacementPolicyPack PlacementPolicy = "Pack"
+
+	// PlacementPolicyColocate places each pod of the replica type in a
+	// topology domain with a pod of the ColocateWith replica type.
+	PlacementPolicyColocate PlacementPolicy = "Colocate"
+)
+
+// ExitCodeRule maps terminations of the tensorflow container to an action.
+// A rule matches if all of its matchers match, at least one must be set.
+type ExitCodeRule struct {
+	// ExitCodes matches the exit codes in this range.
+	ExitCodes *ExitCodeRange `json:"exitCodes,omitempty"`
+
+	// Signal matches a container killed by this signal, i.e.
+	// which exited with code 128+signal.
+	Signal *int32 `json:"signal,omitempty"`
+
+	// Reason matches the reason of the termination reported by
+	// the kubelet, e.g. OOMKilled.
+	Reason string `json:"reason,omitempty"`
+
+	// Action is taken when the rule matches.
+	Action ExitCodeAction `json:"action"`
 }
 
+// ExitCodeRange is an inclusive range of exit codes.
+type ExitCodeRange struct {
+	Min int32 `json:"min"`
+	Max int32 `json:"max"`
+}
+
+// ExitCodeAction describes what to do when the tensorflow container of a pod fails.
+type ExitCodeAction string
+
+const (
+	// ExitCodeActionRestartPod deletes the pod so that it is created again.
+	ExitCodeActionRestartPod ExitCodeAction = "RestartPod"
+
+	// ExitCodeActionRestartJob deletes all the pods of the TFJob so that
+	// the whole training starts again.
+	ExitCodeActionRestartJob ExitCodeAction = "RestartJob"
+
+	// ExitCodeActionFailJob keeps the pod failed, which fails the TFJob.
+	ExitCodeActionFailJob ExitCodeAction = "FailJob"
+
+	// ExitCodeActionIgnore keeps the pod but counts it as succeeded,
+	// so that the TFJob may still succeed.
+	ExitCodeActionIgnore ExitCodeAction = "Ignore"
+)
+
 // RestartPolicy describes how the TFReplicas should be restarted.
 // Only one of the following restart policies may be specified.
 // If none of the following policies is specified, the default one
@@ -82,13 +299,30 @@ const (
 	RestartPolicyNever     RestartPolicy = "Never"
 
 	// `ExitCode` policy means that user should add exit code by themselves,
-	// `tf-operator` will check these exit codes to
-	// determine the behavior when an error occurs:
-	// - 1-127: permanent error, do not restart.
-	// - 128-255: retryable error, will restart the pod.
+	// `tf-operator` will check these exit codes against the ExitCodeRules
+	// of the replica, then against DefaultExitCodeRules, to
+	// determine the behavior when an error occurs.
 	RestartPolicyExitCode RestartPolicy = "ExitCode"
 )
 
+// ClusterSpecDelivery describes how the pods of a TFJob get its cluster spec.
+type ClusterSpecDelivery string
+
+const (
+	// ClusterSpecDeliveryEnv sets the TF_CONFIG environment variable in
+	// all the containers of the pods.
+	ClusterSpecDeliveryEnv ClusterSpecDelivery = "Env"
+
+	// ClusterSpecDeliveryConfigMap writes the cluster once in a ConfigMap
+	// owned by the TFJob, which is mounted in the tensorflow container at
+	// ClusterSpecMountPath. The container learns its task from the
+	// TF_REPLICA_TYPE and TF_REPLICA_INDEX environment variables, set
+	// from the labels of the pod. The command of the container is wrapped
+	// in a sh shim exporting TF_CONFIG, so the tensorflow container must
+	// set its command rather than rely on the entrypoint of its image.
+	ClusterSpecDeliveryConfigMap ClusterSpecDelivery = "ConfigMap"
+)
+
 // TFReplicaType is the type for TFReplica.
 type TFReplicaType string
 
@@ -112,7 +346,7 @@ const (
 // TFJobStatus represents the current observed state of the TFJob.
 type TFJobStatus struct {
 	// Conditions is an array of current observed TFJob conditions.
-	Conditions []TFJobCondition `json:"conditions"`
+	Conditions []common.JobCondition `json:"conditions"`
 
 	// TFReplicaStatuses is map of TFReplicaType and TFReplicaStatus,
 	// specifies the status of each TFReplica.
@@ -132,10 +366,17 @@ type TFJobStatus struct {
 	// be set in happens-before order across separate operations.
 	// It is represented in RFC3339 form and is in UTC.
 	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// TensorBoardURL is the in-cluster URL of the TensorBoard of the TFJob,
+	// empty if it has none or if it has been deleted after the TFJob finished.
+	TensorBoardURL string `json:"tensorBoardURL,omitempty"`
 }
 
 // TFReplicaStatus represents the current observed state of the TFReplica.
 type TFReplicaStatus struct {
+	// The number of pods which are not running yet.
+	Pending int32 `json:"pending,omitempty"`
+
 	// The number of actively running pods.
 	Active int32 `json:"active,omitempty"`
 
@@ -144,53 +385,101 @@ type TFReplicaStatus struct {
 
 	// The number of pods which reached phase Failed.
 	Failed int32 `json:"failed,omitempty"`
+
+	// The number of pods recreated because they were lost to an
+	// infrastructure failure, e.g. an eviction or the failure of their node.
+	// These pods are not counted as failed.
+	InfrastructureRestarts int32 `json:"infrastructureRestarts,omitempty"`
+
+	// Replicas is the status of the pod of each index, sorted by index.
+	// Indexes without a pod are omitted.
+	Replicas []TFReplicaIndexStatus `json:"replicas,omitempty"`
 }
 
-// TFJobCondition describes the state of the TFJob at a certain point.
-type TFJobCondition struct {
-	// Type of TFJob condition.
-	Type TFJobConditionType `json:"type"`
-	// Status of the condition, one of True, False, Unknown.
-	Status v1.ConditionStatus `json:"status"`
-	// The reason for the condition's last transition.
-	Reason string `json:"reason,omitempty"`
-	// A human readable message indicating details about the transition.
-	Message string `json:"message,omitempty"`
-	// The last time this condition was updated.
-	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
-	// Last time the condition transitioned from one status to another.
-	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+// TFReplicaIndexStatus represents the observed state of the pod of a TFReplica index.
+type TFReplicaIndexStatus struct {
+	// Index of the replica.
+	Index int32 `json:"index"`
+
+	// Attempt of the pod of the replica, the number of pods created for
+	// the index before it. The name of the pod ends with its attempt.
+	Attempt int32 `json:"attempt"`
+
+	// PodName is the name of the pod of the replica.
+	PodName string `json:"podName"`
+
+	// NodeName is the node the pod is scheduled on.
+	NodeName string `json:"nodeName,omitempty"`
+
+	// PodIP is the IP address of the pod.
+	PodIP string `json:"podIP,omitempty"`
+
+	// Phase of the pod.
+	Phase v1.PodPhase `json:"phase,omitempty"`
+
+	// RestartCount is the number of times the tensorflow container
+	// has been restarted by the kubelet.
+	RestartCount int32 `json:"restartCount,omitempty"`
+
+	// LastExitCode is the exit code of the last termination of
+	// the tensorflow container.
+	LastExitCode *int32 `json:"lastExitCode,omitempty"`
+
+	// LastTerminationReason is the reason of the last termination of
+	// the tensorflow container, e.g. OOMKilled.
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+
+	// StartTime is the time the pod was acknowledged by the kubelet.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
 }
 
+// TFJobCondition describes the state of the TFJob at a certain point.
+type TFJobCondition = common.JobCondition
+
 // TFJobConditionType defines all kinds of types of TFJobStatus.
-type TFJobConditionType string
+type TFJobConditionType = common.JobConditionType
 
 const (
 	// TFJobCreated means the tfjob has been accepted by the system,
 	// but one or more of the pods/services has not been started.
 	// This includes time before pods being scheduled and launched.
-	TFJobCreated TFJobConditionType = "Created"
+	TFJobCreated = common.JobCreated
 
 	// TFJobRunning means all sub-resources (e.g. services/pods) of this TFJob
 	// have been successfully scheduled and launched.
 	// The training is running without error.
-	TFJobRunning TFJobConditionType = "Running"
+	TFJobRunning = common.JobRunning
 
 	// TFJobRestarting means one or more sub-resources (e.g. services/pods) of this TFJob
 	// reached phase failed but maybe restarted according to it's restart policy
 	// which specified by user in v1.PodTemplateSpec.
 	// The training is freezing/pending.
-	TFJobRestarting TFJobConditionType = "Restarting"
+	TFJobRestarting = common.JobRestarting
 
 	// TFJobSucceeded means all sub-resources (e.g. services/pods) of this TFJob
 	// reached phase have terminated in success.
 	// The training is complete without error.
-	TFJobSucceeded TFJobConditionType = "Succeeded"
+	TFJobSucceeded = common.JobSucceeded
 
 	// TFJobFailed means one or more sub-resources (e.g. services/pods) of this TFJob
 	// reached phase failed with no restarting.
 	// The training has failed its execution.
-	TFJobFailed TFJobConditionType = "Failed"
+	TFJobFailed = common.JobFailed
+
+	// TFJobUnschedulable means one or more pods of this TFJob can not be
+	// scheduled. The message of the condition is the one of the scheduler.
+	TFJobUnschedulable TFJobConditionType = "Unschedulable"
+
+	// TFJobStalled means one or more pods of this TFJob do not run because
+	// of a problem which does not fail them, e.g. an image which can not be
+	// pulled, a crash loop or an exceeded quota. The reason of the condition
+	// is the one of the problem. It is removed once the problem is solved.
+	TFJobStalled TFJobConditionType = "Stalled"
+
+	// TFJobUpdating means one or more pods of this TFJob have been created
+	// from a template which has changed since. It is removed once all the
+	// pods are up to date.
+	TFJobUpdating TFJobConditionType = "Updating"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
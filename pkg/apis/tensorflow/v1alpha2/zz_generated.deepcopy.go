@@ -19,11 +19,14 @@
 package v1alpha2
 
 import (
+	common_v1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/common/v1alpha2"
+	core_v1 "k8s.io/api/core/v1"
+	networking_v1 "k8s.io/api/networking/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TFJob) DeepCopyInto(out *TFJob) {
+func (in *CronTFJob) DeepCopyInto(out *CronTFJob) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -32,18 +35,51 @@ func (in *TFJob) DeepCopyInto(out *TFJob) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFJob.
-func (in *TFJob) DeepCopy() *TFJob {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronTFJob.
+func (in *CronTFJob) DeepCopy() *CronTFJob {
 	if in == nil {
 		return nil
 	}
-	out := new(TFJob)
+	out := new(CronTFJob)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TFJob) DeepCopyObject() runtime.Object {
+func (in *CronTFJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronTFJobList) DeepCopyInto(out *CronTFJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CronTFJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronTFJobList.
+func (in *CronTFJobList) DeepCopy() *CronTFJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(CronTFJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CronTFJobList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -51,23 +87,318 @@ func (in *TFJob) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TFJobCondition) DeepCopyInto(out *TFJobCondition) {
+func (in *CronTFJobSpec) DeepCopyInto(out *CronTFJobSpec) {
+	*out = *in
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	in.JobTemplate.DeepCopyInto(&out.JobTemplate)
+	if in.SuccessfulJobsHistoryLimit != nil {
+		in, out := &in.SuccessfulJobsHistoryLimit, &out.SuccessfulJobsHistoryLimit
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.FailedJobsHistoryLimit != nil {
+		in, out := &in.FailedJobsHistoryLimit, &out.FailedJobsHistoryLimit
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronTFJobSpec.
+func (in *CronTFJobSpec) DeepCopy() *CronTFJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CronTFJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronTFJobStatus) DeepCopyInto(out *CronTFJobStatus) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]core_v1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronTFJobStatus.
+func (in *CronTFJobStatus) DeepCopy() *CronTFJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CronTFJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExitCodeRange) DeepCopyInto(out *ExitCodeRange) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExitCodeRange.
+func (in *ExitCodeRange) DeepCopy() *ExitCodeRange {
+	if in == nil {
+		return nil
+	}
+	out := new(ExitCodeRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExitCodeRule) DeepCopyInto(out *ExitCodeRule) {
+	*out = *in
+	if in.ExitCodes != nil {
+		in, out := &in.ExitCodes, &out.ExitCodes
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ExitCodeRange)
+			**out = **in
+		}
+	}
+	if in.Signal != nil {
+		in, out := &in.Signal, &out.Signal
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExitCodeRule.
+func (in *ExitCodeRule) DeepCopy() *ExitCodeRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ExitCodeRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostNetworkPolicy) DeepCopyInto(out *HostNetworkPolicy) {
 	*out = *in
-	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFJobCondition.
-func (in *TFJobCondition) DeepCopy() *TFJobCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostNetworkPolicy.
+func (in *HostNetworkPolicy) DeepCopy() *HostNetworkPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(TFJobCondition)
+	out := new(HostNetworkPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkIsolationPolicy) DeepCopyInto(out *NetworkIsolationPolicy) {
+	*out = *in
+	if in.AllowFrom != nil {
+		in, out := &in.AllowFrom, &out.AllowFrom
+		*out = make([]networking_v1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkIsolationPolicy.
+func (in *NetworkIsolationPolicy) DeepCopy() *NetworkIsolationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkIsolationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterRange) DeepCopyInto(out *ParameterRange) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterRange.
+func (in *ParameterRange) DeepCopy() *ParameterRange {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Placement) DeepCopyInto(out *Placement) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Placement.
+func (in *Placement) DeepCopy() *Placement {
+	if in == nil {
+		return nil
+	}
+	out := new(Placement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupPolicy) DeepCopyInto(out *StartupPolicy) {
+	*out = *in
+	if in.Order != nil {
+		in, out := &in.Order, &out.Order
+		*out = make([]TFReplicaType, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupPolicy.
+func (in *StartupPolicy) DeepCopy() *StartupPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepParameter) DeepCopyInto(out *SweepParameter) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Range != nil {
+		in, out := &in.Range, &out.Range
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ParameterRange)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepParameter.
+func (in *SweepParameter) DeepCopy() *SweepParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepTrialStatus) DeepCopyInto(out *SweepTrialStatus) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepTrialStatus.
+func (in *SweepTrialStatus) DeepCopy() *SweepTrialStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepTrialStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TFJob) DeepCopyInto(out *TFJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFJob.
+func (in *TFJob) DeepCopy() *TFJob {
+	if in == nil {
+		return nil
+	}
+	out := new(TFJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TFJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TFJobList) DeepCopyInto(out *TFJobList) {
 	*out = *in
@@ -116,6 +447,60 @@ func (in *TFJobSpec) DeepCopyInto(out *TFJobSpec) {
 			}
 		}
 	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.PodTemplateSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.SchedulingTimeoutSeconds != nil {
+		in, out := &in.SchedulingTimeoutSeconds, &out.SchedulingTimeoutSeconds
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.TensorBoard != nil {
+		in, out := &in.TensorBoard, &out.TensorBoard
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(TensorBoardSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.StartupPolicy != nil {
+		in, out := &in.StartupPolicy, &out.StartupPolicy
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StartupPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.HostNetwork != nil {
+		in, out := &in.HostNetwork, &out.HostNetwork
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(HostNetworkPolicy)
+			**out = **in
+		}
+	}
+	if in.NetworkIsolation != nil {
+		in, out := &in.NetworkIsolation, &out.NetworkIsolation
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetworkIsolationPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -134,7 +519,7 @@ func (in *TFJobStatus) DeepCopyInto(out *TFJobStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]TFJobCondition, len(*in))
+		*out = make([]common_v1alpha2.JobCondition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -188,6 +573,239 @@ func (in *TFJobStatus) DeepCopy() *TFJobStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TFJobSweep) DeepCopyInto(out *TFJobSweep) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFJobSweep.
+func (in *TFJobSweep) DeepCopy() *TFJobSweep {
+	if in == nil {
+		return nil
+	}
+	out := new(TFJobSweep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TFJobSweep) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TFJobSweepList) DeepCopyInto(out *TFJobSweepList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TFJobSweep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFJobSweepList.
+func (in *TFJobSweepList) DeepCopy() *TFJobSweepList {
+	if in == nil {
+		return nil
+	}
+	out := new(TFJobSweepList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TFJobSweepList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TFJobSweepSpec) DeepCopyInto(out *TFJobSweepSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]SweepParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Trials != nil {
+		in, out := &in.Trials, &out.Trials
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] == nil {
+				(*out)[i] = nil
+			} else {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+	if in.MaxTrials != nil {
+		in, out := &in.MaxTrials, &out.MaxTrials
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.Seed != nil {
+		in, out := &in.Seed, &out.Seed
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.TargetSucceededTrials != nil {
+		in, out := &in.TargetSucceededTrials, &out.TargetSucceededTrials
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFJobSweepSpec.
+func (in *TFJobSweepSpec) DeepCopy() *TFJobSweepSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TFJobSweepSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TFJobSweepStatus) DeepCopyInto(out *TFJobSweepStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]common_v1alpha2.JobCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Trials != nil {
+		in, out := &in.Trials, &out.Trials
+		*out = make([]SweepTrialStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFJobSweepStatus.
+func (in *TFJobSweepStatus) DeepCopy() *TFJobSweepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TFJobSweepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TFJobTemplateSpec) DeepCopyInto(out *TFJobTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFJobTemplateSpec.
+func (in *TFJobTemplateSpec) DeepCopy() *TFJobTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TFJobTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TFReplicaIndexStatus) DeepCopyInto(out *TFReplicaIndexStatus) {
+	*out = *in
+	if in.LastExitCode != nil {
+		in, out := &in.LastExitCode, &out.LastExitCode
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int32)
+			**out = **in
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = (*in).DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFReplicaIndexStatus.
+func (in *TFReplicaIndexStatus) DeepCopy() *TFReplicaIndexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TFReplicaIndexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TFReplicaSpec) DeepCopyInto(out *TFReplicaSpec) {
 	*out = *in
@@ -201,6 +819,22 @@ func (in *TFReplicaSpec) DeepCopyInto(out *TFReplicaSpec) {
 		}
 	}
 	in.Template.DeepCopyInto(&out.Template)
+	if in.ExitCodeRules != nil {
+		in, out := &in.ExitCodeRules, &out.ExitCodeRules
+		*out = make([]ExitCodeRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(Placement)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -217,6 +851,13 @@ func (in *TFReplicaSpec) DeepCopy() *TFReplicaSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TFReplicaStatus) DeepCopyInto(out *TFReplicaStatus) {
 	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]TFReplicaIndexStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -229,3 +870,42 @@ func (in *TFReplicaStatus) DeepCopy() *TFReplicaStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TensorBoardSpec) DeepCopyInto(out *TensorBoardSpec) {
+	*out = *in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]core_v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]core_v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecondsAfterFinished != nil {
+		in, out := &in.SecondsAfterFinished, &out.SecondsAfterFinished
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(int64)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TensorBoardSpec.
+func (in *TensorBoardSpec) DeepCopy() *TensorBoardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TensorBoardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -26,11 +26,26 @@ import (
 // Public to allow building arbitrary schemes.
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&CronTFJob{}, func(obj interface{}) { SetObjectDefaults_CronTFJob(obj.(*CronTFJob)) })
+	scheme.AddTypeDefaultingFunc(&CronTFJobList{}, func(obj interface{}) { SetObjectDefaults_CronTFJobList(obj.(*CronTFJobList)) })
 	scheme.AddTypeDefaultingFunc(&TFJob{}, func(obj interface{}) { SetObjectDefaults_TFJob(obj.(*TFJob)) })
 	scheme.AddTypeDefaultingFunc(&TFJobList{}, func(obj interface{}) { SetObjectDefaults_TFJobList(obj.(*TFJobList)) })
+	scheme.AddTypeDefaultingFunc(&TFJobSweep{}, func(obj interface{}) { SetObjectDefaults_TFJobSweep(obj.(*TFJobSweep)) })
+	scheme.AddTypeDefaultingFunc(&TFJobSweepList{}, func(obj interface{}) { SetObjectDefaults_TFJobSweepList(obj.(*TFJobSweepList)) })
 	return nil
 }
 
+func SetObjectDefaults_CronTFJob(in *CronTFJob) {
+	SetDefaults_CronTFJob(in)
+}
+
+func SetObjectDefaults_CronTFJobList(in *CronTFJobList) {
+	for i := range in.Items {
+		a := &in.Items[i]
+		SetObjectDefaults_CronTFJob(a)
+	}
+}
+
 func SetObjectDefaults_TFJob(in *TFJob) {
 	SetDefaults_TFJob(in)
 }
@@ -41,3 +56,14 @@ func SetObjectDefaults_TFJobList(in *TFJobList) {
 		SetObjectDefaults_TFJob(a)
 	}
 }
+
+func SetObjectDefaults_TFJobSweep(in *TFJobSweep) {
+	SetDefaults_TFJobSweep(in)
+}
+
+func SetObjectDefaults_TFJobSweepList(in *TFJobSweepList) {
+	for i := range in.Items {
+		a := &in.Items[i]
+		SetObjectDefaults_TFJobSweep(a)
+	}
+}
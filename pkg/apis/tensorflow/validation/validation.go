@@ -19,7 +19,9 @@ import (
 	"fmt"
 
 	tfv1 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha1"
+	tfv2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
 	"github.com/kubeflow/tf-operator/pkg/util"
+	"github.com/kubeflow/tf-operator/pkg/util/cron"
 )
 
 // ValidateTFJobSpec checks that the TFJobSpec is valid.
@@ -77,3 +79,335 @@ func ValidateTFJobSpec(c *tfv1.TFJobSpec) error {
 
 	return nil
 }
+
+// ValidateV1Alpha2TFJobSpec checks that the v1alpha2.TFJobSpec is valid.
+// It is expected to be called on a TFJob that has been defaulted.
+func ValidateV1Alpha2TFJobSpec(c *tfv2.TFJobSpec) error {
+	if len(c.TFReplicaSpecs) == 0 {
+		return errors.New("tfReplicaSpecs can't be empty")
+	}
+
+	if c.SchedulingTimeoutSeconds != nil && *c.SchedulingTimeoutSeconds <= 0 {
+		return fmt.Errorf("schedulingTimeoutSeconds must be positive, got %d", *c.SchedulingTimeoutSeconds)
+	}
+	if c.FailOnSchedulingTimeout && c.SchedulingTimeoutSeconds == nil {
+		return errors.New("failOnSchedulingTimeout requires schedulingTimeoutSeconds")
+	}
+	if c.TensorBoard != nil {
+		if c.TensorBoard.LogDir == "" {
+			return errors.New("tensorboard.logDir must be specified")
+		}
+		if c.TensorBoard.SecondsAfterFinished != nil && *c.TensorBoard.SecondsAfterFinished < 0 {
+			return fmt.Errorf("tensorboard.secondsAfterFinished must not be negative, got %d", *c.TensorBoard.SecondsAfterFinished)
+		}
+	}
+
+	switch c.ClusterSpecDelivery {
+	case "", tfv2.ClusterSpecDeliveryEnv, tfv2.ClusterSpecDeliveryConfigMap:
+	default:
+		return fmt.Errorf("clusterSpecDelivery must be %s or %s, got %s", tfv2.ClusterSpecDeliveryEnv, tfv2.ClusterSpecDeliveryConfigMap, c.ClusterSpecDelivery)
+	}
+
+	switch c.UpdateStrategy {
+	case "", tfv2.UpdateStrategyRecreate, tfv2.UpdateStrategyOnDelete:
+	default:
+		return fmt.Errorf("updateStrategy must be %s or %s, got %s", tfv2.UpdateStrategyRecreate, tfv2.UpdateStrategyOnDelete, c.UpdateStrategy)
+	}
+
+	if c.HostNetwork != nil {
+		if err := validateHostNetwork(c); err != nil {
+			return err
+		}
+	}
+
+	if c.NetworkIsolation != nil {
+		if c.HostNetwork != nil {
+			return errors.New("networkIsolation does not apply to hostNetwork")
+		}
+		for i, peer := range c.NetworkIsolation.AllowFrom {
+			if peer.PodSelector == nil && peer.NamespaceSelector == nil && peer.IPBlock == nil {
+				return fmt.Errorf("networkIsolation.allowFrom[%d] must have one of podSelector, namespaceSelector and ipBlock", i)
+			}
+		}
+	}
+
+	validReplicaTypes := []tfv2.TFReplicaType{tfv2.TFReplicaTypePS, tfv2.TFReplicaTypeWorker, tfv2.TFReplicaTypeChief, tfv2.TFReplicaTypeEval}
+
+	if c.StartupPolicy != nil {
+		seen := make(map[tfv2.TFReplicaType]bool)
+		for _, rtype := range c.StartupPolicy.Order {
+			isValidReplicaType := false
+			for _, t := range validReplicaTypes {
+				if t == rtype {
+					isValidReplicaType = true
+					break
+				}
+			}
+			if !isValidReplicaType {
+				return fmt.Errorf("startupPolicy.order has replica type %v but must only have %v", rtype, validReplicaTypes)
+			}
+			if seen[rtype] {
+				return fmt.Errorf("startupPolicy.order has replica type %v more than once", rtype)
+			}
+			seen[rtype] = true
+		}
+	}
+
+	for rtype, spec := range c.TFReplicaSpecs {
+		isValidReplicaType := false
+		for _, t := range validReplicaTypes {
+			if t == rtype {
+				isValidReplicaType = true
+				break
+			}
+		}
+		if !isValidReplicaType {
+			return fmt.Errorf("tfReplicaType is %v but must be one of %v", rtype, validReplicaTypes)
+		}
+
+		if spec == nil {
+			return fmt.Errorf("tfReplicaSpec for %v can't be nil", rtype)
+		}
+
+		if spec.Replicas != nil && *spec.Replicas < 0 {
+			return fmt.Errorf("tfReplicaSpec for %v has negative replicas %d", rtype, *spec.Replicas)
+		}
+		if rtype == tfv2.TFReplicaTypeChief && spec.Replicas != nil && *spec.Replicas > 1 {
+			return fmt.Errorf("tfReplicaSpec for %v can't have more than 1 replica", rtype)
+		}
+
+		switch spec.RestartPolicy {
+		case "", tfv2.RestartPolicyAlways, tfv2.RestartPolicyOnFailure, tfv2.RestartPolicyNever, tfv2.RestartPolicyExitCode:
+		default:
+			return fmt.Errorf("tfReplicaSpec for %v has invalid restart policy %v", rtype, spec.RestartPolicy)
+		}
+
+		if len(spec.ExitCodeRules) != 0 && spec.RestartPolicy != tfv2.RestartPolicyExitCode {
+			return fmt.Errorf("tfReplicaSpec for %v has exit code rules but its restart policy is not %v", rtype, tfv2.RestartPolicyExitCode)
+		}
+		for i, rule := range spec.ExitCodeRules {
+			if err := validateExitCodeRule(&rule); err != nil {
+				return fmt.Errorf("exit code rule %d of replica type %v is invalid: %v", i, rtype, err)
+			}
+		}
+
+		if spec.Placement != nil {
+			if err := validatePlacement(c, rtype, spec.Placement); err != nil {
+				return fmt.Errorf("placement of replica type %v is invalid: %v", rtype, err)
+			}
+		}
+
+		// The containers and images may come from the template of the tfjob.
+		template, err := tfv2.MergePodTemplate(c.Template, &spec.Template)
+		if err != nil {
+			return fmt.Errorf("template of replica type %v is invalid: %v", rtype, err)
+		}
+		found := false
+		for _, container := range template.Spec.Containers {
+			if container.Image == "" {
+				return fmt.Errorf("container %s of replica type %v has no image", container.Name, rtype)
+			}
+			if container.Name == tfv2.DefaultContainerName {
+				found = true
+				if c.ClusterSpecDelivery == tfv2.ClusterSpecDeliveryConfigMap && len(container.Command) == 0 {
+					return fmt.Errorf("container %s of replica type %v must set its command with clusterSpecDelivery %s, it is wrapped to export TF_CONFIG",
+						container.Name, rtype, tfv2.ClusterSpecDeliveryConfigMap)
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("Replica type %v is missing a container named %s", rtype, tfv2.DefaultContainerName)
+		}
+	}
+
+	return nil
+}
+
+// validateHostNetwork checks that the port range of the host network policy
+// has a port for each replica.
+func validateHostNetwork(c *tfv2.TFJobSpec) error {
+	policy := c.HostNetwork
+	if policy.MinPort <= 0 || policy.MaxPort > 65535 || policy.MinPort > policy.MaxPort {
+		return fmt.Errorf("hostNetwork ports must be a range within 1-65535, got %d-%d", policy.MinPort, policy.MaxPort)
+	}
+	var replicas int32
+	for _, spec := range c.TFReplicaSpecs {
+		if spec.Replicas != nil {
+			replicas += *spec.Replicas
+		}
+	}
+	if replicas > policy.MaxPort-policy.MinPort+1 {
+		return fmt.Errorf("hostNetwork ports %d-%d are fewer than the %d replicas", policy.MinPort, policy.MaxPort, replicas)
+	}
+	if c.ClusterSpecDelivery != tfv2.ClusterSpecDeliveryConfigMap {
+		return fmt.Errorf("hostNetwork requires clusterSpecDelivery %s", tfv2.ClusterSpecDeliveryConfigMap)
+	}
+	if c.StartupPolicy != nil {
+		return errors.New("hostNetwork does not support startupPolicy")
+	}
+	return nil
+}
+
+// validatePlacement checks that the placement of the replica type has a known
+// policy, and that the replica type it is colocated with is another one of the tfjob.
+func validatePlacement(c *tfv2.TFJobSpec, rtype tfv2.TFReplicaType, placement *tfv2.Placement) error {
+	if placement.TopologyKey == "" {
+		return errors.New("topologyKey must be specified")
+	}
+	switch placement.Policy {
+	case tfv2.PlacementPolicySpread, tfv2.PlacementPolicyPack:
+		if placement.ColocateWith != "" {
+			return fmt.Errorf("colocateWith is only used by policy %v", tfv2.PlacementPolicyColocate)
+		}
+	case tfv2.PlacementPolicyColocate:
+		if placement.ColocateWith == rtype {
+			return fmt.Errorf("can't colocate replica type %v with itself", rtype)
+		}
+		if _, ok := c.TFReplicaSpecs[placement.ColocateWith]; !ok {
+			return fmt.Errorf("colocateWith is %v but the tfjob has no such replica type", placement.ColocateWith)
+		}
+	default:
+		return fmt.Errorf("unknown policy %q", placement.Policy)
+	}
+	return nil
+}
+
+func validateExitCodeRule(rule *tfv2.ExitCodeRule) error {
+	switch rule.Action {
+	case tfv2.ExitCodeActionRestartPod, tfv2.ExitCodeActionRestartJob, tfv2.ExitCodeActionFailJob, tfv2.ExitCodeActionIgnore:
+	default:
+		return fmt.Errorf("unknown action %q", rule.Action)
+	}
+	if rule.ExitCodes == nil && rule.Signal == nil && rule.Reason == "" {
+		return errors.New("one of exitCodes, signal or reason must be set")
+	}
+	if rule.ExitCodes != nil && rule.ExitCodes.Min > rule.ExitCodes.Max {
+		return fmt.Errorf("exit code range %d-%d is empty", rule.ExitCodes.Min, rule.ExitCodes.Max)
+	}
+	if rule.Signal != nil && (*rule.Signal <= 0 || *rule.Signal > 127) {
+		return fmt.Errorf("signal %d is out of range", *rule.Signal)
+	}
+	return nil
+}
+
+// ValidateTFJobSweepSpec checks that the TFJobSweepSpec is valid.
+// It is expected to be called on a TFJobSweep that has been defaulted.
+func ValidateTFJobSweepSpec(c *tfv2.TFJobSweepSpec) error {
+	if err := ValidateV1Alpha2TFJobSpec(&c.Template.Spec); err != nil {
+		return fmt.Errorf("invalid template: %v", err)
+	}
+
+	if c.MaxTrials != nil && *c.MaxTrials <= 0 {
+		return fmt.Errorf("maxTrials must be positive, got %d", *c.MaxTrials)
+	}
+	if c.Parallelism == nil || *c.Parallelism <= 0 {
+		return errors.New("parallelism must be positive")
+	}
+	if c.TargetSucceededTrials != nil && *c.TargetSucceededTrials <= 0 {
+		return fmt.Errorf("targetSucceededTrials must be positive, got %d", *c.TargetSucceededTrials)
+	}
+	switch c.ParameterInjection {
+	case tfv2.ParameterInjectionEnv, tfv2.ParameterInjectionArgs:
+	default:
+		return fmt.Errorf("unknown parameterInjection %q", c.ParameterInjection)
+	}
+
+	switch c.Algorithm {
+	case tfv2.SweepAlgorithmGrid, tfv2.SweepAlgorithmRandom:
+		if len(c.Parameters) == 0 {
+			return fmt.Errorf("algorithm %v requires parameters", c.Algorithm)
+		}
+		if len(c.Trials) != 0 {
+			return fmt.Errorf("trials are only used by algorithm %v", tfv2.SweepAlgorithmList)
+		}
+		if c.Algorithm == tfv2.SweepAlgorithmRandom && c.MaxTrials == nil {
+			return fmt.Errorf("algorithm %v requires maxTrials", c.Algorithm)
+		}
+	case tfv2.SweepAlgorithmList:
+		if len(c.Trials) == 0 {
+			return fmt.Errorf("algorithm %v requires trials", c.Algorithm)
+		}
+		if len(c.Parameters) != 0 {
+			return fmt.Errorf("parameters are not used by algorithm %v", c.Algorithm)
+		}
+	default:
+		return fmt.Errorf("unknown algorithm %q", c.Algorithm)
+	}
+
+	names := map[string]bool{}
+	for _, p := range c.Parameters {
+		if p.Name == "" {
+			return errors.New("parameters must have a name")
+		}
+		if names[p.Name] {
+			return fmt.Errorf("parameter %s is duplicated", p.Name)
+		}
+		names[p.Name] = true
+		if err := validateSweepParameter(&p, c.Algorithm); err != nil {
+			return fmt.Errorf("parameter %s is invalid: %v", p.Name, err)
+		}
+	}
+	for i, trial := range c.Trials {
+		for name := range trial {
+			if name == "" {
+				return fmt.Errorf("trial %d has a parameter without name", i)
+			}
+		}
+	}
+	return nil
+}
+
+func validateSweepParameter(p *tfv2.SweepParameter, algorithm tfv2.SweepAlgorithm) error {
+	if (len(p.Values) == 0) == (p.Range == nil) {
+		return errors.New("exactly one of values and range must be set")
+	}
+	r := p.Range
+	if r == nil {
+		return nil
+	}
+	if r.Min > r.Max {
+		return fmt.Errorf("range %v-%v is empty", r.Min, r.Max)
+	}
+	switch r.Scale {
+	case tfv2.ParameterScaleLinear:
+	case tfv2.ParameterScaleLog:
+		if r.Min <= 0 {
+			return fmt.Errorf("scale %v requires a positive min, got %v", r.Scale, r.Min)
+		}
+	default:
+		return fmt.Errorf("unknown scale %q", r.Scale)
+	}
+	if r.Steps < 0 {
+		return fmt.Errorf("steps must not be negative, got %d", r.Steps)
+	}
+	if algorithm == tfv2.SweepAlgorithmGrid && r.Steps == 0 {
+		return fmt.Errorf("algorithm %v requires the steps of a range", algorithm)
+	}
+	return nil
+}
+
+// ValidateCronTFJobSpec checks that the CronTFJobSpec is valid.
+// It is expected to be called on a CronTFJob that has been defaulted.
+func ValidateCronTFJobSpec(c *tfv2.CronTFJobSpec) error {
+	if err := ValidateV1Alpha2TFJobSpec(&c.JobTemplate.Spec); err != nil {
+		return fmt.Errorf("invalid jobTemplate: %v", err)
+	}
+	if _, err := cron.Parse(c.Schedule); err != nil {
+		return err
+	}
+	if c.StartingDeadlineSeconds != nil && *c.StartingDeadlineSeconds < 0 {
+		return fmt.Errorf("startingDeadlineSeconds must not be negative, got %d", *c.StartingDeadlineSeconds)
+	}
+	switch c.ConcurrencyPolicy {
+	case tfv2.ConcurrencyPolicyAllow, tfv2.ConcurrencyPolicyForbid, tfv2.ConcurrencyPolicyReplace:
+	default:
+		return fmt.Errorf("unknown concurrencyPolicy %q", c.ConcurrencyPolicy)
+	}
+	if c.SuccessfulJobsHistoryLimit != nil && *c.SuccessfulJobsHistoryLimit < 0 {
+		return fmt.Errorf("successfulJobsHistoryLimit must not be negative, got %d", *c.SuccessfulJobsHistoryLimit)
+	}
+	if c.FailedJobsHistoryLimit != nil && *c.FailedJobsHistoryLimit < 0 {
+		return fmt.Errorf("failedJobsHistoryLimit must not be negative, got %d", *c.FailedJobsHistoryLimit)
+	}
+	return nil
+}
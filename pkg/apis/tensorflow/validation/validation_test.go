@@ -18,9 +18,12 @@ import (
 	"testing"
 
 	tfv1 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha1"
+	tfv2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
 
 	"github.com/gogo/protobuf/proto"
 	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestValidate(t *testing.T) {
@@ -111,3 +114,449 @@ func TestValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateV1Alpha2TFJobSpec(t *testing.T) {
+	newSpec := func(rtype tfv2.TFReplicaType, replicas int32, containers ...v1.Container) *tfv2.TFJobSpec {
+		return &tfv2.TFJobSpec{
+			TFReplicaSpecs: map[tfv2.TFReplicaType]*tfv2.TFReplicaSpec{
+				rtype: {
+					Replicas: tfv2.Int32(replicas),
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: containers,
+						},
+					},
+				},
+			},
+		}
+	}
+	tfContainer := v1.Container{Name: tfv2.DefaultContainerName, Image: "tensorflow/tensorflow:1.8.0", Command: []string{"python", "train.py"}}
+	entrypointContainer := v1.Container{Name: tfv2.DefaultContainerName, Image: "tensorflow/tensorflow:1.8.0"}
+
+	testCases := map[string]struct {
+		in             *tfv2.TFJobSpec
+		expectingError bool
+	}{
+		"valid worker": {
+			in: newSpec(tfv2.TFReplicaTypeWorker, 2, tfContainer),
+		},
+		"no replica specs": {
+			in:             &tfv2.TFJobSpec{},
+			expectingError: true,
+		},
+		"no containers": {
+			in:             newSpec(tfv2.TFReplicaTypeWorker, 1),
+			expectingError: true,
+		},
+		"no tensorflow container": {
+			in:             newSpec(tfv2.TFReplicaTypeWorker, 1, v1.Container{Name: "sidecar", Image: "busybox"}),
+			expectingError: true,
+		},
+		"missing image": {
+			in:             newSpec(tfv2.TFReplicaTypeWorker, 1, v1.Container{Name: tfv2.DefaultContainerName}),
+			expectingError: true,
+		},
+		"unknown replica type": {
+			in:             newSpec(tfv2.TFReplicaType("Master"), 1, tfContainer),
+			expectingError: true,
+		},
+		"two chiefs": {
+			in:             newSpec(tfv2.TFReplicaTypeChief, 2, tfContainer),
+			expectingError: true,
+		},
+		"scheduling timeout": {
+			in: withSchedulingTimeout(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), 600, true),
+		},
+		"negative scheduling timeout": {
+			in:             withSchedulingTimeout(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), -1, false),
+			expectingError: true,
+		},
+		"exit code rules": {
+			in: withExitCodeRules(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), tfv2.RestartPolicyExitCode,
+				tfv2.ExitCodeRule{ExitCodes: &tfv2.ExitCodeRange{Min: 64, Max: 64}, Action: tfv2.ExitCodeActionIgnore},
+				tfv2.ExitCodeRule{Reason: "OOMKilled", Action: tfv2.ExitCodeActionRestartPod}),
+		},
+		"exit code rules without the ExitCode restart policy": {
+			in: withExitCodeRules(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), tfv2.RestartPolicyNever,
+				tfv2.ExitCodeRule{Signal: tfv2.Int32(9), Action: tfv2.ExitCodeActionRestartPod}),
+			expectingError: true,
+		},
+		"exit code rule without matcher": {
+			in: withExitCodeRules(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), tfv2.RestartPolicyExitCode,
+				tfv2.ExitCodeRule{Action: tfv2.ExitCodeActionRestartPod}),
+			expectingError: true,
+		},
+		"exit code rule with unknown action": {
+			in: withExitCodeRules(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), tfv2.RestartPolicyExitCode,
+				tfv2.ExitCodeRule{Signal: tfv2.Int32(9), Action: "Retry"}),
+			expectingError: true,
+		},
+		"empty exit code range": {
+			in: withExitCodeRules(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), tfv2.RestartPolicyExitCode,
+				tfv2.ExitCodeRule{ExitCodes: &tfv2.ExitCodeRange{Min: 10, Max: 1}, Action: tfv2.ExitCodeActionFailJob}),
+			expectingError: true,
+		},
+		"startup policy": {
+			in: withStartupOrder(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), "ps", "worker"),
+		},
+		"startup policy with the default order": {
+			in: withStartupOrder(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer)),
+		},
+		"startup policy with unknown replica type": {
+			in:             withStartupOrder(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), "PS", "Master"),
+			expectingError: true,
+		},
+		"startup policy with duplicate replica type": {
+			in:             withStartupOrder(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), "PS", "ps"),
+			expectingError: true,
+		},
+		"cluster spec in a configmap": {
+			in: withClusterSpecDelivery(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), tfv2.ClusterSpecDeliveryConfigMap),
+		},
+		"cluster spec in a configmap without command": {
+			in:             withClusterSpecDelivery(newSpec(tfv2.TFReplicaTypeWorker, 1, entrypointContainer), tfv2.ClusterSpecDeliveryConfigMap),
+			expectingError: true,
+		},
+		"host network without command": {
+			in:             withHostNetwork(newSpec(tfv2.TFReplicaTypeWorker, 1, entrypointContainer), 30000, 30001),
+			expectingError: true,
+		},
+		"host network": {
+			in: withHostNetwork(newSpec(tfv2.TFReplicaTypeWorker, 2, tfContainer), 30000, 30001),
+		},
+		"host network with too few ports": {
+			in:             withHostNetwork(newSpec(tfv2.TFReplicaTypeWorker, 3, tfContainer), 30000, 30001),
+			expectingError: true,
+		},
+		"host network with the cluster spec in env": {
+			in:             withClusterSpecDelivery(withHostNetwork(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), 0, 0), tfv2.ClusterSpecDeliveryEnv),
+			expectingError: true,
+		},
+		"host network with startup policy": {
+			in:             withStartupOrder(withHostNetwork(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), 0, 0)),
+			expectingError: true,
+		},
+		"spread placement": {
+			in: withPlacement(newSpec(tfv2.TFReplicaTypeWorker, 2, tfContainer), tfv2.TFReplicaTypeWorker, tfv2.Placement{Policy: tfv2.PlacementPolicySpread}),
+		},
+		"colocate placement": {
+			in: withPlacement(withReplicaSpec(newSpec(tfv2.TFReplicaTypeWorker, 2, tfContainer), tfv2.TFReplicaTypePS, 1, tfContainer),
+				tfv2.TFReplicaTypeWorker, tfv2.Placement{Policy: tfv2.PlacementPolicyColocate, ColocateWith: "ps"}),
+		},
+		"colocate placement without the replica type": {
+			in:             withPlacement(newSpec(tfv2.TFReplicaTypeWorker, 2, tfContainer), tfv2.TFReplicaTypeWorker, tfv2.Placement{Policy: tfv2.PlacementPolicyColocate}),
+			expectingError: true,
+		},
+		"colocate placement with itself": {
+			in:             withPlacement(newSpec(tfv2.TFReplicaTypeWorker, 2, tfContainer), tfv2.TFReplicaTypeWorker, tfv2.Placement{Policy: tfv2.PlacementPolicyColocate, ColocateWith: tfv2.TFReplicaTypeWorker}),
+			expectingError: true,
+		},
+		"spread placement with colocateWith": {
+			in: withPlacement(withReplicaSpec(newSpec(tfv2.TFReplicaTypeWorker, 2, tfContainer), tfv2.TFReplicaTypePS, 1, tfContainer),
+				tfv2.TFReplicaTypeWorker, tfv2.Placement{Policy: tfv2.PlacementPolicySpread, ColocateWith: tfv2.TFReplicaTypePS}),
+			expectingError: true,
+		},
+		"unknown placement policy": {
+			in:             withPlacement(newSpec(tfv2.TFReplicaTypeWorker, 2, tfContainer), tfv2.TFReplicaTypeWorker, tfv2.Placement{Policy: "Random"}),
+			expectingError: true,
+		},
+		"network isolation": {
+			in: withNetworkIsolation(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer),
+				networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "prometheus"}}}),
+		},
+		"network isolation with empty peer": {
+			in:             withNetworkIsolation(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), networkingv1.NetworkPolicyPeer{}),
+			expectingError: true,
+		},
+		"network isolation with host network": {
+			in:             withNetworkIsolation(withHostNetwork(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), 0, 0)),
+			expectingError: true,
+		},
+		"update strategy": {
+			in: withUpdateStrategy(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), tfv2.UpdateStrategyOnDelete),
+		},
+		"unknown update strategy": {
+			in:             withUpdateStrategy(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), "RollingUpdate"),
+			expectingError: true,
+		},
+		"image in the job template": {
+			in: withJobTemplate(newSpec(tfv2.TFReplicaTypeWorker, 1, v1.Container{Name: tfv2.DefaultContainerName}), tfContainer),
+		},
+		"container in the job template": {
+			in: withJobTemplate(newSpec(tfv2.TFReplicaTypeWorker, 1), tfContainer),
+		},
+		"missing image in the job template": {
+			in:             withJobTemplate(newSpec(tfv2.TFReplicaTypeWorker, 1), v1.Container{Name: tfv2.DefaultContainerName}),
+			expectingError: true,
+		},
+		"unknown cluster spec delivery": {
+			in:             withClusterSpecDelivery(newSpec(tfv2.TFReplicaTypeWorker, 1, tfContainer), "File"),
+			expectingError: true,
+		},
+	}
+
+	for name, c := range testCases {
+		job := &tfv2.TFJob{
+			Spec: *c.in,
+		}
+		tfv2.SetObjectDefaults_TFJob(job)
+		if err := ValidateV1Alpha2TFJobSpec(&job.Spec); (err != nil) != c.expectingError {
+			t.Errorf("%s: unexpected validation result: %v", name, err)
+		}
+	}
+}
+
+func withExitCodeRules(spec *tfv2.TFJobSpec, policy tfv2.RestartPolicy, rules ...tfv2.ExitCodeRule) *tfv2.TFJobSpec {
+	for _, replicaSpec := range spec.TFReplicaSpecs {
+		replicaSpec.RestartPolicy = policy
+		replicaSpec.ExitCodeRules = rules
+	}
+	return spec
+}
+
+func withStartupOrder(spec *tfv2.TFJobSpec, order ...tfv2.TFReplicaType) *tfv2.TFJobSpec {
+	spec.StartupPolicy = &tfv2.StartupPolicy{Order: order, WaitForPeers: true}
+	return spec
+}
+
+func withClusterSpecDelivery(spec *tfv2.TFJobSpec, delivery tfv2.ClusterSpecDelivery) *tfv2.TFJobSpec {
+	spec.ClusterSpecDelivery = delivery
+	return spec
+}
+
+func withHostNetwork(spec *tfv2.TFJobSpec, minPort, maxPort int32) *tfv2.TFJobSpec {
+	spec.HostNetwork = &tfv2.HostNetworkPolicy{MinPort: minPort, MaxPort: maxPort}
+	return spec
+}
+
+func withReplicaSpec(spec *tfv2.TFJobSpec, rtype tfv2.TFReplicaType, replicas int32, containers ...v1.Container) *tfv2.TFJobSpec {
+	spec.TFReplicaSpecs[rtype] = &tfv2.TFReplicaSpec{
+		Replicas: tfv2.Int32(replicas),
+		Template: v1.PodTemplateSpec{
+			Spec: v1.PodSpec{
+				Containers: containers,
+			},
+		},
+	}
+	return spec
+}
+
+func withJobTemplate(spec *tfv2.TFJobSpec, containers ...v1.Container) *tfv2.TFJobSpec {
+	spec.Template = &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: containers,
+		},
+	}
+	return spec
+}
+
+func withPlacement(spec *tfv2.TFJobSpec, rtype tfv2.TFReplicaType, placement tfv2.Placement) *tfv2.TFJobSpec {
+	spec.TFReplicaSpecs[rtype].Placement = &placement
+	return spec
+}
+
+func withNetworkIsolation(spec *tfv2.TFJobSpec, allowFrom ...networkingv1.NetworkPolicyPeer) *tfv2.TFJobSpec {
+	spec.NetworkIsolation = &tfv2.NetworkIsolationPolicy{AllowFrom: allowFrom}
+	return spec
+}
+
+func withUpdateStrategy(spec *tfv2.TFJobSpec, strategy tfv2.UpdateStrategy) *tfv2.TFJobSpec {
+	spec.UpdateStrategy = strategy
+	return spec
+}
+
+func withSchedulingTimeout(spec *tfv2.TFJobSpec, seconds int64, fail bool) *tfv2.TFJobSpec {
+	spec.SchedulingTimeoutSeconds = &seconds
+	spec.FailOnSchedulingTimeout = fail
+	return spec
+}
+
+func TestValidateTFJobSweepSpec(t *testing.T) {
+	newSweep := func(modify func(spec *tfv2.TFJobSweepSpec)) *tfv2.TFJobSweepSpec {
+		spec := &tfv2.TFJobSweepSpec{
+			Template: tfv2.TFJobTemplateSpec{
+				Spec: tfv2.TFJobSpec{
+					TFReplicaSpecs: map[tfv2.TFReplicaType]*tfv2.TFReplicaSpec{
+						tfv2.TFReplicaTypeWorker: {
+							Template: v1.PodTemplateSpec{
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{Name: tfv2.DefaultContainerName, Image: "tensorflow/tensorflow:1.8.0"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Parameters: []tfv2.SweepParameter{
+				{Name: "learning_rate", Range: &tfv2.ParameterRange{Min: 0.001, Max: 0.1, Steps: 3, Scale: tfv2.ParameterScaleLog}},
+				{Name: "optimizer", Values: []string{"adam", "sgd"}},
+			},
+		}
+		modify(spec)
+		return spec
+	}
+
+	testCases := map[string]struct {
+		in             *tfv2.TFJobSweepSpec
+		expectingError bool
+	}{
+		"valid grid": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {}),
+		},
+		"valid random": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.Algorithm = tfv2.SweepAlgorithmRandom
+				spec.MaxTrials = tfv2.Int32(10)
+				spec.Parameters[0].Range.Steps = 0
+			}),
+		},
+		"valid list": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.Algorithm = tfv2.SweepAlgorithmList
+				spec.Parameters = nil
+				spec.Trials = []map[string]string{{"learning_rate": "0.01"}}
+			}),
+		},
+		"invalid template": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.Template.Spec.TFReplicaSpecs = nil
+			}),
+			expectingError: true,
+		},
+		"random without maxTrials": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.Algorithm = tfv2.SweepAlgorithmRandom
+			}),
+			expectingError: true,
+		},
+		"grid range without steps": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.Parameters[0].Range.Steps = 0
+			}),
+			expectingError: true,
+		},
+		"log range from zero": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.Parameters[0].Range.Min = 0
+			}),
+			expectingError: true,
+		},
+		"values and range": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.Parameters[1].Range = &tfv2.ParameterRange{Min: 0, Max: 1, Steps: 2}
+			}),
+			expectingError: true,
+		},
+		"duplicated parameter": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.Parameters[1].Name = "learning_rate"
+			}),
+			expectingError: true,
+		},
+		"list without trials": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.Algorithm = tfv2.SweepAlgorithmList
+				spec.Parameters = nil
+			}),
+			expectingError: true,
+		},
+		"zero target": {
+			in: newSweep(func(spec *tfv2.TFJobSweepSpec) {
+				spec.TargetSucceededTrials = tfv2.Int32(0)
+			}),
+			expectingError: true,
+		},
+	}
+
+	for name, c := range testCases {
+		sweep := &tfv2.TFJobSweep{
+			Spec: *c.in,
+		}
+		tfv2.SetObjectDefaults_TFJobSweep(sweep)
+		if err := ValidateTFJobSweepSpec(&sweep.Spec); (err != nil) != c.expectingError {
+			t.Errorf("%s: unexpected validation result: %v", name, err)
+		}
+	}
+}
+
+func TestValidateCronTFJobSpec(t *testing.T) {
+	newCron := func(modify func(spec *tfv2.CronTFJobSpec)) *tfv2.CronTFJobSpec {
+		spec := &tfv2.CronTFJobSpec{
+			Schedule: "0 2 * * *",
+			JobTemplate: tfv2.TFJobTemplateSpec{
+				Spec: tfv2.TFJobSpec{
+					TFReplicaSpecs: map[tfv2.TFReplicaType]*tfv2.TFReplicaSpec{
+						tfv2.TFReplicaTypeWorker: {
+							Template: v1.PodTemplateSpec{
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{Name: tfv2.DefaultContainerName, Image: "tensorflow/tensorflow:1.8.0"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		modify(spec)
+		return spec
+	}
+
+	testCases := map[string]struct {
+		in             *tfv2.CronTFJobSpec
+		expectingError bool
+	}{
+		"valid": {
+			in: newCron(func(spec *tfv2.CronTFJobSpec) {}),
+		},
+		"valid with deadline and policy": {
+			in: newCron(func(spec *tfv2.CronTFJobSpec) {
+				spec.StartingDeadlineSeconds = tfv2.Int64(300)
+				spec.ConcurrencyPolicy = tfv2.ConcurrencyPolicyReplace
+			}),
+		},
+		"invalid schedule": {
+			in: newCron(func(spec *tfv2.CronTFJobSpec) {
+				spec.Schedule = "0 25 * * *"
+			}),
+			expectingError: true,
+		},
+		"invalid template": {
+			in: newCron(func(spec *tfv2.CronTFJobSpec) {
+				spec.JobTemplate.Spec.TFReplicaSpecs = nil
+			}),
+			expectingError: true,
+		},
+		"unknown policy": {
+			in: newCron(func(spec *tfv2.CronTFJobSpec) {
+				spec.ConcurrencyPolicy = "Queue"
+			}),
+			expectingError: true,
+		},
+		"negative deadline": {
+			in: newCron(func(spec *tfv2.CronTFJobSpec) {
+				spec.StartingDeadlineSeconds = tfv2.Int64(-1)
+			}),
+			expectingError: true,
+		},
+		"negative history limit": {
+			in: newCron(func(spec *tfv2.CronTFJobSpec) {
+				spec.FailedJobsHistoryLimit = tfv2.Int32(-1)
+			}),
+			expectingError: true,
+		},
+	}
+
+	for name, c := range testCases {
+		cron := &tfv2.CronTFJob{
+			Spec: *c.in,
+		}
+		tfv2.SetObjectDefaults_CronTFJob(cron)
+		if err := ValidateCronTFJobSpec(&cron.Spec); (err != nil) != c.expectingError {
+			t.Errorf("%s: unexpected validation result: %v", name, err)
+		}
+	}
+}
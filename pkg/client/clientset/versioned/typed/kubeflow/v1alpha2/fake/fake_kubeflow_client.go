@@ -26,10 +26,18 @@ type FakeKubeflowV1alpha2 struct {
 	*testing.Fake
 }
 
+func (c *FakeKubeflowV1alpha2) CronTFJobs(namespace string) v1alpha2.CronTFJobInterface {
+	return &FakeCronTFJobs{c, namespace}
+}
+
 func (c *FakeKubeflowV1alpha2) TFJobs(namespace string) v1alpha2.TFJobInterface {
 	return &FakeTFJobs{c, namespace}
 }
 
+func (c *FakeKubeflowV1alpha2) TFJobSweeps(namespace string) v1alpha2.TFJobSweepInterface {
+	return &FakeTFJobSweeps{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeKubeflowV1alpha2) RESTClient() rest.Interface {
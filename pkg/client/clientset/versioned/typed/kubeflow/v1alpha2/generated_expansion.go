@@ -16,4 +16,8 @@
 
 package v1alpha2
 
+type CronTFJobExpansion interface{}
+
 type TFJobExpansion interface{}
+
+type TFJobSweepExpansion interface{}
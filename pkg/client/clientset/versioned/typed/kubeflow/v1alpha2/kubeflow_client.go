@@ -25,7 +25,9 @@ import (
 
 type KubeflowV1alpha2Interface interface {
 	RESTClient() rest.Interface
+	CronTFJobsGetter
 	TFJobsGetter
+	TFJobSweepsGetter
 }
 
 // KubeflowV1alpha2Client is used to interact with features provided by the kubeflow.org group.
@@ -33,10 +35,18 @@ type KubeflowV1alpha2Client struct {
 	restClient rest.Interface
 }
 
+func (c *KubeflowV1alpha2Client) CronTFJobs(namespace string) CronTFJobInterface {
+	return newCronTFJobs(c, namespace)
+}
+
 func (c *KubeflowV1alpha2Client) TFJobs(namespace string) TFJobInterface {
 	return newTFJobs(c, namespace)
 }
 
+func (c *KubeflowV1alpha2Client) TFJobSweeps(namespace string) TFJobSweepInterface {
+	return newTFJobSweeps(c, namespace)
+}
+
 // NewForConfig creates a new KubeflowV1alpha2Client for the given config.
 func NewForConfig(c *rest.Config) (*KubeflowV1alpha2Client, error) {
 	config := *c
@@ -58,8 +58,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubeflow().V1alpha1().TFJobs().Informer()}, nil
 
 		// Group=Kubeflow, Version=V1alpha2
+	case v1alpha2.SchemeGroupVersion.WithResource("crontfjobs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubeflow().V1alpha2().CronTFJobs().Informer()}, nil
 	case v1alpha2.SchemeGroupVersion.WithResource("tfjobs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubeflow().V1alpha2().TFJobs().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("tfjobsweeps"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubeflow().V1alpha2().TFJobSweeps().Informer()}, nil
 
 	}
 
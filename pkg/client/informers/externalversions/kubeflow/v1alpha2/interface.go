@@ -24,8 +24,12 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// CronTFJobs returns a CronTFJobInformer.
+	CronTFJobs() CronTFJobInformer
 	// TFJobs returns a TFJobInformer.
 	TFJobs() TFJobInformer
+	// TFJobSweeps returns a TFJobSweepInformer.
+	TFJobSweeps() TFJobSweepInformer
 }
 
 type version struct {
@@ -37,7 +41,17 @@ func New(f internalinterfaces.SharedInformerFactory) Interface {
 	return &version{f}
 }
 
+// CronTFJobs returns a CronTFJobInformer.
+func (v *version) CronTFJobs() CronTFJobInformer {
+	return &cronTFJobInformer{factory: v.SharedInformerFactory}
+}
+
 // TFJobs returns a TFJobInformer.
 func (v *version) TFJobs() TFJobInformer {
 	return &tFJobInformer{factory: v.SharedInformerFactory}
 }
+
+// TFJobSweeps returns a TFJobSweepInformer.
+func (v *version) TFJobSweeps() TFJobSweepInformer {
+	return &tFJobSweepInformer{factory: v.SharedInformerFactory}
+}
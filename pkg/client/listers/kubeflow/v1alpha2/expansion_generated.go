@@ -18,6 +18,14 @@
 
 package v1alpha2
 
+// CronTFJobListerExpansion allows custom methods to be added to
+// CronTFJobLister.
+type CronTFJobListerExpansion interface{}
+
+// CronTFJobNamespaceListerExpansion allows custom methods to be added to
+// CronTFJobNamespaceLister.
+type CronTFJobNamespaceListerExpansion interface{}
+
 // TFJobListerExpansion allows custom methods to be added to
 // TFJobLister.
 type TFJobListerExpansion interface{}
@@ -25,3 +33,11 @@ type TFJobListerExpansion interface{}
 // TFJobNamespaceListerExpansion allows custom methods to be added to
 // TFJobNamespaceLister.
 type TFJobNamespaceListerExpansion interface{}
+
+// TFJobSweepListerExpansion allows custom methods to be added to
+// TFJobSweepLister.
+type TFJobSweepListerExpansion interface{}
+
+// TFJobSweepNamespaceListerExpansion allows custom methods to be added to
+// TFJobSweepNamespaceLister.
+type TFJobSweepNamespaceListerExpansion interface{}
@@ -0,0 +1,342 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobcontroller provides the parts of a Kubernetes controller common
+// to the distributed training jobs, e.g. TFJob, whose replicas are pods with
+// a headless service each. The controller of a job resource implements
+// ControllerInterface for its framework and embeds a JobController.
+package jobcontroller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubeclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/pkg/controller"
+
+	"github.com/kubeflow/tf-operator/pkg/control"
+)
+
+// LabelGroupName is the label of the pods and services with the API group of their job.
+const LabelGroupName = "group_name"
+
+var (
+	// KeyFunc is the short name to DeletionHandlingMetaNamespaceKeyFunc.
+	// IndexerInformer uses a delta queue, therefore for deletes we have to use this
+	// key function but it should be just fine for non delete events.
+	KeyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
+)
+
+// Job is a job resource, e.g. a *TFJob.
+type Job interface {
+	metav1.Object
+	runtime.Object
+}
+
+// ControllerInterface defines the hooks of a job controller for its framework.
+type ControllerInterface interface {
+	// ControllerName returns the name of the controller, which is the
+	// source of its events.
+	ControllerName() string
+
+	// GetAPIGroupVersionKind returns the GroupVersionKind of the jobs.
+	GetAPIGroupVersionKind() schema.GroupVersionKind
+
+	// GetGroupNameLabelValue returns the value of the LabelGroupName label
+	// of the pods and services.
+	GetGroupNameLabelValue() string
+
+	// GetJobNameLabelKey returns the label of the pods and services with
+	// the name of their job.
+	GetJobNameLabelKey() string
+
+	// GetReplicaTypeLabelKey returns the label of the pods and services
+	// with their replica type.
+	GetReplicaTypeLabelKey() string
+
+	// GetReplicaIndexLabelKey returns the label of the pods and services
+	// with their index in their replica type.
+	GetReplicaIndexLabelKey() string
+
+	// GetReplicaAttemptLabelKey returns the label of the pods with their
+	// attempt, the number of pods of their index created before them.
+	GetReplicaAttemptLabelKey() string
+
+	// GetDefaultContainerPortName returns the name of the port the
+	// replicas communicate on, which the services expose.
+	GetDefaultContainerPortName() string
+
+	// GetJobFromInformerCache returns the job with the given namespace and
+	// name from the cache of the controller.
+	GetJobFromInformerCache(namespace, name string) (Job, error)
+
+	// GetJobFromAPIClient returns the job with the given namespace and name
+	// from the API server, bypassing the cache.
+	GetJobFromAPIClient(namespace, name string) (Job, error)
+
+	// GetReplicaTypes returns the replica types of the job.
+	GetReplicaTypes(job Job) []string
+
+	// SetClusterSpec sets the cluster spec of the framework, usually an
+	// environment variable, in the template of the pod of the given
+	// replica type and index.
+	SetClusterSpec(job Job, podTemplate *v1.PodTemplateSpec, rtype, index string) error
+
+	// IsMasterRole returns true if the pods of the replica type decide
+	// whether the job is running, has succeeded or has failed.
+	IsMasterRole(job Job, rtype string) bool
+}
+
+// JobController holds the state and implements the logic common to the job
+// controllers: the expectations of pod and service creations, the claiming
+// of the pods and services of the jobs, their creation, and the conditions.
+type JobController struct {
+	// Controller is the job controller of the framework.
+	Controller ControllerInterface
+
+	// PodControl is used to add or delete pods.
+	PodControl controller.PodControlInterface
+
+	// ServiceControl is used to add or delete services.
+	ServiceControl control.ServiceControlInterface
+
+	// KubeClientSet is a standard kubernetes clientset.
+	KubeClientSet kubeclientset.Interface
+
+	// PodLister can list/get pods from the shared informer's store.
+	PodLister corelisters.PodLister
+
+	// ServiceLister can list/get services from the shared informer's store.
+	ServiceLister corelisters.ServiceLister
+
+	// PodInformerSynced returns true if the pod store has been synced at least once.
+	PodInformerSynced cache.InformerSynced
+
+	// ServiceInformerSynced returns true if the service store has been synced at least once.
+	ServiceInformerSynced cache.InformerSynced
+
+	// Expectations is a TTLCache of pod/services creates/deletes each job
+	// expects to see. The keys are the job key, the replica type and
+	// pods or services, e.g. "tf-operator/tfjob-abc/ps/pods".
+	Expectations controller.ControllerExpectationsInterface
+
+	// WorkQueue is a rate limited work queue of job keys. This is used to
+	// queue work to be processed instead of performing it as soon as a
+	// change happens.
+	WorkQueue workqueue.RateLimitingInterface
+
+	// Recorder is an event recorder for recording Event resources to the
+	// Kubernetes API.
+	Recorder record.EventRecorder
+}
+
+// NewJobController returns a new JobController for the job controller of a
+// framework. The jobs of the pods and services are added to workQueue when
+// they change.
+func NewJobController(
+	controllerImpl ControllerInterface,
+	kubeClientSet kubeclientset.Interface,
+	kubeInformerFactory kubeinformers.SharedInformerFactory,
+	workQueue workqueue.RateLimitingInterface) *JobController {
+
+	log.Debug("Creating event broadcaster")
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(log.Infof)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
+	source := v1.EventSource{Component: controllerImpl.ControllerName()}
+
+	jc := &JobController{
+		Controller: controllerImpl,
+		PodControl: control.RealPodControl{
+			KubeClient: kubeClientSet,
+			Recorder:   eventBroadcaster.NewRecorder(scheme.Scheme, source),
+		},
+		ServiceControl: control.RealServiceControl{
+			KubeClient: kubeClientSet,
+			Recorder:   eventBroadcaster.NewRecorder(scheme.Scheme, source),
+		},
+		KubeClientSet: kubeClientSet,
+		Expectations:  controller.NewControllerExpectations(),
+		WorkQueue:     workQueue,
+		Recorder:      eventBroadcaster.NewRecorder(scheme.Scheme, source),
+	}
+
+	// Create pod informer.
+	podInformer := kubeInformerFactory.Core().V1().Pods()
+
+	// Set up an event handler for when pod resources change
+	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    jc.AddPod,
+		UpdateFunc: jc.UpdatePod,
+		DeleteFunc: jc.DeletePod,
+	})
+
+	jc.PodLister = podInformer.Lister()
+	jc.PodInformerSynced = podInformer.Informer().HasSynced
+
+	// Create service informer.
+	serviceInformer := kubeInformerFactory.Core().V1().Services()
+
+	// Set up an event handler for when service resources change.
+	serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    jc.AddService,
+		UpdateFunc: jc.UpdateService,
+		DeleteFunc: jc.DeleteService,
+	})
+
+	jc.ServiceLister = serviceInformer.Lister()
+	jc.ServiceInformerSynced = serviceInformer.Informer().HasSynced
+
+	return jc
+}
+
+// GenOwnerReference returns the controller reference of the pods and services of the job.
+func (jc *JobController) GenOwnerReference(job metav1.Object) *metav1.OwnerReference {
+	boolPtr := func(b bool) *bool { return &b }
+	gvk := jc.Controller.GetAPIGroupVersionKind()
+	controllerRef := &metav1.OwnerReference{
+		APIVersion:         gvk.GroupVersion().String(),
+		Kind:               gvk.Kind,
+		Name:               job.GetName(),
+		UID:                job.GetUID(),
+		BlockOwnerDeletion: boolPtr(true),
+		Controller:         boolPtr(true),
+	}
+
+	return controllerRef
+}
+
+// GenLabels returns the labels of the pods and services of the job with the given name.
+func (jc *JobController) GenLabels(jobName string) map[string]string {
+	return map[string]string{
+		LabelGroupName:                     jc.Controller.GetGroupNameLabelValue(),
+		jc.Controller.GetJobNameLabelKey(): strings.Replace(jobName, "/", "-", -1),
+	}
+}
+
+// GenGeneralName returns the name of the pod and service of the given
+// replica type and index of a job.
+func GenGeneralName(jobName, rtype, index string) string {
+	n := jobName + "-" + rtype + "-" + index
+	return strings.Replace(n, "/", "-", -1)
+}
+
+// GenPodName returns the name of the pod of the given attempt of the replica
+// of the given type and index of a job. The attempts have distinct names, so
+// that a pod is created again while the previous one is terminating.
+func GenPodName(jobName, rtype, index string, attempt int32) string {
+	return GenGeneralName(jobName, rtype, index) + "-" + strconv.Itoa(int(attempt))
+}
+
+// GenExpectationPodsKey returns the expectations key of the pods of the replica type.
+func GenExpectationPodsKey(jobKey, replicaType string) string {
+	return jobKey + "/" + strings.ToLower(replicaType) + "/pods"
+}
+
+// GenExpectationServicesKey returns the expectations key of the services of the replica type.
+func GenExpectationServicesKey(jobKey, replicaType string) string {
+	return jobKey + "/" + strings.ToLower(replicaType) + "/services"
+}
+
+// SatisfiedExpectations returns true if the required adds/dels for the given job have been observed.
+// Add/del counts are established by the controller at sync time, and updated as controllees are observed by the controller
+// manager.
+func (jc *JobController) SatisfiedExpectations(job Job) bool {
+	satisfied := false
+	jobKey, err := KeyFunc(job)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for job object %#v: %v", job, err))
+		return false
+	}
+
+	for _, rtype := range jc.Controller.GetReplicaTypes(job) {
+		// Check the expectations of the pods.
+		expectationPodsKey := GenExpectationPodsKey(jobKey, rtype)
+		satisfied = satisfied || jc.Expectations.SatisfiedExpectations(expectationPodsKey)
+
+		// Check the expectations of the services.
+		expectationServicesKey := GenExpectationServicesKey(jobKey, rtype)
+		satisfied = satisfied || jc.Expectations.SatisfiedExpectations(expectationServicesKey)
+	}
+
+	return satisfied
+}
+
+// enqueueJob adds the job to the work queue.
+func (jc *JobController) enqueueJob(job Job) {
+	key, err := KeyFunc(job)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for job object %#v: %v", job, err))
+		return
+	}
+
+	jc.WorkQueue.Add(key)
+}
+
+// resolveControllerRef returns the job referenced by a ControllerRef,
+// or nil if the ControllerRef could not be resolved to a matching job
+// of the correct Kind.
+func (jc *JobController) resolveControllerRef(namespace string, controllerRef *metav1.OwnerReference) Job {
+	// We can't look up by UID, so look up by Name and then verify UID.
+	// Don't even try to look up by Name if it's the wrong Kind.
+	if controllerRef.Kind != jc.Controller.GetAPIGroupVersionKind().Kind {
+		return nil
+	}
+	job, err := jc.Controller.GetJobFromInformerCache(namespace, controllerRef.Name)
+	if err != nil {
+		return nil
+	}
+	if job.GetUID() != controllerRef.UID {
+		// The controller we found with this Name is not the same one that the
+		// ControllerRef points to.
+		return nil
+	}
+	return job
+}
+
+// recheckDeletionTimestamp returns a CanAdopt() function to recheck deletion
+// of the job, with an uncached quorum read (see #42639).
+//
+// The CanAdopt() function fetches the latest value of the job, and denies
+// adoption attempts if it has a non-nil DeletionTimestamp.
+func (jc *JobController) recheckDeletionTimestamp(job Job) func() error {
+	return func() error {
+		fresh, err := jc.Controller.GetJobFromAPIClient(job.GetNamespace(), job.GetName())
+		if err != nil {
+			return fmt.Errorf("can't recheck DeletionTimestamp: %v", err)
+		}
+		if fresh.GetUID() != job.GetUID() {
+			return fmt.Errorf("can't recheck DeletionTimestamp: original %s %v/%v is gone: got uid %v, wanted %v",
+				jc.Controller.GetAPIGroupVersionKind().Kind, job.GetNamespace(), job.GetName(), fresh.GetUID(), job.GetUID())
+		}
+		if fresh.GetDeletionTimestamp() != nil {
+			return fmt.Errorf("%v/%v has just been deleted at %v", fresh.GetNamespace(), fresh.GetName(), fresh.GetDeletionTimestamp())
+		}
+		return nil
+	}
+}
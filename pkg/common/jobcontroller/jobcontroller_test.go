@@ -0,0 +1,121 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobcontroller
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// testController is a ControllerInterface for jobs with a master replica
+// type and worker replica types.
+type testController struct{}
+
+var _ ControllerInterface = testController{}
+
+func (testController) ControllerName() string {
+	return "test-operator"
+}
+
+func (testController) GetAPIGroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: "test.kubeflow.org", Version: "v1alpha2", Kind: "TestJob"}
+}
+
+func (testController) GetGroupNameLabelValue() string {
+	return "test.kubeflow.org"
+}
+
+func (testController) GetJobNameLabelKey() string {
+	return "test_job_name"
+}
+
+func (testController) GetReplicaTypeLabelKey() string {
+	return "test-replica-type"
+}
+
+func (testController) GetReplicaIndexLabelKey() string {
+	return "test-replica-index"
+}
+
+func (testController) GetReplicaAttemptLabelKey() string {
+	return "test-replica-attempt"
+}
+
+func (testController) GetDefaultContainerPortName() string {
+	return "test-port"
+}
+
+func (testController) GetJobFromInformerCache(namespace, name string) (Job, error) {
+	return newTestJob(), nil
+}
+
+func (testController) GetJobFromAPIClient(namespace, name string) (Job, error) {
+	return newTestJob(), nil
+}
+
+func (testController) GetReplicaTypes(job Job) []string {
+	return []string{"master", "worker"}
+}
+
+func (testController) SetClusterSpec(job Job, podTemplate *v1.PodTemplateSpec, rtype, index string) error {
+	return nil
+}
+
+func (testController) IsMasterRole(job Job, rtype string) bool {
+	return rtype == "master"
+}
+
+func newTestJobController() *JobController {
+	return &JobController{Controller: testController{}}
+}
+
+func newTestJob() Job {
+	job := &unstructured.Unstructured{}
+	job.SetAPIVersion("test.kubeflow.org/v1alpha2")
+	job.SetKind("TestJob")
+	job.SetNamespace(metav1.NamespaceDefault)
+	job.SetName("test-job")
+	job.SetUID("test-uid")
+	return job
+}
+
+func TestGenLabels(t *testing.T) {
+	jc := newTestJobController()
+	labels := jc.GenLabels("default/test-job")
+	if labels[LabelGroupName] != "test.kubeflow.org" {
+		t.Errorf("Expected %s test.kubeflow.org, got %s", LabelGroupName, labels[LabelGroupName])
+	}
+	if labels["test_job_name"] != "default-test-job" {
+		t.Errorf("Expected test_job_name default-test-job, got %s", labels["test_job_name"])
+	}
+}
+
+func TestGenOwnerReference(t *testing.T) {
+	jc := newTestJobController()
+	ref := jc.GenOwnerReference(newTestJob())
+	if ref.Kind != "TestJob" || ref.APIVersion != "test.kubeflow.org/v1alpha2" {
+		t.Errorf("Expected the kind and version of the job, got %s %s", ref.Kind, ref.APIVersion)
+	}
+	if ref.Name != "test-job" || ref.UID != "test-uid" {
+		t.Errorf("Expected the name and UID of the job, got %s %s", ref.Name, ref.UID)
+	}
+	if ref.Controller == nil || !*ref.Controller {
+		t.Errorf("Expected the job to be the controller")
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobcontroller
+
+import (
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LoggerForJob returns the logger of the job.
+func LoggerForJob(job metav1.Object) *log.Entry {
+	return log.WithFields(log.Fields{
+		// We use job to match the key used in the controller.
+		// In the controller we log the key used with the workqueue.
+		"job": job.GetNamespace() + "/" + job.GetName(),
+		"uid": job.GetUID(),
+	})
+}
+
+// LoggerForReplica returns the logger of the replica type of the job.
+func LoggerForReplica(job metav1.Object, rtype string) *log.Entry {
+	return log.WithFields(log.Fields{
+		// We use job to match the key used in the controller.
+		// In the controller we log the key used with the workqueue.
+		"job":          job.GetNamespace() + "/" + job.GetName(),
+		"uid":          job.GetUID(),
+		"replica-type": rtype,
+	})
+}
@@ -0,0 +1,300 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobcontroller
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/controller"
+)
+
+// GetPodsForJob returns the set of pods that this job should manage.
+// It also reconciles ControllerRef by adopting/orphaning.
+// Note that the returned Pods are pointers into the cache.
+func (jc *JobController) GetPodsForJob(job Job) ([]*v1.Pod, error) {
+	// Create selector.
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels: jc.GenLabels(job.GetName()),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("couldn't convert Job selector: %v", err)
+	}
+	// List all pods to include those that don't match the selector anymore
+	// but have a ControllerRef pointing to this controller.
+	pods, err := jc.PodLister.Pods(job.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	// If any adoptions are attempted, we should first recheck for deletion
+	// with an uncached quorum read sometime after listing Pods.
+	canAdoptFunc := jc.recheckDeletionTimestamp(job)
+	cm := controller.NewPodControllerRefManager(jc.PodControl, job, selector, jc.Controller.GetAPIGroupVersionKind(), canAdoptFunc)
+	return cm.ClaimPods(pods)
+}
+
+// FilterPodsForReplicaType returns pods belong to a replica type.
+func (jc *JobController) FilterPodsForReplicaType(pods []*v1.Pod, replicaType string) []*v1.Pod {
+	var result []*v1.Pod
+
+	replicaSelector := &metav1.LabelSelector{
+		MatchLabels: make(map[string]string),
+	}
+
+	replicaSelector.MatchLabels[jc.Controller.GetReplicaTypeLabelKey()] = replicaType
+
+	for _, pod := range pods {
+		selector, _ := metav1.LabelSelectorAsSelector(replicaSelector)
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		result = append(result, pod)
+	}
+	return result
+}
+
+// GetPodSlices returns a slice, which element is the slice of pod.
+// Assume the return object is podSlices, then podSlices[i] is an
+// array of pointers to pods corresponding to replica i.
+func (jc *JobController) GetPodSlices(pods []*v1.Pod, replicas int, logger *log.Entry) [][]*v1.Pod {
+	indexLabel := jc.Controller.GetReplicaIndexLabelKey()
+	podSlices := make([][]*v1.Pod, replicas)
+	for _, pod := range pods {
+		if _, ok := pod.Labels[indexLabel]; !ok {
+			logger.Warning("The pod do not have the index label.")
+			continue
+		}
+		index, err := strconv.Atoi(pod.Labels[indexLabel])
+		if err != nil {
+			logger.Warningf("Error when strconv.Atoi: %v", err)
+			continue
+		}
+		if index < 0 || index >= replicas {
+			logger.Warningf("The label index is not expected: %d", index)
+		} else {
+			podSlices[index] = append(podSlices[index], pod)
+		}
+	}
+	return podSlices
+}
+
+// GetPodAttempt returns the attempt of the pod, 0 if it has none, e.g. if it
+// has been created before the pods had attempts.
+func (jc *JobController) GetPodAttempt(pod *v1.Pod) int32 {
+	attempt, err := strconv.Atoi(pod.Labels[jc.Controller.GetReplicaAttemptLabelKey()])
+	if err != nil {
+		return 0
+	}
+	return int32(attempt)
+}
+
+// CreateNewPod creates the pod of the given replica type, index and attempt
+// from the template, see SetPodTemplate.
+func (jc *JobController) CreateNewPod(job Job, rt, index string, attempt int32, podTemplate *v1.PodTemplateSpec) error {
+	jobKey, err := KeyFunc(job)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for job object %#v: %v", job, err))
+		return err
+	}
+	expectationPodsKey := GenExpectationPodsKey(jobKey, rt)
+	err = jc.Expectations.ExpectCreations(expectationPodsKey, 1)
+	if err != nil {
+		return err
+	}
+
+	// Create OwnerReference.
+	controllerRef := jc.GenOwnerReference(job)
+
+	if err := jc.SetPodTemplate(job, rt, index, attempt, podTemplate); err != nil {
+		jc.Expectations.CreationObserved(expectationPodsKey)
+		return err
+	}
+
+	err = jc.PodControl.CreatePodsWithControllerRef(job.GetNamespace(), podTemplate, job, controllerRef)
+	if err != nil && errors.IsTimeout(err) {
+		// Pod is created but its initialization has timed out.
+		// If the initialization is successful eventually, the
+		// controller will observe the creation via the informer.
+		// If the initialization fails, or if the pod keeps
+		// uninitialized for a long time, the informer will not
+		// receive any update, and the controller will create a new
+		// pod when the expectation expires.
+		return nil
+	} else if err != nil {
+		// The pod will not be observed, lower the expectations so that
+		// the creation is retried on the next sync.
+		jc.Expectations.CreationObserved(expectationPodsKey)
+		return err
+	}
+	return nil
+}
+
+// SetPodTemplate sets the name and the labels of the attempt of the replica
+// of the given type and index in the template of its pod, and the cluster
+// spec of the framework.
+func (jc *JobController) SetPodTemplate(job Job, rt, index string, attempt int32, podTemplate *v1.PodTemplateSpec) error {
+	// Set type, index and attempt for the replica.
+	labels := jc.GenLabels(job.GetName())
+	labels[jc.Controller.GetReplicaTypeLabelKey()] = rt
+	labels[jc.Controller.GetReplicaIndexLabelKey()] = index
+	labels[jc.Controller.GetReplicaAttemptLabelKey()] = strconv.Itoa(int(attempt))
+
+	// Set name for the template.
+	podTemplate.Name = GenPodName(job.GetName(), rt, index, attempt)
+
+	if podTemplate.Labels == nil {
+		podTemplate.Labels = make(map[string]string)
+	}
+
+	for key, value := range labels {
+		podTemplate.Labels[key] = value
+	}
+
+	return jc.Controller.SetClusterSpec(job, podTemplate, rt, index)
+}
+
+// AddPod enqueues the job that manages a created pod and updates its expectations.
+func (jc *JobController) AddPod(obj interface{}) {
+	pod := obj.(*v1.Pod)
+	if pod.DeletionTimestamp != nil {
+		// on a restart of the controller controller, it's possible a new pod shows up in a state that
+		// is already pending deletion. Prevent the pod from being a creation observation.
+		return
+	}
+
+	// If it has a ControllerRef, that's all that matters.
+	if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil {
+		job := jc.resolveControllerRef(pod.Namespace, controllerRef)
+		if job == nil {
+			log.Info("This pod's job does not exists")
+			return
+		}
+
+		jobKey, err := KeyFunc(job)
+		if err != nil {
+			LoggerForJob(job).Infof("Failed to get the key of the job: %v", err)
+			return
+		}
+
+		rtype, ok := pod.Labels[jc.Controller.GetReplicaTypeLabelKey()]
+		if !ok {
+			LoggerForJob(job).Infof("This pod maybe not created by %s", jc.Controller.ControllerName())
+			return
+		}
+
+		expectationPodsKey := GenExpectationPodsKey(jobKey, rtype)
+
+		jc.Expectations.CreationObserved(expectationPodsKey)
+		jc.enqueueJob(job)
+
+		return
+	}
+
+	// Otherwise, it's an orphan. No controller should be waiting for an
+	// orphan, so its creation is not observed.
+}
+
+// UpdatePod figures out what job manages an updated pod and wakes it up.
+// If the ControllerRef of the pod has changed, both the old and new jobs are
+// woken up. old and cur must be *v1.Pod types.
+func (jc *JobController) UpdatePod(old, cur interface{}) {
+	curPod := cur.(*v1.Pod)
+	oldPod := old.(*v1.Pod)
+	if curPod.ResourceVersion == oldPod.ResourceVersion {
+		// Periodic resync will send update events for all known pods.
+		// Two different versions of the same pod will always have different RVs.
+		return
+	}
+
+	curControllerRef := metav1.GetControllerOf(curPod)
+	oldControllerRef := metav1.GetControllerOf(oldPod)
+	controllerRefChanged := !reflect.DeepEqual(curControllerRef, oldControllerRef)
+	if controllerRefChanged && oldControllerRef != nil {
+		// The ControllerRef was changed. Sync the old controller, if any.
+		if job := jc.resolveControllerRef(oldPod.Namespace, oldControllerRef); job != nil {
+			log.Infof("pod ControllerRef updated: %v, %v", curPod, oldPod)
+			jc.enqueueJob(job)
+		}
+	}
+
+	// If it has a ControllerRef, that's all that matters.
+	if curControllerRef != nil {
+		job := jc.resolveControllerRef(curPod.Namespace, curControllerRef)
+		if job == nil {
+			return
+		}
+		log.Infof("pod has a ControllerRef: %v, %v", curPod, oldPod)
+		jc.enqueueJob(job)
+		return
+	}
+}
+
+// DeletePod enqueues the job that manages a deleted pod and updates its expectations.
+// obj could be an *v1.Pod, or a DeletionFinalStateUnknown marker item.
+func (jc *JobController) DeletePod(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+
+	// When a delete is dropped, the relist will notice a pod in the store not
+	// in the list, leading to the insertion of a tombstone object which contains
+	// the deleted key/value. Note that this value might be stale. If the pod
+	// changed labels the new job will not be woken up till the periodic resync.
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %+v", obj))
+			return
+		}
+		pod, ok = tombstone.Obj.(*v1.Pod)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a pod %+v", obj))
+			return
+		}
+	}
+
+	controllerRef := metav1.GetControllerOf(pod)
+	if controllerRef == nil {
+		// No controller should care about orphans being deleted.
+		return
+	}
+	job := jc.resolveControllerRef(pod.Namespace, controllerRef)
+	if job == nil {
+		return
+	}
+	jobKey, err := KeyFunc(job)
+	if err != nil {
+		return
+	}
+
+	rtype, ok := pod.Labels[jc.Controller.GetReplicaTypeLabelKey()]
+	if !ok {
+		LoggerForJob(job).Infof("This pod maybe not created by %s", jc.Controller.ControllerName())
+		return
+	}
+
+	expectationPodsKey := GenExpectationPodsKey(jobKey, rtype)
+
+	jc.Expectations.DeletionObserved(expectationPodsKey)
+	jc.enqueueJob(job)
+}
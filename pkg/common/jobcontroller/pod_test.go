@@ -0,0 +1,91 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobcontroller
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestPod(name, rtype, index string) *v1.Pod {
+	labels := map[string]string{
+		"test-replica-type": rtype,
+	}
+	if index != "" {
+		labels["test-replica-index"] = index
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			Labels:    labels,
+		},
+	}
+}
+
+func TestFilterPodsForReplicaType(t *testing.T) {
+	jc := newTestJobController()
+	pods := []*v1.Pod{
+		newTestPod("master-0", "master", "0"),
+		newTestPod("worker-0", "worker", "0"),
+		newTestPod("worker-1", "worker", "1"),
+	}
+	filtered := jc.FilterPodsForReplicaType(pods, "worker")
+	if len(filtered) != 2 || filtered[0].Name != "worker-0" || filtered[1].Name != "worker-1" {
+		t.Errorf("Expected the 2 worker pods, got %v", filtered)
+	}
+}
+
+func TestGetPodSlices(t *testing.T) {
+	jc := newTestJobController()
+	pods := []*v1.Pod{
+		newTestPod("worker-0", "worker", "0"),
+		newTestPod("worker-0-old", "worker", "0"),
+		newTestPod("worker-2", "worker", "2"),
+		newTestPod("worker-3", "worker", "3"),
+		newTestPod("worker-bad", "worker", "bad"),
+		newTestPod("worker-none", "worker", ""),
+	}
+	podSlices := jc.GetPodSlices(pods, 3, log.NewEntry(log.StandardLogger()))
+	if len(podSlices) != 3 {
+		t.Fatalf("Expected 3 slices, got %d", len(podSlices))
+	}
+	for index, expected := range []int{2, 0, 1} {
+		if len(podSlices[index]) != expected {
+			t.Errorf("Expected %d pods with index %d, got %d", expected, index, len(podSlices[index]))
+		}
+	}
+}
+
+func TestSetPodTemplate(t *testing.T) {
+	jc := newTestJobController()
+	podTemplate := &v1.PodTemplateSpec{}
+	if err := jc.SetPodTemplate(newTestJob(), "worker", "1", 2, podTemplate); err != nil {
+		t.Fatalf("Failed to set the pod template: %v", err)
+	}
+	if podTemplate.Name != "test-job-worker-1-2" {
+		t.Errorf("Expected the name of the attempt, got %s", podTemplate.Name)
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: podTemplate.Labels}}
+	if podTemplate.Labels["test-replica-index"] != "1" || jc.GetPodAttempt(pod) != 2 {
+		t.Errorf("Expected the index and the attempt in the labels, got %v", podTemplate.Labels)
+	}
+	if attempt := jc.GetPodAttempt(newTestPod("worker-1", "worker", "1")); attempt != 0 {
+		t.Errorf("Expected attempt 0 for a pod without attempt, got %d", attempt)
+	}
+}
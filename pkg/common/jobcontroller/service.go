@@ -0,0 +1,211 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobcontroller
+
+import (
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	"github.com/kubeflow/tf-operator/pkg/control"
+)
+
+// GetServicesForJob returns the set of services that this job should manage.
+// It also reconciles ControllerRef by adopting/orphaning.
+// Note that the returned services are pointers into the cache.
+func (jc *JobController) GetServicesForJob(job Job) ([]*v1.Service, error) {
+	// Create selector
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels: jc.GenLabels(job.GetName()),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("couldn't convert Job selector: %v", err)
+	}
+	// List all services to include those that don't match the selector anymore
+	// but have a ControllerRef pointing to this controller.
+	services, err := jc.ServiceLister.Services(job.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	// If any adoptions are attempted, we should first recheck for deletion
+	// with an uncached quorum read sometime after listing services.
+	canAdoptFunc := jc.recheckDeletionTimestamp(job)
+	cm := control.NewServiceControllerRefManager(jc.ServiceControl, job, selector, jc.Controller.GetAPIGroupVersionKind(), canAdoptFunc)
+	return cm.ClaimServices(services)
+}
+
+// FilterServicesForReplicaType returns service belong to a replica type.
+func (jc *JobController) FilterServicesForReplicaType(services []*v1.Service, replicaType string) []*v1.Service {
+	var result []*v1.Service
+
+	replicaSelector := &metav1.LabelSelector{
+		MatchLabels: make(map[string]string),
+	}
+
+	replicaSelector.MatchLabels[jc.Controller.GetReplicaTypeLabelKey()] = replicaType
+
+	for _, service := range services {
+		selector, _ := metav1.LabelSelectorAsSelector(replicaSelector)
+		if !selector.Matches(labels.Set(service.Labels)) {
+			continue
+		}
+		result = append(result, service)
+	}
+	return result
+}
+
+// GetServiceSlices returns a slice, which element is the slice of service.
+// Assume the return object is serviceSlices, then serviceSlices[i] is an
+// array of pointers to services corresponding to Services for replica i.
+func (jc *JobController) GetServiceSlices(services []*v1.Service, replicas int, logger *log.Entry) [][]*v1.Service {
+	indexLabel := jc.Controller.GetReplicaIndexLabelKey()
+	serviceSlices := make([][]*v1.Service, replicas)
+	for _, service := range services {
+		if _, ok := service.Labels[indexLabel]; !ok {
+			logger.Warning("The service do not have the index label.")
+			continue
+		}
+		index, err := strconv.Atoi(service.Labels[indexLabel])
+		if err != nil {
+			logger.Warningf("Error when strconv.Atoi: %v", err)
+			continue
+		}
+		if index < 0 || index >= replicas {
+			logger.Warningf("The label index is not expected: %d", index)
+		} else {
+			serviceSlices[index] = append(serviceSlices[index], service)
+		}
+	}
+	return serviceSlices
+}
+
+// CreateNewService creates the service of the pod of the given replica type
+// and index, see GenService.
+func (jc *JobController) CreateNewService(job Job, rt, index string, port int32) error {
+	jobKey, err := KeyFunc(job)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for job object %#v: %v", job, err))
+		return err
+	}
+
+	expectationServicesKey := GenExpectationServicesKey(jobKey, rt)
+	err = jc.Expectations.ExpectCreations(expectationServicesKey, 1)
+	if err != nil {
+		return err
+	}
+
+	// Create OwnerReference.
+	controllerRef := jc.GenOwnerReference(job)
+
+	service := jc.GenService(job, rt, index, port)
+
+	err = jc.ServiceControl.CreateServicesWithControllerRef(job.GetNamespace(), service, job, controllerRef)
+	if err != nil && errors.IsTimeout(err) {
+		// Service is created but its initialization has timed out.
+		// If the initialization is successful eventually, the
+		// controller will observe the creation via the informer.
+		// If the initialization fails, or if the service keeps
+		// uninitialized for a long time, the informer will not
+		// receive any update, and the controller will create a new
+		// service when the expectation expires.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GenService returns the headless service of the pod of the given replica
+// type and index, exposing the given port.
+func (jc *JobController) GenService(job Job, rt, index string, port int32) *v1.Service {
+	// Append the replica type and index labels.
+	labels := jc.GenLabels(job.GetName())
+	labels[jc.Controller.GetReplicaTypeLabelKey()] = rt
+	labels[jc.Controller.GetReplicaIndexLabelKey()] = index
+
+	service := &v1.Service{
+		Spec: v1.ServiceSpec{
+			ClusterIP: "None",
+			Selector:  labels,
+			Ports: []v1.ServicePort{
+				{
+					Name: jc.Controller.GetDefaultContainerPortName(),
+					Port: port,
+				},
+			},
+		},
+	}
+
+	service.Name = GenGeneralName(job.GetName(), rt, index)
+	service.Labels = labels
+
+	return service
+}
+
+// AddService enqueues the job that manages a created service and updates its expectations.
+func (jc *JobController) AddService(obj interface{}) {
+	service := obj.(*v1.Service)
+	if service.DeletionTimestamp != nil {
+		// on a restart of the controller controller, it's possible a new service shows up in a state that
+		// is already pending deletion. Prevent the service from being a creation observation.
+		return
+	}
+
+	// If it has a ControllerRef, that's all that matters.
+	if controllerRef := metav1.GetControllerOf(service); controllerRef != nil {
+		job := jc.resolveControllerRef(service.Namespace, controllerRef)
+		if job == nil {
+			return
+		}
+
+		jobKey, err := KeyFunc(job)
+		if err != nil {
+			return
+		}
+
+		rtype, ok := service.Labels[jc.Controller.GetReplicaTypeLabelKey()]
+		if !ok {
+			log.Infof("This service maybe not created by %s", jc.Controller.ControllerName())
+			return
+		}
+
+		expectationServicesKey := GenExpectationServicesKey(jobKey, rtype)
+
+		jc.Expectations.CreationObserved(expectationServicesKey)
+		jc.enqueueJob(job)
+
+		return
+	}
+
+}
+
+// UpdateService figures out what job manages an updated service and wakes it up.
+func (jc *JobController) UpdateService(old, cur interface{}) {
+	// TODO(CPH): handle this gracefully.
+}
+
+// DeleteService enqueues the job that manages a deleted service and updates its expectations.
+// obj could be an *v1.Service, or a DeletionFinalStateUnknown marker item.
+func (jc *JobController) DeleteService(obj interface{}) {
+	// TODO(CPH): handle this gracefully.
+}
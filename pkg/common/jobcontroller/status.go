@@ -0,0 +1,123 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobcontroller
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	common "github.com/kubeflow/tf-operator/pkg/apis/common/v1alpha2"
+)
+
+// ReplicaCounts are the numbers of pods of a replica type of a job by phase.
+type ReplicaCounts struct {
+	// Replicas is the desired number of pods.
+	Replicas int
+
+	Active    int
+	Succeeded int
+	Failed    int
+}
+
+// ReplicaConditions returns the conditions of the job resulting from the pods
+// of the replica type, in the order they are to be set. Only the replica type
+// deciding of the state of the job results in conditions, see
+// ControllerInterface.IsMasterRole. failedReason and failedMessage are the
+// ones of the failed condition.
+func (jc *JobController) ReplicaConditions(job Job, rtype string, counts ReplicaCounts, failedReason, failedMessage string) []common.JobCondition {
+	if !jc.Controller.IsMasterRole(job, rtype) {
+		return nil
+	}
+	kind := jc.Controller.GetAPIGroupVersionKind().Kind
+	var conditions []common.JobCondition
+
+	// Some replicas are still running, leave a running condition.
+	if counts.Active > 0 {
+		msg := fmt.Sprintf("%s %s is running.", kind, job.GetName())
+		conditions = append(conditions, NewCondition(common.JobRunning, kind+"Running", msg))
+	}
+
+	// All replicas are succeeded, leave a succeeded condition.
+	if counts.Replicas-counts.Succeeded == 0 {
+		msg := fmt.Sprintf("%s %s is successfully completed.", kind, job.GetName())
+		conditions = append(conditions, NewCondition(common.JobSucceeded, kind+"Succeeded", msg))
+	}
+
+	// Some replicas are failed, leave a failed condition.
+	if counts.Failed > 0 {
+		conditions = append(conditions, NewCondition(common.JobFailed, failedReason, failedMessage))
+	}
+	return conditions
+}
+
+// NewCondition creates a new job condition.
+func NewCondition(conditionType common.JobConditionType, reason, message string) common.JobCondition {
+	return common.JobCondition{
+		Type:               conditionType,
+		Status:             v1.ConditionTrue,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetCondition returns the condition with the provided type.
+func GetCondition(conditions []common.JobCondition, condType common.JobConditionType) *common.JobCondition {
+	for i := range conditions {
+		c := conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// SetCondition updates the conditions to include the provided condition,
+// and returns true if they have changed.
+// If the condition that we are about to add already exists
+// and has the same status and reason then we are not going to update.
+func SetCondition(conditions *[]common.JobCondition, condition common.JobCondition) bool {
+	currentCond := GetCondition(*conditions, condition.Type)
+
+	// Do nothing if condition doesn't change
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason {
+		return false
+	}
+
+	// Do not update lastTransitionTime if the status of the condition doesn't change.
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+
+	// Append the updated condition to the conditions.
+	newConditions := FilterOutCondition(*conditions, condition.Type)
+	*conditions = append(newConditions, condition)
+	return true
+}
+
+// FilterOutCondition returns a new slice of job conditions without conditions with the provided type.
+func FilterOutCondition(conditions []common.JobCondition, condType common.JobConditionType) []common.JobCondition {
+	var newConditions []common.JobCondition
+	for _, c := range conditions {
+		if c.Type == condType {
+			continue
+		}
+		newConditions = append(newConditions, c)
+	}
+	return newConditions
+}
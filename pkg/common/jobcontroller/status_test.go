@@ -0,0 +1,111 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobcontroller
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+
+	common "github.com/kubeflow/tf-operator/pkg/apis/common/v1alpha2"
+)
+
+func TestReplicaConditions(t *testing.T) {
+	type testCase struct {
+		description string
+		rtype       string
+		counts      ReplicaCounts
+		expected    []common.JobConditionType
+	}
+	testCases := []testCase{
+		{
+			description: "Worker replicas do not decide of the state of the job",
+			rtype:       "worker",
+			counts:      ReplicaCounts{Replicas: 2, Failed: 2},
+			expected:    nil,
+		},
+		{
+			description: "Master is running",
+			rtype:       "master",
+			counts:      ReplicaCounts{Replicas: 1, Active: 1},
+			expected:    []common.JobConditionType{common.JobRunning},
+		},
+		{
+			description: "Master is succeeded",
+			rtype:       "master",
+			counts:      ReplicaCounts{Replicas: 1, Succeeded: 1},
+			expected:    []common.JobConditionType{common.JobSucceeded},
+		},
+		{
+			description: "Master is failed",
+			rtype:       "master",
+			counts:      ReplicaCounts{Replicas: 1, Failed: 1},
+			expected:    []common.JobConditionType{common.JobFailed},
+		},
+	}
+
+	jc := newTestJobController()
+	for _, c := range testCases {
+		conditions := jc.ReplicaConditions(newTestJob(), c.rtype, c.counts, "TestJobFailed", "TestJob test-job is failed.")
+		if len(conditions) != len(c.expected) {
+			t.Errorf("%s: Expected conditions %v, got %+v", c.description, c.expected, conditions)
+			continue
+		}
+		for i, condition := range conditions {
+			if condition.Type != c.expected[i] || condition.Status != v1.ConditionTrue {
+				t.Errorf("%s: Expected condition %s, got %+v", c.description, c.expected[i], condition)
+			}
+		}
+	}
+}
+
+func TestReplicaConditionsMessages(t *testing.T) {
+	jc := newTestJobController()
+	conditions := jc.ReplicaConditions(newTestJob(), "master", ReplicaCounts{Replicas: 1, Active: 1}, "", "")
+	if len(conditions) != 1 {
+		t.Fatalf("Expected a running condition, got %+v", conditions)
+	}
+	if conditions[0].Reason != "TestJobRunning" || conditions[0].Message != "TestJob test-job is running." {
+		t.Errorf("Expected the reason and message of the kind of the job, got %+v", conditions[0])
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	var conditions []common.JobCondition
+	if !SetCondition(&conditions, NewCondition(common.JobCreated, "TestJobCreated", "")) {
+		t.Errorf("Expected the created condition to be added")
+	}
+	if !SetCondition(&conditions, NewCondition(common.JobRunning, "TestJobRunning", "")) {
+		t.Errorf("Expected the running condition to be added")
+	}
+	if SetCondition(&conditions, NewCondition(common.JobRunning, "TestJobRunning", "")) {
+		t.Errorf("Expected the same running condition not to change the conditions")
+	}
+	if !SetCondition(&conditions, NewCondition(common.JobRunning, "TestJobRestarted", "")) {
+		t.Errorf("Expected the running condition with another reason to be updated")
+	}
+	if len(conditions) != 2 {
+		t.Fatalf("Expected 2 conditions, got %+v", conditions)
+	}
+	if conditions[1].Type != common.JobRunning || conditions[1].Reason != "TestJobRestarted" {
+		t.Errorf("Expected the updated running condition last, got %+v", conditions[1])
+	}
+	if c := GetCondition(conditions, common.JobCreated); c == nil || c.Reason != "TestJobCreated" {
+		t.Errorf("Expected the created condition, got %+v", c)
+	}
+	if c := GetCondition(FilterOutCondition(conditions, common.JobCreated), common.JobCreated); c != nil {
+		t.Errorf("Expected the created condition to be filtered out, got %+v", c)
+	}
+}
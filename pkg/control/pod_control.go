@@ -56,7 +56,16 @@ type RealPodControl struct {
 	Recorder   record.EventRecorder
 }
 
-var _ controller.PodControlInterface = &RealPodControl{}
+var _ GracefulPodControlInterface = &RealPodControl{}
+
+// GracefulPodControlInterface is a PodControlInterface which also deletes
+// pods with a given grace period.
+type GracefulPodControlInterface interface {
+	controller.PodControlInterface
+	// DeletePodWithGracePeriod deletes the pod with the grace period in
+	// seconds, 0 deletes it immediately.
+	DeletePodWithGracePeriod(namespace string, podID string, object runtime.Object, gracePeriod int64) error
+}
 
 func getPodsLabelSet(template *v1.PodTemplateSpec) labels.Set {
 	desiredLabels := make(labels.Set)
@@ -150,12 +159,20 @@ func (r RealPodControl) createPods(nodeName, namespace string, template *v1.PodT
 }
 
 func (r RealPodControl) DeletePod(namespace string, podID string, object runtime.Object) error {
+	return r.deletePod(namespace, podID, object, nil)
+}
+
+func (r RealPodControl) DeletePodWithGracePeriod(namespace string, podID string, object runtime.Object, gracePeriod int64) error {
+	return r.deletePod(namespace, podID, object, &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+}
+
+func (r RealPodControl) deletePod(namespace string, podID string, object runtime.Object, options *metav1.DeleteOptions) error {
 	accessor, err := meta.Accessor(object)
 	if err != nil {
 		return fmt.Errorf("object does not have ObjectMeta, %v", err)
 	}
 	glog.V(2).Infof("Controller %v deleting pod %v/%v", accessor.GetName(), namespace, podID)
-	if err := r.KubeClient.CoreV1().Pods(namespace).Delete(podID, nil); err != nil {
+	if err := r.KubeClient.CoreV1().Pods(namespace).Delete(podID, options); err != nil {
 		r.Recorder.Eventf(object, v1.EventTypeWarning, FailedDeletePodReason, "Error deleting: %v", err)
 		return fmt.Errorf("unable to delete pods: %v", err)
 	} else {
@@ -163,3 +180,22 @@ func (r RealPodControl) DeletePod(namespace string, podID string, object runtime
 	}
 	return nil
 }
+
+// FakeGracefulPodControl is a FakePodControl which records the grace periods
+// of the pods deleted with one.
+type FakeGracefulPodControl struct {
+	controller.FakePodControl
+	GracePeriods map[string]int64
+}
+
+var _ GracefulPodControlInterface = &FakeGracefulPodControl{}
+
+func (f *FakeGracefulPodControl) DeletePodWithGracePeriod(namespace string, podID string, object runtime.Object, gracePeriod int64) error {
+	f.Lock()
+	if f.GracePeriods == nil {
+		f.GracePeriods = make(map[string]int64)
+	}
+	f.GracePeriods[podID] = gracePeriod
+	f.Unlock()
+	return f.DeletePod(namespace, podID, object)
+}
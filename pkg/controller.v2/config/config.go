@@ -0,0 +1,342 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config provides the configuration file of the v2 tf-operator.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/juju/ratelimit"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// APIVersion is the version of the configuration file.
+	APIVersion = "tf-operator.kubeflow.org/v1alpha2"
+	// Kind is the kind of the configuration file.
+	Kind = "OperatorConfiguration"
+)
+
+// OperatorConfiguration is the configuration of the v2 tf-operator.
+//
+// The accelerators, the namespace overlays, the deletion grace period and
+// the notifications are reloaded when the file changes. The other settings
+// are only read when the operator starts.
+type OperatorConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// ResyncPeriod is how often the informers resync all the objects.
+	// It is set to 30 seconds by default.
+	ResyncPeriod metav1.Duration `json:"resyncPeriod"`
+
+	// LeaderElection configures the election of the active operator.
+	LeaderElection LeaderElectionConfiguration `json:"leaderElection"`
+
+	// RateLimiter configures how fast failed tfjobs are synced again.
+	RateLimiter RateLimiterConfiguration `json:"rateLimiter"`
+
+	// NamespaceOverlays are pod templates merged under the templates of the
+	// replicas of the tfjobs of a namespace, e.g. to add image pull secrets
+	// or tolerations to all the pods of a team. The template of the tfjob
+	// and of its replicas win on conflict.
+	NamespaceOverlays map[string]v1.PodTemplateSpec `json:"namespaceOverlays,omitempty"`
+
+	// Accelerators is a map from the name of an accelerator resource, e.g.
+	// nvidia.com/gpu, to the volumes and env added to the tensorflow
+	// containers which request or are limited to it.
+	Accelerators map[string]AcceleratorConfig `json:"accelerators,omitempty"`
+
+	// DeletionGracePeriod is the grace period of the pods of a deleted tfjob,
+	// which are stopped before its finalizer is removed.
+	// It is set to 30 seconds by default.
+	DeletionGracePeriod metav1.Duration `json:"deletionGracePeriod"`
+
+	// Notifications configures the webhooks notified when the conditions
+	// of the tfjobs change.
+	Notifications NotificationConfiguration `json:"notifications"`
+}
+
+// NotificationConfiguration configures the webhooks notified of the
+// conditions of the tfjobs. A failed notification is retried after an
+// exponential backoff.
+type NotificationConfiguration struct {
+	// Webhooks are the URLs notified for all the tfjobs.
+	Webhooks []string `json:"webhooks,omitempty"`
+	// AllowedHosts are the hosts which the tfjobs may notify with the
+	// kubeflow.org/notification-webhooks annotation. The webhooks of the
+	// annotations are ignored if it is empty.
+	AllowedHosts []string `json:"allowedHosts,omitempty"`
+	// SecretFile is the path to the key signing the payloads with
+	// HMAC-SHA256. The payloads are not signed if it is not set.
+	SecretFile string `json:"secretFile,omitempty"`
+	// Retries is how many times a failed notification is retried.
+	// It is set to 3 by default.
+	Retries int `json:"retries"`
+	// BaseDelay is the backoff before the first retry, doubled after
+	// each one. It is set to 1 second by default.
+	BaseDelay metav1.Duration `json:"baseDelay"`
+	// Timeout is how long a webhook may take to respond.
+	// It is set to 10 seconds by default.
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// LeaderElectionConfiguration configures the leader election.
+type LeaderElectionConfiguration struct {
+	// LeaseDuration is how long non-leaders wait before trying to acquire
+	// the leadership. It is set to 15 seconds by default.
+	LeaseDuration metav1.Duration `json:"leaseDuration"`
+	// RenewDeadline is how long the leader tries to renew its leadership
+	// before giving up. It is set to 5 seconds by default.
+	RenewDeadline metav1.Duration `json:"renewDeadline"`
+	// RetryPeriod is how long the candidates wait between tries.
+	// It is set to 3 seconds by default.
+	RetryPeriod metav1.Duration `json:"retryPeriod"`
+}
+
+// RateLimiterConfiguration configures the rate limiter of the work queue.
+// A tfjob is requeued after an exponential backoff per tfjob, and no faster
+// than the overall rate.
+type RateLimiterConfiguration struct {
+	// BaseDelay is the first backoff of a tfjob. It is set to 5ms by default.
+	BaseDelay metav1.Duration `json:"baseDelay"`
+	// MaxDelay is the maximum backoff of a tfjob. It is set to 1000s by default.
+	MaxDelay metav1.Duration `json:"maxDelay"`
+	// QPS is the overall rate of requeues. It is set to 10 by default.
+	QPS float64 `json:"qps"`
+	// Burst is the overall burst of requeues. It is set to 100 by default.
+	Burst int64 `json:"burst"`
+}
+
+// AcceleratorConfig represents the volumes and the environment variables
+// added to the containers using an accelerator.
+type AcceleratorConfig struct {
+	Volumes []AcceleratorVolume `json:"volumes,omitempty"`
+	EnvVars []v1.EnvVar         `json:"envVars,omitempty"`
+}
+
+// AcceleratorVolume represents a host path that must be mounted into
+// each container that needs to use the accelerator.
+type AcceleratorVolume struct {
+	Name      string `json:"name"`
+	HostPath  string `json:"hostPath"`
+	MountPath string `json:"mountPath"`
+}
+
+// Default returns the default configuration, which matches the behavior
+// of the operator without configuration file.
+func Default() *OperatorConfiguration {
+	return &OperatorConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: APIVersion,
+			Kind:       Kind,
+		},
+		ResyncPeriod: metav1.Duration{Duration: 30 * time.Second},
+		LeaderElection: LeaderElectionConfiguration{
+			LeaseDuration: metav1.Duration{Duration: 15 * time.Second},
+			RenewDeadline: metav1.Duration{Duration: 5 * time.Second},
+			RetryPeriod:   metav1.Duration{Duration: 3 * time.Second},
+		},
+		RateLimiter: RateLimiterConfiguration{
+			BaseDelay: metav1.Duration{Duration: 5 * time.Millisecond},
+			MaxDelay:  metav1.Duration{Duration: 1000 * time.Second},
+			QPS:       10,
+			Burst:     100,
+		},
+		DeletionGracePeriod: metav1.Duration{Duration: 30 * time.Second},
+		Notifications: NotificationConfiguration{
+			Retries:   3,
+			BaseDelay: metav1.Duration{Duration: time.Second},
+			Timeout:   metav1.Duration{Duration: 10 * time.Second},
+		},
+	}
+}
+
+// Parse parses and validates a configuration file.
+// The settings missing from the file keep their default values.
+func Parse(data []byte) (*OperatorConfiguration, error) {
+	c := Default()
+	c.APIVersion = ""
+	c.Kind = ""
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if err := Validate(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate checks that the configuration is valid.
+func Validate(c *OperatorConfiguration) error {
+	if c.APIVersion != APIVersion || c.Kind != Kind {
+		return fmt.Errorf("expected apiVersion %s and kind %s, got %s and %s", APIVersion, Kind, c.APIVersion, c.Kind)
+	}
+	if c.ResyncPeriod.Duration <= 0 {
+		return errors.New("resyncPeriod must be positive")
+	}
+
+	le := c.LeaderElection
+	if le.LeaseDuration.Duration <= 0 || le.RenewDeadline.Duration <= 0 || le.RetryPeriod.Duration <= 0 {
+		return errors.New("the durations of leaderElection must be positive")
+	}
+	if le.LeaseDuration.Duration <= le.RenewDeadline.Duration {
+		return errors.New("leaderElection.leaseDuration must be greater than renewDeadline")
+	}
+	if le.RenewDeadline.Duration <= le.RetryPeriod.Duration {
+		return errors.New("leaderElection.renewDeadline must be greater than retryPeriod")
+	}
+
+	rl := c.RateLimiter
+	if rl.BaseDelay.Duration <= 0 || rl.MaxDelay.Duration < rl.BaseDelay.Duration {
+		return errors.New("rateLimiter.baseDelay must be positive and at most maxDelay")
+	}
+	if rl.QPS <= 0 || rl.Burst <= 0 {
+		return errors.New("rateLimiter.qps and burst must be positive")
+	}
+
+	if c.DeletionGracePeriod.Duration < 0 {
+		return errors.New("deletionGracePeriod must not be negative")
+	}
+
+	n := c.Notifications
+	if n.Retries < 0 || n.BaseDelay.Duration <= 0 || n.Timeout.Duration <= 0 {
+		return errors.New("notifications.retries must not be negative, baseDelay and timeout must be positive")
+	}
+	for _, webhook := range n.Webhooks {
+		if err := ValidateWebhook(webhook); err != nil {
+			return fmt.Errorf("notifications.webhooks is invalid: %v", err)
+		}
+	}
+	for _, host := range n.AllowedHosts {
+		if host == "" {
+			return errors.New("notifications.allowedHosts must not be empty strings")
+		}
+	}
+
+	for namespace := range c.NamespaceOverlays {
+		if namespace == "" {
+			return errors.New("namespaceOverlays must be keyed by namespace")
+		}
+	}
+	for name, accelerator := range c.Accelerators {
+		for _, volume := range accelerator.Volumes {
+			if volume.Name == "" || volume.HostPath == "" || volume.MountPath == "" {
+				return fmt.Errorf("the volumes of accelerator %s must have a name, hostPath and mountPath", name)
+			}
+		}
+		for _, env := range accelerator.EnvVars {
+			if env.Name == "" {
+				return fmt.Errorf("the envVars of accelerator %s must have a name", name)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateWebhook checks that the webhook is an absolute http or https URL.
+func ValidateWebhook(webhook string) error {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("expected an http or https URL, got %q", webhook)
+	}
+	return nil
+}
+
+// NewRateLimiter returns the rate limiter of the work queue.
+func (c *OperatorConfiguration) NewRateLimiter() workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(c.RateLimiter.BaseDelay.Duration, c.RateLimiter.MaxDelay.Duration),
+		&workqueue.BucketRateLimiter{Bucket: ratelimit.NewBucketWithRate(c.RateLimiter.QPS, c.RateLimiter.Burst)},
+	)
+}
+
+// ConfigureAccelerators adds the volumes and env of the accelerators used by
+// the container to the pod template. Volumes already in the template are kept.
+func (c *OperatorConfiguration) ConfigureAccelerators(template *v1.PodTemplateSpec, containerName string) {
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if container.Name != containerName {
+			continue
+		}
+		for _, resources := range []v1.ResourceList{container.Resources.Limits, container.Resources.Requests} {
+			for name := range resources {
+				accelerator, ok := c.Accelerators[string(name)]
+				if !ok {
+					continue
+				}
+				for _, volume := range accelerator.Volumes {
+					if !hasVolume(template.Spec.Volumes, volume.Name) {
+						template.Spec.Volumes = append(template.Spec.Volumes, v1.Volume{
+							Name: volume.Name,
+							VolumeSource: v1.VolumeSource{
+								HostPath: &v1.HostPathVolumeSource{
+									Path: volume.HostPath,
+								},
+							},
+						})
+					}
+					if !hasVolumeMount(container.VolumeMounts, volume.Name) {
+						container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+							Name:      volume.Name,
+							MountPath: volume.MountPath,
+						})
+					}
+				}
+				for _, env := range accelerator.EnvVars {
+					if !hasEnv(container.Env, env.Name) {
+						container.Env = append(container.Env, env)
+					}
+				}
+			}
+		}
+		return
+	}
+}
+
+func hasVolume(volumes []v1.Volume, name string) bool {
+	for _, volume := range volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVolumeMount(mounts []v1.VolumeMount, name string) bool {
+	for _, mount := range mounts {
+		if mount.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEnv(env []v1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const testConfig = `
+apiVersion: tf-operator.kubeflow.org/v1alpha2
+kind: OperatorConfiguration
+resyncPeriod: 1m
+leaderElection:
+  leaseDuration: 30s
+namespaceOverlays:
+  research:
+    spec:
+      containers: []
+      imagePullSecrets:
+      - name: registry
+accelerators:
+  nvidia.com/gpu:
+    volumes:
+    - name: nvidia-libraries
+      hostPath: /home/kubernetes/bin/nvidia/lib
+      mountPath: /usr/local/nvidia/lib
+    envVars:
+    - name: LD_LIBRARY_PATH
+      value: /usr/local/nvidia/lib
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c.ResyncPeriod.Duration != time.Minute || c.LeaderElection.LeaseDuration.Duration != 30*time.Second {
+		t.Errorf("Expected the settings of the file, got %+v", c)
+	}
+	// The settings missing from the file are defaulted.
+	defaults := Default()
+	if c.LeaderElection.RenewDeadline != defaults.LeaderElection.RenewDeadline || c.RateLimiter != defaults.RateLimiter {
+		t.Errorf("Expected the default settings, got %+v", c)
+	}
+	if len(c.NamespaceOverlays["research"].Spec.ImagePullSecrets) != 1 || len(c.Accelerators["nvidia.com/gpu"].Volumes) != 1 {
+		t.Errorf("Expected the overlays and accelerators of the file, got %+v", c)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(Default()); err != nil {
+		t.Errorf("Expected the default configuration to be valid, got %v", err)
+	}
+
+	testCases := map[string]func(c *OperatorConfiguration){
+		"wrong version": func(c *OperatorConfiguration) {
+			c.APIVersion = "v1"
+		},
+		"no resync": func(c *OperatorConfiguration) {
+			c.ResyncPeriod.Duration = 0
+		},
+		"renew deadline longer than lease": func(c *OperatorConfiguration) {
+			c.LeaderElection.RenewDeadline.Duration = time.Minute
+		},
+		"retry period longer than renew deadline": func(c *OperatorConfiguration) {
+			c.LeaderElection.RetryPeriod.Duration = 10 * time.Second
+		},
+		"max delay shorter than base delay": func(c *OperatorConfiguration) {
+			c.RateLimiter.MaxDelay.Duration = time.Millisecond
+		},
+		"no qps": func(c *OperatorConfiguration) {
+			c.RateLimiter.QPS = 0
+		},
+		"negative deletion grace period": func(c *OperatorConfiguration) {
+			c.DeletionGracePeriod.Duration = -time.Second
+		},
+		"webhook without scheme": func(c *OperatorConfiguration) {
+			c.Notifications.Webhooks = []string{"pipeline.example.com/tfjobs"}
+		},
+		"webhook without host": func(c *OperatorConfiguration) {
+			c.Notifications.Webhooks = []string{"https:///tfjobs"}
+		},
+		"no notification timeout": func(c *OperatorConfiguration) {
+			c.Notifications.Timeout.Duration = 0
+		},
+		"accelerator volume without path": func(c *OperatorConfiguration) {
+			c.Accelerators = map[string]AcceleratorConfig{
+				"nvidia.com/gpu": {Volumes: []AcceleratorVolume{{Name: "lib"}}},
+			}
+		},
+	}
+	for name, modify := range testCases {
+		c := Default()
+		modify(c)
+		if err := Validate(c); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestConfigureAccelerators(t *testing.T) {
+	c, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	gpu := v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+	template := &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:      "tensorflow",
+					Resources: v1.ResourceRequirements{Limits: gpu, Requests: gpu},
+				},
+				{
+					Name:      "sidecar",
+					Resources: v1.ResourceRequirements{Limits: gpu},
+				},
+			},
+		},
+	}
+
+	c.ConfigureAccelerators(template, "tensorflow")
+
+	container := template.Spec.Containers[0]
+	if len(template.Spec.Volumes) != 1 || template.Spec.Volumes[0].HostPath.Path != "/home/kubernetes/bin/nvidia/lib" {
+		t.Errorf("Expected the volume of the accelerator once, got %+v", template.Spec.Volumes)
+	}
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != "/usr/local/nvidia/lib" {
+		t.Errorf("Expected the volume mount of the accelerator once, got %+v", container.VolumeMounts)
+	}
+	if len(container.Env) != 1 || container.Env[0].Name != "LD_LIBRARY_PATH" {
+		t.Errorf("Expected the env of the accelerator once, got %+v", container.Env)
+	}
+	if sidecar := template.Spec.Containers[1]; len(sidecar.VolumeMounts) != 0 || len(sidecar.Env) != 0 {
+		t.Errorf("Expected only the named container to be configured, got %+v", sidecar)
+	}
+}
+
+func TestStoreReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-operator-config")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(testConfig), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.Get().ResyncPeriod.Duration != time.Minute {
+		t.Errorf("Expected the configuration of the file, got %+v", s.Get())
+	}
+
+	// An invalid file is not loaded.
+	if err := ioutil.WriteFile(path, []byte("kind: Pod"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := s.reload(); err == nil {
+		t.Errorf("Expected an error for an invalid file")
+	}
+	if s.Get().ResyncPeriod.Duration != time.Minute {
+		t.Errorf("Expected the previous configuration to be kept, got %+v", s.Get())
+	}
+
+	if err := ioutil.WriteFile(path, []byte("apiVersion: "+APIVersion+"\nkind: "+Kind+"\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := s.reload(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(s.Get().Accelerators) != 0 {
+		t.Errorf("Expected the new configuration, got %+v", s.Get())
+	}
+
+	// Without a file the defaults are used.
+	s, err = NewStore("")
+	if err != nil || s.Get().ResyncPeriod != Default().ResyncPeriod {
+		t.Errorf("Expected the default configuration, got %+v, %v", s.Get(), err)
+	}
+}
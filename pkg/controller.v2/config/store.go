@@ -0,0 +1,110 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// reloadPeriod is how often the configuration file is checked for changes.
+// The file is polled rather than watched since the ConfigMaps mounted in
+// pods are updated by swapping symlinks.
+const reloadPeriod = 10 * time.Second
+
+// Store holds the current configuration of the operator.
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	data    []byte
+	current *OperatorConfiguration
+}
+
+// NewStore loads the configuration file at path. If path is empty the
+// default configuration is used.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		current: Default(),
+	}
+	if path == "" {
+		return s, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	s.data = data
+	s.current = c
+	return s, nil
+}
+
+// Get returns the current configuration. It must not be modified.
+func (s *Store) Get() *OperatorConfiguration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Run reloads the configuration file when it changes, until stopCh is closed.
+// An invalid file is logged and the previous configuration is kept.
+func (s *Store) Run(stopCh <-chan struct{}) {
+	if s.path == "" {
+		return
+	}
+	wait.Until(func() {
+		if err := s.reload(); err != nil {
+			log.Errorf("Failed to reload the configuration file %s: %v", s.path, err)
+		}
+	}, reloadPeriod, stopCh)
+}
+
+func (s *Store) reload() error {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	s.mu.RLock()
+	unchanged := bytes.Equal(data, s.data)
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	c, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	log.Infof("Reloaded the configuration file %s", s.path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current.ResyncPeriod != c.ResyncPeriod || s.current.LeaderElection != c.LeaderElection || s.current.RateLimiter != c.RateLimiter {
+		log.Warnf("The resync period, leader election and rate limiter settings are only applied when the operator restarts")
+	}
+	s.data = data
+	s.current = c
+	return nil
+}
@@ -27,12 +27,9 @@ import (
 	kubeinformers "k8s.io/client-go/informers"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
-	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
-	"k8s.io/kubernetes/pkg/controller"
 
 	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
 	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
@@ -40,15 +37,21 @@ import (
 	tfjobinformers "github.com/kubeflow/tf-operator/pkg/client/informers/externalversions"
 	tfjobinformersv1alpha2 "github.com/kubeflow/tf-operator/pkg/client/informers/externalversions/kubeflow/v1alpha2"
 	tfjoblisters "github.com/kubeflow/tf-operator/pkg/client/listers/kubeflow/v1alpha2"
-	"github.com/kubeflow/tf-operator/pkg/control"
+	"github.com/kubeflow/tf-operator/pkg/common/jobcontroller"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/config"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/notification"
 )
 
 const (
 	controllerName = "tf-operator"
 
 	// labels for pods and servers.
-	tfReplicaTypeLabel  = "tf-replica-type"
-	tfReplicaIndexLabel = "tf-replica-index"
+	tfReplicaTypeLabel    = "tf-replica-type"
+	tfReplicaIndexLabel   = "tf-replica-index"
+	tfReplicaAttemptLabel = "tf-replica-attempt"
+
+	// notificationWorkers is how many notifications are delivered concurrently.
+	notificationWorkers = 4
 )
 
 var (
@@ -63,6 +66,7 @@ var (
 	// DefaultTFJobControllerConfiguration is the suggested tf-operator configuration for production.
 	DefaultTFJobControllerConfiguration = TFJobControllerConfiguration{
 		ReconcilerSyncLoopPeriod: metav1.Duration{Duration: 15 * time.Second},
+		NodeNotReadyTimeout:      metav1.Duration{Duration: 5 * time.Minute},
 	}
 )
 
@@ -76,25 +80,29 @@ type TFJobControllerConfiguration struct {
 	// and up to 5 minutes to reduce idle loop.
 	// e.g. 15s, 30s, 60s, 120s...
 	ReconcilerSyncLoopPeriod metav1.Duration
+
+	// NodeNotReadyTimeout is how long the node of a pod may be not ready
+	// before the pod is considered lost and is recreated.
+	// It is set to 5 minutes by default.
+	NodeNotReadyTimeout metav1.Duration
 }
 
 // TFJobController is the type for TFJob Controller, which manages
 // the lifecycle of TFJobs.
 type TFJobController struct {
-	config TFJobControllerConfiguration
+	*jobcontroller.JobController
 
-	// podControl is used to add or delete pods.
-	podControl controller.PodControlInterface
-
-	// serviceControl is used to add or delete services.
-	serviceControl control.ServiceControlInterface
+	config TFJobControllerConfiguration
 
-	// kubeClientSet is a standard kubernetes clientset.
-	kubeClientSet kubeclientset.Interface
+	// configStore holds the configuration file of the operator.
+	configStore *config.Store
 
 	// tfJobClientSet is a clientset for CRD TFJob.
 	tfJobClientSet tfjobclientset.Interface
 
+	// notifier posts the changed conditions of the tfjobs to the webhooks.
+	notifier *notification.Notifier
+
 	// To allow injection of syncTFJob for testing.
 	syncHandler func(tfJobKey string) (bool, error)
 
@@ -104,53 +112,19 @@ type TFJobController struct {
 	// tfJobInformer is a temporary field for unstructured informer support.
 	tfJobInformer cache.SharedIndexInformer
 
-	// Listers for TFJob, Pod and Service
+	// Listers for TFJob and Node, the ones of Pod and Service are in the
+	// JobController.
 	// tfJobLister can list/get tfjobs from the shared informer's store.
 	tfJobLister tfjoblisters.TFJobLister
 
-	// podLister can list/get pods from the shared informer's store.
-	podLister corelisters.PodLister
-
-	// serviceLister can list/get services from the shared informer's store.
-	serviceLister corelisters.ServiceLister
+	// nodeLister can list/get nodes from the shared informer's store.
+	nodeLister corelisters.NodeLister
 
 	// tfJobInformerSynced returns true if the tfjob store has been synced at least once.
 	tfJobInformerSynced cache.InformerSynced
 
-	// podInformerSynced returns true if the pod store has been synced at least once.
-	podInformerSynced cache.InformerSynced
-
-	// serviceInformerSynced returns true if the service store has been synced at least once.
-	serviceInformerSynced cache.InformerSynced
-
-	// A TTLCache of pod/services creates/deletes each tfjob expects to see
-	// We use TFJob namespace/name + TFReplicaType + pods/services as an expectation key,
-	// For example, there is a TFJob with namespace "tf-operator" and name "tfjob-abc":
-	// {
-	//     "PS": {
-	//         "Replicas": 2,
-	//     },
-	//     "Worker": {
-	//         "Replicas": 4,
-	//     }
-	// }
-	// We will create 4 expectations:
-	// - "tf-operator/tfjob-abc/ps/services", expects 2 adds.
-	// - "tf-operator/tfjob-abc/ps/pods", expects 2 adds.
-	// - "tf-operator/tfjob-abc/worker/services", expects 4 adds.
-	// - "tf-operator/tfjob-abc/worker/pods", expects 4 adds.
-	expectations controller.ControllerExpectationsInterface
-
-	// workQueue is a rate limited work queue. This is used to queue work to be
-	// processed instead of performing it as soon as a change happens. This
-	// means we can ensure we only process a fixed amount of resources at a
-	// time, and makes it easy to ensure we are never processing the same item
-	// simultaneously in two different workers.
-	workQueue workqueue.RateLimitingInterface
-
-	// recorder is an event recorder for recording Event resources to the
-	// Kubernetes API.
-	recorder record.EventRecorder
+	// nodeInformerSynced returns true if the node store has been synced at least once.
+	nodeInformerSynced cache.InformerSynced
 }
 
 // NewTFJobController returns a new TFJob controller.
@@ -162,36 +136,20 @@ func NewTFJobController(
 	kubeInformerFactory kubeinformers.SharedInformerFactory,
 	// This field is not used now but we keep it since it will be used
 	// after we support CRD validation.
-	tfJobInformerFactory tfjobinformers.SharedInformerFactory) *TFJobController {
+	tfJobInformerFactory tfjobinformers.SharedInformerFactory,
+	configStore *config.Store) *TFJobController {
 
 	tfjobscheme.AddToScheme(scheme.Scheme)
 
-	log.Debug("Creating event broadcaster")
-	eventBroadcaster := record.NewBroadcaster()
-	eventBroadcaster.StartLogging(log.Infof)
-	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
-	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: controllerName})
-
-	realPodControl := control.RealPodControl{
-		KubeClient: kubeClientSet,
-		Recorder:   eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: controllerName}),
-	}
-
-	realServiceControl := control.RealServiceControl{
-		KubeClient: kubeClientSet,
-		Recorder:   eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: controllerName}),
-	}
-
 	// Create new TFJobController.
 	tc := &TFJobController{
-		podControl:     realPodControl,
-		serviceControl: realServiceControl,
-		kubeClientSet:  kubeClientSet,
+		config:         DefaultTFJobControllerConfiguration,
+		configStore:    configStore,
 		tfJobClientSet: tfJobClientSet,
-		expectations:   controller.NewControllerExpectations(),
-		workQueue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), tfv1alpha2.Plural),
-		recorder:       recorder,
+		notifier:       notification.NewNotifier(),
 	}
+	workQueue := workqueue.NewNamedRateLimitingQueue(configStore.Get().NewRateLimiter(), tfv1alpha2.Plural)
+	tc.JobController = jobcontroller.NewJobController(tc, kubeClientSet, kubeInformerFactory, workQueue)
 
 	// Set sync handler.
 	tc.syncHandler = tc.syncTFJob
@@ -210,31 +168,10 @@ func NewTFJobController(
 	tc.tfJobLister = tfJobInformer.Lister()
 	tc.tfJobInformerSynced = tfJobInformer.Informer().HasSynced
 
-	// Create pod informer.
-	podInformer := kubeInformerFactory.Core().V1().Pods()
-
-	// Set up an event handler for when pod resources change
-	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    tc.addPod,
-		UpdateFunc: tc.updatePod,
-		DeleteFunc: tc.deletePod,
-	})
-
-	tc.podLister = podInformer.Lister()
-	tc.podInformerSynced = podInformer.Informer().HasSynced
-
-	// Create service informer.
-	serviceInformer := kubeInformerFactory.Core().V1().Services()
-
-	// Set up an event handler for when service resources change.
-	serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    tc.addService,
-		UpdateFunc: tc.updateService,
-		DeleteFunc: tc.deleteService,
-	})
-
-	tc.serviceLister = serviceInformer.Lister()
-	tc.serviceInformerSynced = serviceInformer.Informer().HasSynced
+	// Create node informer, used to find the pods lost with their node.
+	nodeInformer := kubeInformerFactory.Core().V1().Nodes()
+	tc.nodeLister = nodeInformer.Lister()
+	tc.nodeInformerSynced = nodeInformer.Informer().HasSynced
 
 	return tc
 }
@@ -245,7 +182,7 @@ func NewTFJobController(
 // workers to finish processing their current work items.
 func (tc *TFJobController) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
-	defer tc.workQueue.ShutDown()
+	defer tc.WorkQueue.ShutDown()
 
 	// Start the informer factories to begin populating the informer caches.
 	log.Info("Starting TFJob controller")
@@ -256,14 +193,16 @@ func (tc *TFJobController) Run(threadiness int, stopCh <-chan struct{}) error {
 		return fmt.Errorf("failed to wait for tfjob caches to sync")
 	}
 
-	if ok := cache.WaitForCacheSync(stopCh, tc.podInformerSynced); !ok {
-		return fmt.Errorf("failed to wait for pod caches to sync")
+	if ok := cache.WaitForCacheSync(stopCh, tc.PodInformerSynced, tc.nodeInformerSynced); !ok {
+		return fmt.Errorf("failed to wait for pod and node caches to sync")
 	}
 
-	if ok := cache.WaitForCacheSync(stopCh, tc.serviceInformerSynced); !ok {
+	if ok := cache.WaitForCacheSync(stopCh, tc.ServiceInformerSynced); !ok {
 		return fmt.Errorf("failed to wait for service caches to sync")
 	}
 
+	go tc.notifier.Run(notificationWorkers, stopCh)
+
 	log.Infof("Starting %v workers", threadiness)
 	// Launch workers to process TFJob resources.
 	for i := 0; i < threadiness; i++ {
@@ -288,11 +227,11 @@ func (tc *TFJobController) runWorker() {
 // processNextWorkItem will read a single work item off the workqueue and
 // attempt to process it, by calling the syncHandler.
 func (tc *TFJobController) processNextWorkItem() bool {
-	key, quit := tc.workQueue.Get()
+	key, quit := tc.WorkQueue.Get()
 	if quit {
 		return false
 	}
-	defer tc.workQueue.Done(key)
+	defer tc.WorkQueue.Done(key)
 
 	tfJob, err := tc.getTFJobFromKey(key.(string))
 	if err != nil {
@@ -301,7 +240,7 @@ func (tc *TFJobController) processNextWorkItem() bool {
 		if err == errFailedMarshal {
 			errMsg := fmt.Sprintf("Failed to unmarshal the object to TFJob object: %v", err)
 			loggerForTFJob(tfJob).Warn(errMsg)
-			tc.recorder.Event(tfJob, v1.EventTypeWarning, failedMarshalTFJobReason, errMsg)
+			tc.Recorder.Event(tfJob, v1.EventTypeWarning, failedMarshalTFJobReason, errMsg)
 		}
 		return true
 	}
@@ -310,13 +249,13 @@ func (tc *TFJobController) processNextWorkItem() bool {
 	forget, err := tc.syncHandler(key.(string))
 	if err == nil {
 		if forget {
-			tc.workQueue.Forget(key)
+			tc.WorkQueue.Forget(key)
 		}
 		return true
 	}
 
 	utilruntime.HandleError(fmt.Errorf("Error syncing tfjob: %v", err))
-	tc.workQueue.AddRateLimited(key)
+	tc.WorkQueue.AddRateLimited(key)
 
 	return true
 }
@@ -329,7 +268,7 @@ func (tc *TFJobController) enqueueTFJob(tfjob interface{}) {
 	}
 
 	// TODO: we may need add backoff here
-	tc.workQueue.Add(key)
+	tc.WorkQueue.Add(key)
 }
 
 // syncTFJob syncs the tfjob with the given key if it has had its expectations fulfilled, meaning
@@ -360,13 +299,22 @@ func (tc *TFJobController) syncTFJob(key string) (bool, error) {
 	}
 
 	tfjob := sharedTFJob.DeepCopy()
-	tfjobNeedsSync := tc.satisfiedExpectations(tfjob)
+	tfjobNeedsSync := tc.SatisfiedExpectations(tfjob)
 
 	// Set default for the new tfjob.
 	scheme.Scheme.Default(tfjob)
 
+	// A deleted tfjob is cleaned up until its finalizer can be removed.
+	if tfjob.DeletionTimestamp != nil {
+		if err := tc.finalizeTFJob(tfjob); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
 	var reconcileTFJobsErr error
-	if tfjobNeedsSync && tfjob.DeletionTimestamp == nil {
+	if tfjobNeedsSync {
+		addFinalizer(tfjob)
 		reconcileTFJobsErr = tc.reconcileTFJobs(tfjob)
 	}
 
@@ -382,25 +330,97 @@ func (tc *TFJobController) syncTFJob(key string) (bool, error) {
 func (tc *TFJobController) reconcileTFJobs(tfjob *tfv1alpha2.TFJob) error {
 	log.Infof("Reconcile TFJobs %s", tfjob.Name)
 
-	pods, err := tc.getPodsForTFJob(tfjob)
+	pods, err := tc.GetPodsForJob(tfjob)
+
+	if err != nil {
+		log.Infof("GetPodsForJob error %v", err)
+		return err
+	}
+
+	services, err := tc.GetServicesForJob(tfjob)
+
+	if err != nil {
+		log.Infof("GetServicesForJob error %v", err)
+		return err
+	}
+
+	if invalid, err := tc.reconcileInvalidTFJob(tfjob, pods); invalid {
+		if err != nil {
+			log.Infof("reconcileInvalidTFJob error %v", err)
+		}
+		return err
+	}
+
+	if err := tc.reconcileTensorBoard(tfjob); err != nil {
+		log.Infof("reconcileTensorBoard error %v", err)
+		return err
+	}
+
+	if err := tc.reconcileClusterSpecConfigMap(tfjob, pods); err != nil {
+		log.Infof("reconcileClusterSpecConfigMap error %v", err)
+		return err
+	}
+
+	if err := tc.reconcileNetworkPolicy(tfjob); err != nil {
+		log.Infof("reconcileNetworkPolicy error %v", err)
+		return err
+	}
+
+	// Keep the conditions to emit events for the changed ones.
+	oldConditions := append([]tfv1alpha2.TFJobCondition{}, tfjob.Status.Conditions...)
 
+	// The pods of a tfjob failed by its scheduling timeout are not created again.
+	if isSchedulingTimedOut(tfjob.Status) {
+		if err := tc.deletePods(tfjob, pods); err != nil {
+			log.Infof("deletePods error %v", err)
+			return err
+		}
+		tc.recordConditionEvents(tfjob, oldConditions)
+		return tc.updateStatusHandler(tfjob)
+	}
+
+	// A failed pod may restart the whole tfjob according to the exit code rules.
+	restarted, err := tc.restartTFJobIfNeeded(tfjob, pods)
 	if err != nil {
-		log.Infof("getPodsForTFJob error %v", err)
+		log.Infof("restartTFJobIfNeeded error %v", err)
 		return err
 	}
+	if restarted {
+		tc.recordConditionEvents(tfjob, oldConditions)
+		return tc.updateStatusHandler(tfjob)
+	}
 
-	services, err := tc.getServicesForTFJob(tfjob)
+	// A change of the templates may recreate all the pods.
+	updated, err := tc.reconcileUpdate(tfjob, pods)
+	if err != nil {
+		log.Infof("reconcileUpdate error %v", err)
+		return err
+	}
+	if updated {
+		tc.recordConditionEvents(tfjob, oldConditions)
+		return tc.updateStatusHandler(tfjob)
+	}
 
+	failed, err := tc.reconcileScheduling(tfjob, pods)
 	if err != nil {
-		log.Infof("getServicesForTFJob error %v", err)
+		log.Infof("reconcileScheduling error %v", err)
 		return err
 	}
+	if failed {
+		tc.recordConditionEvents(tfjob, oldConditions)
+		return tc.updateStatusHandler(tfjob)
+	}
 
 	// Diff current active pods/services with replicas.
 	for rtype, spec := range tfjob.Spec.TFReplicaSpecs {
 		err = tc.reconcilePods(tfjob, pods, rtype, spec)
 		if err != nil {
 			log.Infof("reconcilePods error %v", err)
+			// Record why the pods could not be created.
+			tc.recordConditionEvents(tfjob, oldConditions)
+			if updateErr := tc.updateStatusHandler(tfjob); updateErr != nil {
+				log.Infof("updateStatusHandler error %v", updateErr)
+			}
 			return err
 		}
 
@@ -412,51 +432,9 @@ func (tc *TFJobController) reconcileTFJobs(tfjob *tfv1alpha2.TFJob) error {
 		}
 	}
 
+	updatePodProblemCondition(tfjob, findPodProblem(pods))
+	tc.recordConditionEvents(tfjob, oldConditions)
+
 	// TODO(CPH): Add check here, no need to update the tfjob if the status hasn't changed since last time.
 	return tc.updateStatusHandler(tfjob)
 }
-
-// satisfiedExpectations returns true if the required adds/dels for the given tfjob have been observed.
-// Add/del counts are established by the controller at sync time, and updated as controllees are observed by the controller
-// manager.
-func (tc *TFJobController) satisfiedExpectations(tfjob *tfv1alpha2.TFJob) bool {
-	satisfied := false
-	tfjobKey, err := KeyFunc(tfjob)
-	if err != nil {
-		utilruntime.HandleError(fmt.Errorf("Couldn't get key for tfjob object %#v: %v", tfjob, err))
-		return false
-	}
-
-	for rtype := range tfjob.Spec.TFReplicaSpecs {
-		// Check the expectations of the pods.
-		expectationPodsKey := genExpectationPodsKey(tfjobKey, string(rtype))
-		satisfied = satisfied || tc.expectations.SatisfiedExpectations(expectationPodsKey)
-
-		// Check the expectations of the services.
-		expectationServicesKey := genExpectationServicesKey(tfjobKey, string(rtype))
-		satisfied = satisfied || tc.expectations.SatisfiedExpectations(expectationServicesKey)
-	}
-
-	return satisfied
-}
-
-// resolveControllerRef returns the tfjob referenced by a ControllerRef,
-// or nil if the ControllerRef could not be resolved to a matching tfjob
-// of the correct Kind.
-func (tc *TFJobController) resolveControllerRef(namespace string, controllerRef *metav1.OwnerReference) *tfv1alpha2.TFJob {
-	// We can't look up by UID, so look up by Name and then verify UID.
-	// Don't even try to look up by Name if it's the wrong Kind.
-	if controllerRef.Kind != controllerKind.Kind {
-		return nil
-	}
-	tfjob, err := tc.getTFJobFromName(namespace, controllerRef.Name)
-	if err != nil {
-		return nil
-	}
-	if tfjob.UID != controllerRef.UID {
-		// The controller we found with this Name is not the same one that the
-		// ControllerRef points to.
-		return nil
-	}
-	return tfjob
-}
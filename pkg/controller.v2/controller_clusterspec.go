@@ -0,0 +1,201 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+)
+
+const (
+	clusterSpecVolumeName = "tf-cluster-spec"
+
+	clusterSpecFileEnv  = "TF_CLUSTER_SPEC_FILE"
+	replicaTypeEnv      = "TF_REPLICA_TYPE"
+	replicaIndexEnv     = "TF_REPLICA_INDEX"
+	clusterSpecShimName = "tf-config-shim"
+
+	// clusterSpecShim exports TF_CONFIG from the cluster spec file and the
+	// task of the pod, then runs the command of the container, which is
+	// given as the arguments of the shim. In the host network, it first
+	// waits for the address of the pod to be in the file, which is updated
+	// when the pod has been created again on another node.
+	clusterSpecShim = `if [ -n "$TF_HOST_IP" ]; then
+  until grep -q "\"$TF_HOST_IP:$TF_HOST_PORT\"" "$TF_CLUSTER_SPEC_FILE"; do sleep 1; done
+fi
+export TF_CONFIG="{\"cluster\":$(cat "$TF_CLUSTER_SPEC_FILE"),\"task\":{\"type\":\"$TF_REPLICA_TYPE\",\"index\":$TF_REPLICA_INDEX}}"
+exec "$@"`
+)
+
+// genClusterSpecConfigMapName returns the name of the ConfigMap with the cluster spec of the tfjob.
+func genClusterSpecConfigMapName(tfjob *tfv1alpha2.TFJob) string {
+	return tfjob.Name + "-cluster-spec"
+}
+
+// newClusterSpecConfigMap returns the ConfigMap with the cluster spec of the tfjob.
+func newClusterSpecConfigMap(tfjob *tfv1alpha2.TFJob, cluster ClusterSpec) (*v1.ConfigMap, error) {
+	data, err := json.Marshal(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            genClusterSpecConfigMapName(tfjob),
+			Namespace:       tfjob.Namespace,
+			Labels:          generator.GenLabels(tfjob.Name),
+			OwnerReferences: []metav1.OwnerReference{*generator.GenOwnerReference(tfjob)},
+		},
+		Data: map[string]string{
+			tfv1alpha2.ClusterSpecFileName: string(data),
+		},
+	}, nil
+}
+
+// reconcileClusterSpecConfigMap creates the ConfigMap with the cluster spec of
+// the tfjob if it is delivered by a ConfigMap, and updates it when the
+// replicas change. In the host network, it is only written once all the pods
+// are scheduled: the pods wait for it to exist and for their address in it.
+func (tc *TFJobController) reconcileClusterSpecConfigMap(tfjob *tfv1alpha2.TFJob, pods []*v1.Pod) error {
+	if tfjob.Spec.ClusterSpecDelivery != tfv1alpha2.ClusterSpecDeliveryConfigMap {
+		return nil
+	}
+	var cluster ClusterSpec
+	if tfjob.Spec.HostNetwork != nil {
+		var complete bool
+		cluster, complete = genHostNetworkClusterSpec(tfjob, pods)
+		if !complete {
+			return nil
+		}
+	} else {
+		var err error
+		cluster, err = genClusterSpec(tfjob)
+		if err != nil {
+			return err
+		}
+	}
+	desired, err := newClusterSpecConfigMap(tfjob, cluster)
+	if err != nil {
+		return err
+	}
+
+	configMaps := tc.KubeClientSet.CoreV1().ConfigMaps(tfjob.Namespace)
+	current, err := configMaps.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		loggerForTFJob(tfjob).Infof("Need to create the cluster spec configmap %s", desired.Name)
+		_, err = configMaps.Create(desired)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(current.Data, desired.Data) {
+		return nil
+	}
+	if ref := metav1.GetControllerOf(current); ref == nil || ref.UID != tfjob.UID {
+		return fmt.Errorf("configmap %s already exists and is not owned by tfjob %s", desired.Name, tfjob.Name)
+	}
+	loggerForTFJob(tfjob).Infof("Updating the cluster spec configmap %s", desired.Name)
+	current = current.DeepCopy()
+	current.Data = desired.Data
+	_, err = configMaps.Update(current)
+	return err
+}
+
+// deleteClusterSpecConfigMap deletes the cluster spec ConfigMap of the tfjob
+// if it exists and is owned by the tfjob.
+func (tc *TFJobController) deleteClusterSpecConfigMap(tfjob *tfv1alpha2.TFJob) error {
+	configMaps := tc.KubeClientSet.CoreV1().ConfigMaps(tfjob.Namespace)
+	current, err := configMaps.Get(genClusterSpecConfigMapName(tfjob), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if ref := metav1.GetControllerOf(current); ref == nil || ref.UID != tfjob.UID {
+		return nil
+	}
+	loggerForTFJob(tfjob).Infof("Deleting the cluster spec configmap %s", current.Name)
+	err = configMaps.Delete(current.Name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// setClusterSpecConfigMap mounts the cluster spec ConfigMap in the tensorflow
+// container, sets its task from the labels of the pod and wraps its command
+// in the shim exporting TF_CONFIG. A container without command would run
+// without TF_CONFIG, so it is an error.
+func setClusterSpecConfigMap(podTemplateSpec *v1.PodTemplateSpec, tfjob *tfv1alpha2.TFJob) error {
+	podSpec := &podTemplateSpec.Spec
+	podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+		Name: clusterSpecVolumeName,
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: genClusterSpecConfigMapName(tfjob)},
+			},
+		},
+	})
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name != tfv1alpha2.DefaultContainerName {
+			continue
+		}
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+			Name:      clusterSpecVolumeName,
+			MountPath: tfv1alpha2.ClusterSpecMountPath,
+			ReadOnly:  true,
+		})
+		container.Env = append(container.Env,
+			v1.EnvVar{
+				Name:  clusterSpecFileEnv,
+				Value: path.Join(tfv1alpha2.ClusterSpecMountPath, tfv1alpha2.ClusterSpecFileName),
+			},
+			v1.EnvVar{
+				Name: replicaTypeEnv,
+				ValueFrom: &v1.EnvVarSource{
+					FieldRef: &v1.ObjectFieldSelector{FieldPath: fmt.Sprintf("metadata.labels['%s']", tfReplicaTypeLabel)},
+				},
+			},
+			v1.EnvVar{
+				Name: replicaIndexEnv,
+				ValueFrom: &v1.EnvVarSource{
+					FieldRef: &v1.ObjectFieldSelector{FieldPath: fmt.Sprintf("metadata.labels['%s']", tfReplicaIndexLabel)},
+				},
+			},
+		)
+		if len(container.Command) == 0 {
+			return fmt.Errorf("container %s must set its command with clusterSpecDelivery %s, it is wrapped to export TF_CONFIG",
+				container.Name, tfv1alpha2.ClusterSpecDeliveryConfigMap)
+		}
+		container.Args = append(append([]string{}, container.Command...), container.Args...)
+		container.Command = []string{"sh", "-c", clusterSpecShim, clusterSpecShimName}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestSetClusterSpecConfigMap(t *testing.T) {
+	tfJob := testutil.NewTFJob(2, 1)
+	tfJob.Spec.ClusterSpecDelivery = tfv1alpha2.ClusterSpecDeliveryConfigMap
+	podTemplate := tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker].Template.DeepCopy()
+	podTemplate.Spec.Containers[0].Command = []string{"python", "train.py"}
+	podTemplate.Spec.Containers[0].Args = []string{"--steps=100"}
+	podTemplate.Spec.Containers = append(podTemplate.Spec.Containers, v1.Container{Name: "sidecar", Image: "sidecar"})
+
+	if err := setClusterSpec(podTemplate, tfJob, "worker", "1"); err != nil {
+		t.Fatalf("Failed to set cluster spec: %v", err)
+	}
+
+	if len(podTemplate.Spec.Volumes) != 1 || podTemplate.Spec.Volumes[0].ConfigMap == nil ||
+		podTemplate.Spec.Volumes[0].ConfigMap.Name != testutil.TestTFJobName+"-cluster-spec" {
+		t.Errorf("Expected the cluster spec configmap volume, got %+v", podTemplate.Spec.Volumes)
+	}
+	container := podTemplate.Spec.Containers[0]
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != tfv1alpha2.ClusterSpecMountPath {
+		t.Errorf("Expected the cluster spec to be mounted at %s, got %+v", tfv1alpha2.ClusterSpecMountPath, container.VolumeMounts)
+	}
+	env := make(map[string]v1.EnvVar)
+	for _, e := range container.Env {
+		env[e.Name] = e
+	}
+	if _, ok := env[tfConfig]; ok {
+		t.Errorf("Expected no %s env, got %+v", tfConfig, container.Env)
+	}
+	if env[clusterSpecFileEnv].Value != "/etc/tf-cluster-spec/cluster.json" {
+		t.Errorf("Expected the path of the cluster spec file, got %+v", env[clusterSpecFileEnv])
+	}
+	if e := env[replicaIndexEnv]; e.ValueFrom == nil || e.ValueFrom.FieldRef.FieldPath != "metadata.labels['tf-replica-index']" {
+		t.Errorf("Expected the replica index from the labels of the pod, got %+v", e)
+	}
+	if container.Command[0] != "sh" || container.Command[len(container.Command)-1] != clusterSpecShimName {
+		t.Errorf("Expected the command to be wrapped in the shim, got %v", container.Command)
+	}
+	if !reflect.DeepEqual(container.Args, []string{"python", "train.py", "--steps=100"}) {
+		t.Errorf("Expected the command and args of the container as the args of the shim, got %v", container.Args)
+	}
+
+	sidecar := podTemplate.Spec.Containers[1]
+	if len(sidecar.Env) != 0 || len(sidecar.VolumeMounts) != 0 || len(sidecar.Command) != 0 {
+		t.Errorf("Expected the sidecar to be unchanged, got %+v", sidecar)
+	}
+
+	// A container relying on the entrypoint of its image would not get TF_CONFIG.
+	podTemplate = tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker].Template.DeepCopy()
+	if err := setClusterSpec(podTemplate, tfJob, "worker", "1"); err == nil {
+		t.Errorf("Expected an error for a container without command")
+	}
+}
+
+func TestReconcileClusterSpecConfigMap(t *testing.T) {
+	kubeClientSet := kubefake.NewSimpleClientset()
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+	ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+
+	tfJob := testutil.NewTFJob(1, 1)
+	tfJob.Spec.ClusterSpecDelivery = tfv1alpha2.ClusterSpecDeliveryConfigMap
+	name := testutil.TestTFJobName + "-cluster-spec"
+	psAddress := testutil.TestTFJobName + "-ps-0.default.svc.cluster.local:2222"
+	worker0 := testutil.TestTFJobName + "-worker-0.default.svc.cluster.local:2222"
+	worker1 := testutil.TestTFJobName + "-worker-1.default.svc.cluster.local:2222"
+
+	if err := ctr.reconcileClusterSpecConfigMap(tfJob, nil); err != nil {
+		t.Fatalf("Failed to reconcile the configmap: %v", err)
+	}
+	configMap, err := kubeClientSet.CoreV1().ConfigMaps(tfJob.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the configmap %s to be created: %v", name, err)
+	}
+	expected := `{"ps":["` + psAddress + `"],"worker":["` + worker0 + `"]}`
+	if configMap.Data[tfv1alpha2.ClusterSpecFileName] != expected {
+		t.Errorf("Expected cluster spec %s, got %s", expected, configMap.Data[tfv1alpha2.ClusterSpecFileName])
+	}
+	if ref := metav1.GetControllerOf(configMap); ref == nil || ref.Name != tfJob.Name {
+		t.Errorf("Expected the configmap to be owned by the tfjob, got %v", ref)
+	}
+
+	// The configmap follows the replicas.
+	*tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker].Replicas = 2
+	if err := ctr.reconcileClusterSpecConfigMap(tfJob, nil); err != nil {
+		t.Fatalf("Failed to reconcile the configmap: %v", err)
+	}
+	configMap, err = kubeClientSet.CoreV1().ConfigMaps(tfJob.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get the configmap %s: %v", name, err)
+	}
+	expected = `{"ps":["` + psAddress + `"],"worker":["` + worker0 + `","` + worker1 + `"]}`
+	if configMap.Data[tfv1alpha2.ClusterSpecFileName] != expected {
+		t.Errorf("Expected updated cluster spec %s, got %s", expected, configMap.Data[tfv1alpha2.ClusterSpecFileName])
+	}
+}
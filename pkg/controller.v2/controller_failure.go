@@ -0,0 +1,177 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/notification"
+)
+
+const (
+	// containerFailedReason is used when a container failed without a more precise reason.
+	containerFailedReason = "ContainerFailed"
+	// podFailedReason is used when a pod failed without any failed container.
+	podFailedReason = "PodFailed"
+	// exceededQuotaReason is used when a pod is rejected by a resource quota.
+	exceededQuotaReason = "ExceededQuota"
+	// failedCreatePodReason is used when a pod can not be created for another reason.
+	failedCreatePodReason = "FailedCreatePod"
+)
+
+// podProblemReasons are the reasons of the problems which keep pods from
+// running without failing them.
+var podProblemReasons = map[string]bool{
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"ErrImageNeverPull":          true,
+	"InvalidImageName":           true,
+	"CrashLoopBackOff":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	exceededQuotaReason:          true,
+	failedCreatePodReason:        true,
+}
+
+// podProblem is the condensed reason why a pod failed or does not run.
+type podProblem struct {
+	reason  string
+	message string
+}
+
+// getPodFailure returns why a failed pod failed. The tensorflow container
+// is looked at first, then the other containers and the pod itself.
+func getPodFailure(pod *v1.Pod) *podProblem {
+	statuses := append([]v1.ContainerStatus{}, pod.Status.ContainerStatuses...)
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Name == tfv1alpha2.DefaultContainerName && statuses[j].Name != tfv1alpha2.DefaultContainerName
+	})
+	for _, status := range statuses {
+		terminated := status.State.Terminated
+		if terminated == nil || terminated.ExitCode == 0 {
+			continue
+		}
+		reason := terminated.Reason
+		if reason == "" || reason == "Error" {
+			reason = containerFailedReason
+		}
+		msg := fmt.Sprintf("container %s of pod %s exited with code %d", status.Name, pod.Name, terminated.ExitCode)
+		if terminated.Reason != "" {
+			msg = fmt.Sprintf("%s (%s)", msg, terminated.Reason)
+		}
+		if terminated.Message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, strings.TrimSpace(terminated.Message))
+		}
+		return &podProblem{reason: reason, message: msg}
+	}
+	// E.g. the pod has been evicted.
+	if pod.Status.Reason != "" {
+		return &podProblem{
+			reason:  pod.Status.Reason,
+			message: fmt.Sprintf("pod %s failed: %s", pod.Name, pod.Status.Message),
+		}
+	}
+	return &podProblem{reason: podFailedReason, message: fmt.Sprintf("pod %s failed", pod.Name)}
+}
+
+// getPodProblem returns why a pod which has not failed does not run, or nil.
+// Unschedulable pods are reported in the Unschedulable condition instead.
+func getPodProblem(pod *v1.Pod) *podProblem {
+	if pod.Status.Phase == v1.PodFailed || pod.Status.Phase == v1.PodSucceeded || pod.DeletionTimestamp != nil {
+		return nil
+	}
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		waiting := status.State.Waiting
+		if waiting == nil || !podProblemReasons[waiting.Reason] {
+			continue
+		}
+		msg := fmt.Sprintf("container %s of pod %s is waiting (%s)", status.Name, pod.Name, waiting.Reason)
+		if waiting.Message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, waiting.Message)
+		}
+		if last := status.LastTerminationState.Terminated; last != nil {
+			msg = fmt.Sprintf("%s, last exited with code %d", msg, last.ExitCode)
+			if last.Reason != "" {
+				msg = fmt.Sprintf("%s (%s)", msg, last.Reason)
+			}
+		}
+		return &podProblem{reason: waiting.Reason, message: msg}
+	}
+	return nil
+}
+
+// findPodProblem returns the problem of the first pod, by name, which does not run.
+func findPodProblem(pods []*v1.Pod) *podProblem {
+	sorted := append([]*v1.Pod{}, pods...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for _, pod := range sorted {
+		if problem := getPodProblem(pod); problem != nil {
+			return problem
+		}
+	}
+	return nil
+}
+
+// getCreatePodProblem returns the problem of a pod which can not be created.
+func getCreatePodProblem(name string, err error) *podProblem {
+	reason := failedCreatePodReason
+	if errors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota") {
+		reason = exceededQuotaReason
+	}
+	return &podProblem{
+		reason:  reason,
+		message: fmt.Sprintf("failed to create pod %s: %v", name, err),
+	}
+}
+
+// updatePodProblemCondition records the problem of the pods in the Stalled
+// condition of the tfjob. If problem is nil, the condition is removed.
+func updatePodProblemCondition(tfjob *tfv1alpha2.TFJob, problem *podProblem) {
+	if problem == nil {
+		removementCondition(&tfjob.Status, tfv1alpha2.TFJobStalled)
+		return
+	}
+	msg := fmt.Sprintf("TFJob %s is not progressing: %s.", tfjob.Name, problem.message)
+	setCondition(&tfjob.Status, newCondition(tfv1alpha2.TFJobStalled, problem.reason, msg))
+}
+
+// recordConditionEvents emits an event and notifies the webhooks for each
+// condition of the tfjob whose reason or message differs from the old conditions.
+func (tc *TFJobController) recordConditionEvents(tfjob *tfv1alpha2.TFJob, oldConditions []tfv1alpha2.TFJobCondition) {
+	old := tfv1alpha2.TFJobStatus{Conditions: oldConditions}
+	notifications := tc.configStore.Get().Notifications
+	webhooks := notification.Webhooks(tfjob, notifications)
+	for _, condition := range tfjob.Status.Conditions {
+		if c := getCondition(old, condition.Type); c != nil && c.Reason == condition.Reason && c.Message == condition.Message {
+			continue
+		}
+		eventType := v1.EventTypeNormal
+		if condition.Type == tfv1alpha2.TFJobFailed || condition.Type == tfv1alpha2.TFJobUnschedulable || condition.Type == tfv1alpha2.TFJobStalled {
+			eventType = v1.EventTypeWarning
+		}
+		tc.Recorder.Event(tfjob, eventType, condition.Reason, condition.Message)
+		tc.notifier.Notify(webhooks, notification.NewPayload(tfjob, condition), notifications)
+	}
+}
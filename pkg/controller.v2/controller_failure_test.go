@@ -0,0 +1,285 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	tfconfig "github.com/kubeflow/tf-operator/pkg/controller.v2/config"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/notification"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestGetPodFailure(t *testing.T) {
+	tfJob := testutil.NewTFJob(1, 0)
+	type tc struct {
+		status          v1.PodStatus
+		expectedReason  string
+		expectedMessage string
+	}
+	testCase := []tc{
+		{
+			status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:  "sidecar",
+						State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}},
+					},
+					{
+						Name:  tfv1alpha2.DefaultContainerName,
+						State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}},
+					},
+				},
+			},
+			expectedReason:  "OOMKilled",
+			expectedMessage: "container tensorflow of pod worker-0 exited with code 137 (OOMKilled)",
+		},
+		{
+			status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:  tfv1alpha2.DefaultContainerName,
+						State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1, Reason: "Error", Message: "bad input\n"}},
+					},
+				},
+			},
+			expectedReason:  containerFailedReason,
+			expectedMessage: "container tensorflow of pod worker-0 exited with code 1 (Error): bad input",
+		},
+		{
+			status:          v1.PodStatus{Reason: "Evicted", Message: "The node was low on resource: memory."},
+			expectedReason:  "Evicted",
+			expectedMessage: "pod worker-0 failed: The node was low on resource: memory.",
+		},
+		{
+			status:          v1.PodStatus{},
+			expectedReason:  podFailedReason,
+			expectedMessage: "pod worker-0 failed",
+		},
+	}
+	for _, c := range testCase {
+		pod := testutil.NewPod(tfJob, testutil.LabelWorker, 0, t)
+		pod.Status = c.status
+		pod.Status.Phase = v1.PodFailed
+		failure := getPodFailure(pod)
+		if failure.reason != c.expectedReason || failure.message != c.expectedMessage {
+			t.Errorf("Expected %s: %s, got %s: %s", c.expectedReason, c.expectedMessage, failure.reason, failure.message)
+		}
+	}
+}
+
+func TestGetPodProblem(t *testing.T) {
+	tfJob := testutil.NewTFJob(1, 0)
+	type tc struct {
+		status         v1.PodStatus
+		expectedReason string
+	}
+	testCase := []tc{
+		// Unschedulable pods are reported in their own condition.
+		{
+			status: v1.PodStatus{
+				Phase: v1.PodPending,
+				Conditions: []v1.PodCondition{
+					{Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: v1.PodReasonUnschedulable, Message: "0/3 nodes are available: 3 Insufficient nvidia.com/gpu."},
+				},
+			},
+		},
+		{
+			status: v1.PodStatus{
+				Phase: v1.PodPending,
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: tfv1alpha2.DefaultContainerName, State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+				},
+			},
+			expectedReason: "ImagePullBackOff",
+		},
+		{
+			status: v1.PodStatus{
+				Phase: v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:                 tfv1alpha2.DefaultContainerName,
+						State:                v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+						LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1}},
+					},
+				},
+			},
+			expectedReason: "CrashLoopBackOff",
+		},
+		{
+			status: v1.PodStatus{
+				Phase: v1.PodPending,
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: tfv1alpha2.DefaultContainerName, State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+				},
+			},
+		},
+		{
+			status: v1.PodStatus{Phase: v1.PodFailed},
+		},
+	}
+	for i, c := range testCase {
+		pod := testutil.NewPod(tfJob, testutil.LabelWorker, 0, t)
+		pod.Status = c.status
+		problem := getPodProblem(pod)
+		if c.expectedReason == "" {
+			if problem != nil {
+				t.Errorf("%d: expected no problem, got %+v", i, problem)
+			}
+			continue
+		}
+		if problem == nil || problem.reason != c.expectedReason {
+			t.Errorf("%d: expected %s, got %+v", i, c.expectedReason, problem)
+		}
+	}
+}
+
+func TestGetCreatePodProblem(t *testing.T) {
+	resource := schema.GroupResource{Resource: "pods"}
+	quotaErr := errors.NewForbidden(resource, "worker-0", fmt.Errorf("exceeded quota: compute, requested: nvidia.com/gpu=1, used: nvidia.com/gpu=4, limited: nvidia.com/gpu=4"))
+	if problem := getCreatePodProblem("worker-0", quotaErr); problem.reason != exceededQuotaReason || !strings.Contains(problem.message, "nvidia.com/gpu") {
+		t.Errorf("Expected %s, got %+v", exceededQuotaReason, problem)
+	}
+	invalidErr := errors.NewBadRequest("invalid pod")
+	if problem := getCreatePodProblem("worker-0", invalidErr); problem.reason != failedCreatePodReason {
+		t.Errorf("Expected %s, got %+v", failedCreatePodReason, problem)
+	}
+}
+
+func TestUpdatePodProblemCondition(t *testing.T) {
+	tfJob := testutil.NewTFJob(1, 0)
+	problem := &podProblem{reason: "ImagePullBackOff", message: "container tensorflow of pod worker-0 is waiting (ImagePullBackOff)"}
+
+	setCondition(&tfJob.Status, newCondition(tfv1alpha2.TFJobRunning, tfJobRunningReason, ""))
+
+	updatePodProblemCondition(tfJob, problem)
+	if c := getCondition(tfJob.Status, tfv1alpha2.TFJobStalled); c == nil || c.Reason != problem.reason {
+		t.Errorf("Expected the problem in the stalled condition, got %+v", c)
+	}
+	// Nothing restarted, the other conditions are unchanged.
+	if c := getCondition(tfJob.Status, tfv1alpha2.TFJobRunning); c == nil || c.Status != v1.ConditionTrue {
+		t.Errorf("Expected the running condition to be unchanged, got %+v", c)
+	}
+	if c := getCondition(tfJob.Status, tfv1alpha2.TFJobRestarting); c != nil {
+		t.Errorf("Expected no restarting condition, got %+v", c)
+	}
+
+	updatePodProblemCondition(tfJob, nil)
+	if c := getCondition(tfJob.Status, tfv1alpha2.TFJobStalled); c != nil {
+		t.Errorf("Expected the stalled condition to be removed, got %+v", c)
+	}
+}
+
+func TestFailedConditionReason(t *testing.T) {
+	tfJob := testutil.NewTFJob(1, 0)
+	initializeTFReplicaStatuses(tfJob, tfv1alpha2.TFReplicaTypeWorker)
+	tfJob.Status.TFReplicaStatuses[tfv1alpha2.TFReplicaTypeWorker].Failed = 1
+	failure := &podProblem{reason: "OOMKilled", message: "container tensorflow of pod worker-0 exited with code 137 (OOMKilled)"}
+	if err := newStatusController().updateStatus(tfJob, tfv1alpha2.TFReplicaTypeWorker, 1, failure); err != nil {
+		t.Errorf("Expected error %v to be nil", err)
+	}
+	c := getCondition(tfJob.Status, tfv1alpha2.TFJobFailed)
+	expected := "TFJob test-tfjob is failed because container tensorflow of pod worker-0 exited with code 137 (OOMKilled)."
+	if c == nil || c.Reason != "OOMKilled" || c.Message != expected {
+		t.Errorf("Expected the failure in the failed condition, got %+v", c)
+	}
+}
+
+func TestRecordConditionNotifications(t *testing.T) {
+	payloads := make(chan notification.Payload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload notification.Payload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode the payload: %v", err)
+		}
+		payloads <- payload
+	}))
+	defer server.Close()
+
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+	ctr, _, _ := newTFJobController(config, kubefake.NewSimpleClientset(), tfJobClientSet, controller.NoResyncPeriodFunc)
+
+	dir, err := ioutil.TempDir("", "notifications")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	data := `
+apiVersion: tf-operator.kubeflow.org/v1alpha2
+kind: OperatorConfiguration
+notifications:
+  allowedHosts:
+  - 127.0.0.1
+`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ctr.configStore, err = tfconfig.NewStore(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go ctr.notifier.Run(1, stopCh)
+
+	tfJob := testutil.NewTFJob(1, 0)
+	tfJob.Annotations = map[string]string{tfv1alpha2.NotificationWebhooksAnnotation: server.URL}
+	running := newCondition(tfv1alpha2.TFJobRunning, tfJobRunningReason, "")
+	setCondition(&tfJob.Status, running)
+	oldConditions := append([]tfv1alpha2.TFJobCondition{}, tfJob.Status.Conditions...)
+	setCondition(&tfJob.Status, newCondition(tfv1alpha2.TFJobSucceeded, tfJobSucceededReason, ""))
+
+	// Only the changed condition is notified.
+	ctr.recordConditionEvents(tfJob, oldConditions)
+	select {
+	case payload := <-payloads:
+		if payload.Key != tfJob.Namespace+"/"+tfJob.Name || payload.Condition.Type != tfv1alpha2.TFJobSucceeded {
+			t.Errorf("Expected the succeeded condition of the tfjob, got %+v", payload)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("Expected the webhook to be notified")
+	}
+	select {
+	case payload := <-payloads:
+		t.Errorf("Expected a single notification, got %+v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+const (
+	// tfJobFinalizer is set on the tfjobs so that their replicas are stopped
+	// and their auxiliary objects are deleted before they are.
+	tfJobFinalizer = "kubeflow.org/tfjob-cleanup"
+
+	// tfJobDeletedReason is the reason of the event emitted once a deleted
+	// tfjob has been cleaned up.
+	tfJobDeletedReason = "TFJobDeleted"
+
+	// unfinishedCondition is the final condition of the tfjobs deleted
+	// before they succeeded or failed.
+	unfinishedCondition = "Unfinished"
+)
+
+// hasFinalizer returns true if the finalizer of the operator is set on the tfjob.
+func hasFinalizer(tfjob *tfv1alpha2.TFJob) bool {
+	for _, finalizer := range tfjob.Finalizers {
+		if finalizer == tfJobFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// addFinalizer sets the finalizer of the operator on the tfjob.
+// It is saved together with the status of the tfjob.
+func addFinalizer(tfjob *tfv1alpha2.TFJob) {
+	if !hasFinalizer(tfjob) {
+		tfjob.Finalizers = append(tfjob.Finalizers, tfJobFinalizer)
+	}
+}
+
+// removeFinalizer removes the finalizer of the operator from the tfjob.
+func removeFinalizer(tfjob *tfv1alpha2.TFJob) {
+	var finalizers []string
+	for _, finalizer := range tfjob.Finalizers {
+		if finalizer != tfJobFinalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	tfjob.Finalizers = finalizers
+}
+
+// finalConditionType returns the condition a tfjob ended with.
+func finalConditionType(status tfv1alpha2.TFJobStatus) string {
+	for _, conditionType := range []tfv1alpha2.TFJobConditionType{tfv1alpha2.TFJobSucceeded, tfv1alpha2.TFJobFailed} {
+		if condition := getCondition(status, conditionType); condition != nil && condition.Status == v1.ConditionTrue {
+			return string(conditionType)
+		}
+	}
+	return unfinishedCondition
+}
+
+// finalizeTFJob cleans up a deleted tfjob: its pods are stopped with the
+// deletion grace period of the configuration, or forced to stop once they are
+// stuck, and its services, TensorBoard, cluster spec ConfigMap and
+// NetworkPolicy are deleted. Once the pods are gone, the deletion is recorded
+// in an event and in the metrics and the finalizer is removed, which lets the
+// API server delete the tfjob.
+func (tc *TFJobController) finalizeTFJob(tfjob *tfv1alpha2.TFJob) error {
+	if !hasFinalizer(tfjob) {
+		// Created by an older operator, the garbage collector cleans it up.
+		return nil
+	}
+
+	pods, err := tc.GetPodsForJob(tfjob)
+	if err != nil {
+		return err
+	}
+	gracePeriod := int64(tc.configStore.Get().DeletionGracePeriod.Duration / time.Second)
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			if err := tc.deleteStuckPod(tfjob, pod); err != nil {
+				return err
+			}
+			continue
+		}
+		loggerForTFJob(tfjob).Infof("Stopping the pod %s of the deleted tfjob", pod.Name)
+		if err := tc.deletePodWithGracePeriod(tfjob, pod, gracePeriod); err != nil {
+			return err
+		}
+	}
+
+	services, err := tc.GetServicesForJob(tfjob)
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		if service.DeletionTimestamp != nil {
+			continue
+		}
+		err := tc.KubeClientSet.CoreV1().Services(service.Namespace).Delete(service.Name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	if err := tc.deleteTensorBoard(tfjob); err != nil {
+		return err
+	}
+	if err := tc.deleteClusterSpecConfigMap(tfjob); err != nil {
+		return err
+	}
+	if err := tc.deleteNetworkPolicy(tfjob); err != nil {
+		return err
+	}
+
+	if len(pods) != 0 {
+		// The deletion of the pods syncs the tfjob again.
+		return nil
+	}
+
+	condition := finalConditionType(tfjob.Status)
+	msg := fmt.Sprintf("TFJob %s has been deleted, its final condition is %s.", tfjob.Name, condition)
+	loggerForTFJob(tfjob).Info(msg)
+	tc.Recorder.Event(tfjob, v1.EventTypeNormal, tfJobDeletedReason, msg)
+	tfJobsDeletedCount.WithLabelValues(tfjob.Namespace, condition).Inc()
+	tfJobLifetimeSeconds.WithLabelValues(condition).Observe(tfjob.DeletionTimestamp.Sub(tfjob.CreationTimestamp.Time).Seconds())
+
+	removeFinalizer(tfjob)
+	return tc.updateStatusHandler(tfjob)
+}
+
+// deleteStuckPod force deletes a terminating pod of a deleted tfjob once its
+// grace period has passed, or once its node is lost: the kubelet of a lost
+// node never confirms the deletion. Otherwise the tfjob is checked again once
+// the grace period has passed.
+func (tc *TFJobController) deleteStuckPod(tfjob *tfv1alpha2.TFJob, pod *v1.Pod) error {
+	deadline := pod.DeletionTimestamp.Time
+	if pod.DeletionGracePeriodSeconds != nil {
+		deadline = deadline.Add(time.Duration(*pod.DeletionGracePeriodSeconds) * time.Second)
+	}
+	remaining := deadline.Sub(time.Now())
+	if remaining > 0 {
+		notReady, ok, err := tc.getNodeNotReady(pod)
+		if err != nil {
+			return err
+		}
+		if !ok || notReady < tc.config.NodeNotReadyTimeout.Duration {
+			key, err := KeyFunc(tfjob)
+			if err != nil {
+				return err
+			}
+			tc.WorkQueue.AddAfter(key, remaining)
+			return nil
+		}
+	}
+	loggerForTFJob(tfjob).Infof("Force deleting the pod %s of the deleted tfjob, it did not terminate", pod.Name)
+	return tc.deletePodWithGracePeriod(tfjob, pod, 0)
+}
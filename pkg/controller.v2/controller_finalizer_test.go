@@ -0,0 +1,216 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	"github.com/kubeflow/tf-operator/pkg/control"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestAddFinalizer(t *testing.T) {
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+	ctr, _, _ := newTFJobController(config, kubefake.NewSimpleClientset(), tfJobClientSet, controller.NoResyncPeriodFunc)
+	var actual *tfv1alpha2.TFJob
+	ctr.updateStatusHandler = func(tfJob *tfv1alpha2.TFJob) error {
+		actual = tfJob
+		return nil
+	}
+
+	tfJob := testutil.NewTFJob(1, 0)
+	unstructured, err := generator.ConvertTFJobToUnstructured(tfJob)
+	if err != nil {
+		t.Fatalf("Failed to convert the TFJob to Unstructured: %v", err)
+	}
+	if err := ctr.tfJobInformer.GetIndexer().Add(unstructured); err != nil {
+		t.Fatalf("Failed to add tfjob to tfJobIndexer: %v", err)
+	}
+	if _, err := ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+		t.Fatalf("Unexpected error when syncing jobs %v", err)
+	}
+	if actual == nil || !hasFinalizer(actual) {
+		t.Errorf("Expected the finalizer to be saved with the status, got %v", actual)
+	}
+}
+
+func TestFinalizeTFJob(t *testing.T) {
+	tfJob := testutil.NewTFJob(2, 0)
+	tfJob.Spec.ClusterSpecDelivery = tfv1alpha2.ClusterSpecDeliveryConfigMap
+	tfJob.Spec.NetworkIsolation = &tfv1alpha2.NetworkIsolationPolicy{}
+	tfJob.Finalizers = []string{tfJobFinalizer}
+	now := metav1.Now()
+	tfJob.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	tfJob.DeletionTimestamp = &now
+	tfv1alpha2.SetDefaults_TFJob(tfJob)
+	setCondition(&tfJob.Status, newCondition(tfv1alpha2.TFJobSucceeded, tfJobSucceededReason, ""))
+
+	gracePeriod := int64(30)
+	running := testutil.NewPod(tfJob, testutil.LabelWorker, 0, t)
+	terminating := testutil.NewPod(tfJob, testutil.LabelWorker, 1, t)
+	terminating.DeletionTimestamp = &now
+	terminating.DeletionGracePeriodSeconds = &gracePeriod
+	// The grace period of the stuck pod has passed.
+	stuck := testutil.NewPod(tfJob, testutil.LabelWorker, 2, t)
+	stuckSince := metav1.NewTime(now.Add(-time.Minute))
+	stuck.DeletionTimestamp = &stuckSince
+	stuck.DeletionGracePeriodSeconds = &gracePeriod
+	// The node of the lost pod does not exist anymore.
+	lost := testutil.NewPod(tfJob, testutil.LabelWorker, 3, t)
+	lost.Spec.NodeName = "lost-node"
+	lost.DeletionTimestamp = &now
+	lost.DeletionGracePeriodSeconds = &gracePeriod
+	pods := []*v1.Pod{running, terminating, stuck, lost}
+	service := testutil.NewService(tfJob, testutil.LabelWorker, 0, t)
+	cluster, err := genClusterSpec(tfJob)
+	if err != nil {
+		t.Fatalf("Failed to generate the cluster spec: %v", err)
+	}
+	configMap, err := newClusterSpecConfigMap(tfJob, cluster)
+	if err != nil {
+		t.Fatalf("Failed to generate the configmap: %v", err)
+	}
+	policy, err := newNetworkPolicy(tfJob)
+	if err != nil {
+		t.Fatalf("Failed to generate the networkpolicy: %v", err)
+	}
+
+	// Prepare the clientset and controller for the test.
+	kubeClientSet := kubefake.NewSimpleClientset(service, configMap, policy)
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+	ctr, kubeInformerFactory, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+	recorder := record.NewFakeRecorder(10)
+	ctr.Recorder = recorder
+	podControl := &control.FakeGracefulPodControl{}
+	ctr.PodControl = podControl
+	podIndexer := kubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	serviceIndexer := kubeInformerFactory.Core().V1().Services().Informer().GetIndexer()
+	var actual *tfv1alpha2.TFJob
+	ctr.updateStatusHandler = func(tfJob *tfv1alpha2.TFJob) error {
+		actual = tfJob
+		return nil
+	}
+
+	unstructured, err := generator.ConvertTFJobToUnstructured(tfJob)
+	if err != nil {
+		t.Fatalf("Failed to convert the TFJob to Unstructured: %v", err)
+	}
+	if err := ctr.tfJobInformer.GetIndexer().Add(unstructured); err != nil {
+		t.Fatalf("Failed to add tfjob to tfJobIndexer: %v", err)
+	}
+	for _, pod := range pods {
+		if err := podIndexer.Add(pod); err != nil {
+			t.Fatalf("Unexpected error when adding pod %v", err)
+		}
+	}
+	if err := serviceIndexer.Add(service); err != nil {
+		t.Fatalf("Unexpected error when adding service %v", err)
+	}
+
+	// The replicas are stopped, the stuck ones forced to, and the auxiliary
+	// objects deleted, the finalizer is kept until the pods are gone.
+	if _, err := ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+		t.Fatalf("Unexpected error when syncing jobs %v", err)
+	}
+	expectedGracePeriods := map[string]int64{
+		running.Name: int64(ctr.configStore.Get().DeletionGracePeriod.Duration / time.Second),
+		stuck.Name:   0,
+		lost.Name:    0,
+	}
+	if !reflect.DeepEqual(podControl.GracePeriods, expectedGracePeriods) {
+		t.Errorf("Expected the pods to be deleted with the grace periods %v, got %v", expectedGracePeriods, podControl.GracePeriods)
+	}
+	deleted := make(map[string]bool)
+	for _, action := range kubeClientSet.Actions() {
+		if action, ok := action.(core.DeleteAction); ok {
+			deleted[action.GetResource().Resource+"/"+action.GetName()] = true
+		}
+	}
+	expected := []string{
+		"services/" + service.Name,
+		"configmaps/" + configMap.Name,
+		"networkpolicies/" + policy.Name,
+	}
+	for _, name := range expected {
+		if !deleted[name] {
+			t.Errorf("Expected %s to be deleted, got %v", name, deleted)
+		}
+	}
+	if actual != nil {
+		t.Errorf("Expected the finalizer to be kept while the pods are running, got %v", actual.Finalizers)
+	}
+
+	// Once the pods are gone, the deletion is recorded and the finalizer removed.
+	for _, pod := range pods {
+		if err := podIndexer.Delete(pod); err != nil {
+			t.Fatalf("Unexpected error when deleting pod %v", err)
+		}
+	}
+	before := deletedCount(t, tfJob.Namespace, string(tfv1alpha2.TFJobSucceeded))
+	if _, err := ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+		t.Fatalf("Unexpected error when syncing jobs %v", err)
+	}
+	if actual == nil || hasFinalizer(actual) {
+		t.Fatalf("Expected the finalizer to be removed, got %v", actual)
+	}
+	if after := deletedCount(t, tfJob.Namespace, string(tfv1alpha2.TFJobSucceeded)); after != before+1 {
+		t.Errorf("Expected the deletion to be counted, got %v then %v", before, after)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, tfJobDeletedReason) {
+			t.Errorf("Expected a %s event, got %s", tfJobDeletedReason, event)
+		}
+	default:
+		t.Errorf("Expected a %s event", tfJobDeletedReason)
+	}
+}
+
+// deletedCount returns the number of deleted tfjobs of the namespace with the final condition.
+func deletedCount(t *testing.T, namespace, condition string) float64 {
+	var metric dto.Metric
+	if err := tfJobsDeletedCount.WithLabelValues(namespace, condition).Write(&metric); err != nil {
+		t.Fatalf("Failed to read the metric: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
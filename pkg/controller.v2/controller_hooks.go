@@ -0,0 +1,123 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/common/jobcontroller"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+)
+
+// The TFJobController implements the hooks of the JobController for TensorFlow.
+var _ jobcontroller.ControllerInterface = &TFJobController{}
+
+// ControllerName returns the name of the tf-operator.
+func (tc *TFJobController) ControllerName() string {
+	return controllerName
+}
+
+// GetAPIGroupVersionKind returns the GroupVersionKind of TFJob.
+func (tc *TFJobController) GetAPIGroupVersionKind() schema.GroupVersionKind {
+	return controllerKind
+}
+
+// GetGroupNameLabelValue returns the API group of TFJob.
+func (tc *TFJobController) GetGroupNameLabelValue() string {
+	return tfv1alpha2.GroupName
+}
+
+// GetJobNameLabelKey returns the label of the pods and services with the name of their tfjob.
+func (tc *TFJobController) GetJobNameLabelKey() string {
+	return generator.LabelTFJobKey
+}
+
+// GetReplicaTypeLabelKey returns the label of the pods and services with their replica type.
+func (tc *TFJobController) GetReplicaTypeLabelKey() string {
+	return tfReplicaTypeLabel
+}
+
+// GetReplicaIndexLabelKey returns the label of the pods and services with their index.
+func (tc *TFJobController) GetReplicaIndexLabelKey() string {
+	return tfReplicaIndexLabel
+}
+
+// GetReplicaAttemptLabelKey returns the label of the pods with their attempt.
+func (tc *TFJobController) GetReplicaAttemptLabelKey() string {
+	return tfReplicaAttemptLabel
+}
+
+// GetDefaultContainerPortName returns the name of the port of the tensorflow container.
+func (tc *TFJobController) GetDefaultContainerPortName() string {
+	return tfv1alpha2.DefaultPortName
+}
+
+// GetJobFromInformerCache returns the tfjob from the unstructured informer.
+func (tc *TFJobController) GetJobFromInformerCache(namespace, name string) (jobcontroller.Job, error) {
+	tfjob, err := tc.getTFJobFromName(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return tfjob, nil
+}
+
+// GetJobFromAPIClient returns the tfjob from the API server.
+func (tc *TFJobController) GetJobFromAPIClient(namespace, name string) (jobcontroller.Job, error) {
+	tfjob, err := tc.tfJobClientSet.KubeflowV1alpha2().TFJobs(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return tfjob, nil
+}
+
+// GetReplicaTypes returns the replica types of the tfjob.
+func (tc *TFJobController) GetReplicaTypes(job jobcontroller.Job) []string {
+	tfjob, ok := job.(*tfv1alpha2.TFJob)
+	if !ok {
+		return nil
+	}
+	rtypes := make([]string, 0, len(tfjob.Spec.TFReplicaSpecs))
+	for rtype := range tfjob.Spec.TFReplicaSpecs {
+		rtypes = append(rtypes, string(rtype))
+	}
+	return rtypes
+}
+
+// SetClusterSpec sets the TF_CONFIG environment variable in the containers of the pod.
+func (tc *TFJobController) SetClusterSpec(job jobcontroller.Job, podTemplate *v1.PodTemplateSpec, rtype, index string) error {
+	tfjob, ok := job.(*tfv1alpha2.TFJob)
+	if !ok {
+		return fmt.Errorf("%v is not a TFJob", job)
+	}
+	return setClusterSpec(podTemplate, tfjob, rtype, index)
+}
+
+// IsMasterRole returns true for the chief of the tfjob, or for the workers if it has no chief.
+func (tc *TFJobController) IsMasterRole(job jobcontroller.Job, rtype string) bool {
+	tfjob, ok := job.(*tfv1alpha2.TFJob)
+	if !ok {
+		return false
+	}
+	if generator.ContainChiefSpec(tfjob) {
+		return rtype == string(tfv1alpha2.TFReplicaTypeChief)
+	}
+	return rtype == string(tfv1alpha2.TFReplicaTypeWorker)
+}
@@ -0,0 +1,178 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/api/core/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+)
+
+const (
+	hostIPEnv   = "TF_HOST_IP"
+	hostPortEnv = "TF_HOST_PORT"
+
+	// unscheduledHost stands for the address of the node of a replica
+	// which is not scheduled yet.
+	unscheduledHost = "<unscheduled>"
+)
+
+// genHostPort returns the port of the replica of the given type and index in
+// the host network. The replicas have consecutive ports in the order of their
+// type and index, from an offset depending on the tfjob so that the tfjobs do
+// not all start at the same port.
+func genHostPort(tfjob *tfv1alpha2.TFJob, rt string, index int) int32 {
+	policy := tfjob.Spec.HostNetwork
+
+	var rts []string
+	replicas := make(map[string]int)
+	for rtype, spec := range tfjob.Spec.TFReplicaSpecs {
+		t := strings.ToLower(string(rtype))
+		rts = append(rts, t)
+		replicas[t] = int(*spec.Replicas)
+	}
+	sort.Strings(rts)
+	ordinal := index
+	for _, t := range rts {
+		if t == rt {
+			break
+		}
+		ordinal += replicas[t]
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(tfjob.Namespace + "/" + tfjob.Name + "/" + string(tfjob.UID)))
+	size := int64(policy.MaxPort-policy.MinPort) + 1
+	return policy.MinPort + int32((int64(h.Sum32())+int64(ordinal))%size)
+}
+
+// getReplicaPort returns the port the replica of the given type and index listens on.
+func getReplicaPort(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType, index string) (int32, error) {
+	if tfjob.Spec.HostNetwork == nil {
+		return generator.GetPortFromTFJob(tfjob, rtype)
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return -1, err
+	}
+	return genHostPort(tfjob, strings.ToLower(string(rtype)), i), nil
+}
+
+// setHostNetwork runs the pod of the replica in the host network, with the
+// port of the replica as the port of the tensorflow container.
+func setHostNetwork(podTemplateSpec *v1.PodTemplateSpec, tfjob *tfv1alpha2.TFJob, rt, index string) error {
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return err
+	}
+	port := genHostPort(tfjob, rt, i)
+
+	podSpec := &podTemplateSpec.Spec
+	podSpec.HostNetwork = true
+	// Keep resolving the names of the cluster.
+	podSpec.DNSPolicy = v1.DNSClusterFirstWithHostNet
+	for c := range podSpec.Containers {
+		container := &podSpec.Containers[c]
+		if container.Name != tfv1alpha2.DefaultContainerName {
+			continue
+		}
+		for j := range container.Ports {
+			if container.Ports[j].Name == tfv1alpha2.DefaultPortName {
+				container.Ports[j].ContainerPort = port
+				container.Ports[j].HostPort = port
+			}
+		}
+		container.Env = append(container.Env,
+			v1.EnvVar{
+				Name: hostIPEnv,
+				ValueFrom: &v1.EnvVarSource{
+					FieldRef: &v1.ObjectFieldSelector{FieldPath: "status.hostIP"},
+				},
+			},
+			v1.EnvVar{
+				Name:  hostPortEnv,
+				Value: strconv.Itoa(int(port)),
+			},
+		)
+	}
+	return nil
+}
+
+// genHostNetworkClusterSpec returns the cluster spec with the addresses of the
+// nodes of the pods of the tfjob. It is complete once all the replicas have a
+// scheduled pod, the address of the others is on unscheduledHost.
+func genHostNetworkClusterSpec(tfjob *tfv1alpha2.TFJob, pods []*v1.Pod) (ClusterSpec, bool) {
+	clusterSpec := make(ClusterSpec)
+	complete := true
+	for rtype, spec := range tfjob.Spec.TFReplicaSpecs {
+		if rtype == tfv1alpha2.TFReplicaTypeEval {
+			// The evaluator is not part of the training cluster.
+			continue
+		}
+		rt := strings.ToLower(string(rtype))
+		addresses := make([]string, 0, *spec.Replicas)
+		for i := 0; i < int(*spec.Replicas); i++ {
+			host := unscheduledHost
+			port := genHostPort(tfjob, rt, i)
+			if pod := getScheduledPod(pods, rt, strconv.Itoa(i)); pod != nil {
+				host = pod.Status.HostIP
+				// The port of a pod created before the replicas changed.
+				if p, ok := getPodPort(pod); ok {
+					port = p
+				}
+			} else {
+				complete = false
+			}
+			addresses = append(addresses, fmt.Sprintf("%s:%d", host, port))
+		}
+		clusterSpec[rt] = addresses
+	}
+	return clusterSpec, complete
+}
+
+// getScheduledPod returns the pod of the replica of the given type and index
+// which is on a node and not being deleted, nil if there is none.
+func getScheduledPod(pods []*v1.Pod, rt, index string) *v1.Pod {
+	for _, pod := range pods {
+		if pod.Labels[tfReplicaTypeLabel] == rt && pod.Labels[tfReplicaIndexLabel] == index &&
+			pod.DeletionTimestamp == nil && pod.Status.HostIP != "" {
+			return pod
+		}
+	}
+	return nil
+}
+
+// getPodPort returns the port of the tensorflow container of the pod.
+func getPodPort(pod *v1.Pod) (int32, bool) {
+	for _, container := range pod.Spec.Containers {
+		if container.Name != tfv1alpha2.DefaultContainerName {
+			continue
+		}
+		for _, port := range container.Ports {
+			if port.Name == tfv1alpha2.DefaultPortName {
+				return port.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
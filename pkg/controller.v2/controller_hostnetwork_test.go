@@ -0,0 +1,158 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func newHostNetworkTFJob(worker, ps int, minPort, maxPort int32) *tfv1alpha2.TFJob {
+	tfJob := testutil.NewTFJob(worker, ps)
+	tfJob.Spec.HostNetwork = &tfv1alpha2.HostNetworkPolicy{MinPort: minPort, MaxPort: maxPort}
+	for _, spec := range tfJob.Spec.TFReplicaSpecs {
+		spec.Template.Spec.Containers[0].Command = []string{"python", "train.py"}
+	}
+	tfv1alpha2.SetDefaults_TFJob(tfJob)
+	return tfJob
+}
+
+func TestGenHostPort(t *testing.T) {
+	// Exactly one port per replica.
+	tfJob := newHostNetworkTFJob(3, 2, 30000, 30004)
+	seen := make(map[int32]string)
+	for rtype, spec := range tfJob.Spec.TFReplicaSpecs {
+		for i := 0; i < int(*spec.Replicas); i++ {
+			port, err := getReplicaPort(tfJob, rtype, fmt.Sprintf("%d", i))
+			if err != nil {
+				t.Fatalf("Failed to get the port of %s %d: %v", rtype, i, err)
+			}
+			replica := fmt.Sprintf("%s-%d", rtype, i)
+			if port < 30000 || port > 30004 {
+				t.Errorf("Expected the port of %s in 30000-30004, got %d", replica, port)
+			}
+			if other, ok := seen[port]; ok {
+				t.Errorf("Expected distinct ports, %s and %s have %d", other, replica, port)
+			}
+			seen[port] = replica
+		}
+	}
+}
+
+func TestSetHostNetwork(t *testing.T) {
+	tfJob := newHostNetworkTFJob(2, 1, 30000, 30100)
+	podTemplate := tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker].Template.DeepCopy()
+	if err := setClusterSpec(podTemplate, tfJob, "worker", "1"); err != nil {
+		t.Fatalf("Failed to set cluster spec: %v", err)
+	}
+
+	if !podTemplate.Spec.HostNetwork || podTemplate.Spec.DNSPolicy != v1.DNSClusterFirstWithHostNet {
+		t.Errorf("Expected the pod in the host network, got %+v", podTemplate.Spec)
+	}
+	expectedPort := genHostPort(tfJob, "worker", 1)
+	port := podTemplate.Spec.Containers[0].Ports[0]
+	if port.ContainerPort != expectedPort || port.HostPort != expectedPort {
+		t.Errorf("Expected the port %d, got %+v", expectedPort, port)
+	}
+	env := make(map[string]v1.EnvVar)
+	for _, e := range podTemplate.Spec.Containers[0].Env {
+		env[e.Name] = e
+	}
+	if e := env[hostIPEnv]; e.ValueFrom == nil || e.ValueFrom.FieldRef.FieldPath != "status.hostIP" {
+		t.Errorf("Expected the IP address of the node in the env, got %+v", e)
+	}
+	if e := env[hostPortEnv]; e.Value != fmt.Sprintf("%d", expectedPort) {
+		t.Errorf("Expected the port %d in the env, got %+v", expectedPort, e)
+	}
+	if _, ok := env[clusterSpecFileEnv]; !ok {
+		t.Errorf("Expected the cluster spec to be delivered by a configmap, got env %+v", podTemplate.Spec.Containers[0].Env)
+	}
+}
+
+func TestHostNetworkClusterSpecConfigMap(t *testing.T) {
+	kubeClientSet := kubefake.NewSimpleClientset()
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+	ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+
+	tfJob := newHostNetworkTFJob(2, 0, 30000, 30100)
+	name := testutil.TestTFJobName + "-cluster-spec"
+	newScheduledPod := func(index int, hostIP string) *v1.Pod {
+		pod := testutil.NewPod(tfJob, "worker", index, t)
+		podTemplate := tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker].Template.DeepCopy()
+		if err := setHostNetwork(podTemplate, tfJob, "worker", fmt.Sprintf("%d", index)); err != nil {
+			t.Fatalf("Failed to set the host network: %v", err)
+		}
+		pod.Spec = podTemplate.Spec
+		pod.Status.HostIP = hostIP
+		return pod
+	}
+	address := func(index int, hostIP string) string {
+		return fmt.Sprintf("%s:%d", hostIP, genHostPort(tfJob, "worker", index))
+	}
+
+	// The configmap waits for all the pods to be scheduled.
+	pods := []*v1.Pod{newScheduledPod(0, "10.0.0.1"), newScheduledPod(1, "")}
+	if err := ctr.reconcileClusterSpecConfigMap(tfJob, pods); err != nil {
+		t.Fatalf("Failed to reconcile the configmap: %v", err)
+	}
+	if _, err := kubeClientSet.CoreV1().ConfigMaps(tfJob.Namespace).Get(name, metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("Expected no configmap before the pods are scheduled, got %v", err)
+	}
+
+	pods[1] = newScheduledPod(1, "10.0.0.2")
+	if err := ctr.reconcileClusterSpecConfigMap(tfJob, pods); err != nil {
+		t.Fatalf("Failed to reconcile the configmap: %v", err)
+	}
+	configMap, err := kubeClientSet.CoreV1().ConfigMaps(tfJob.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the configmap %s to be created: %v", name, err)
+	}
+	expected := `{"worker":["` + address(0, "10.0.0.1") + `","` + address(1, "10.0.0.2") + `"]}`
+	if configMap.Data[tfv1alpha2.ClusterSpecFileName] != expected {
+		t.Errorf("Expected cluster spec %s, got %s", expected, configMap.Data[tfv1alpha2.ClusterSpecFileName])
+	}
+
+	// The address of a pod created again on another node is updated.
+	pods[1] = newScheduledPod(1, "10.0.0.3")
+	if err := ctr.reconcileClusterSpecConfigMap(tfJob, pods); err != nil {
+		t.Fatalf("Failed to reconcile the configmap: %v", err)
+	}
+	configMap, err = kubeClientSet.CoreV1().ConfigMaps(tfJob.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get the configmap %s: %v", name, err)
+	}
+	expected = `{"worker":["` + address(0, "10.0.0.1") + `","` + address(1, "10.0.0.3") + `"]}`
+	if configMap.Data[tfv1alpha2.ClusterSpecFileName] != expected {
+		t.Errorf("Expected updated cluster spec %s, got %s", expected, configMap.Data[tfv1alpha2.ClusterSpecFileName])
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+const (
+	// infrastructureRestartReason is the reason of the event emitted when
+	// a pod lost to an infrastructure failure is recreated.
+	infrastructureRestartReason = "InfrastructureRestart"
+
+	// podConditionDisruptionTarget is set on pods which are about to be
+	// terminated by a disruption, e.g. a preemption or a node drain.
+	podConditionDisruptionTarget v1.PodConditionType = "DisruptionTarget"
+)
+
+// infrastructurePodReasons are the reasons set in the status of pods which
+// are terminated by the cluster rather than by the training code.
+var infrastructurePodReasons = map[string]bool{
+	"Evicted":      true,
+	"NodeLost":     true,
+	"Preempting":   true,
+	"Shutdown":     true,
+	"NodeShutdown": true,
+}
+
+// getInfrastructureFailure returns why the pod has been lost to an
+// infrastructure failure, or an empty string if it has not.
+func getInfrastructureFailure(pod *v1.Pod) string {
+	if pod.Status.Phase == v1.PodSucceeded {
+		return ""
+	}
+	if infrastructurePodReasons[pod.Status.Reason] {
+		if pod.Status.Message != "" {
+			return fmt.Sprintf("%s: %s", pod.Status.Reason, pod.Status.Message)
+		}
+		return pod.Status.Reason
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == podConditionDisruptionTarget && condition.Status == v1.ConditionTrue {
+			return fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+		}
+	}
+	return ""
+}
+
+// getNodeNotReady returns how long the node of the pod has not been ready.
+// A node which does not exist anymore has not been ready for ever.
+func (tc *TFJobController) getNodeNotReady(pod *v1.Pod) (time.Duration, bool, error) {
+	if pod.Spec.NodeName == "" {
+		return 0, false, nil
+	}
+	node, err := tc.nodeLister.Get(pod.Spec.NodeName)
+	if errors.IsNotFound(err) {
+		return time.Duration(1<<63 - 1), true, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			if condition.Status == v1.ConditionTrue {
+				return 0, false, nil
+			}
+			return time.Since(condition.LastTransitionTime.Time), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// reconcileLostPod recreates the pod if it has been lost to an infrastructure
+// failure, whatever the restart policy of the replica. The pod is deleted, so that it
+// is created again on the next sync, and it is counted in the InfrastructureRestarts
+// of the replica instead of as failed. The pods of a finished tfjob are not
+// recreated. It returns true if the pod has been lost.
+func (tc *TFJobController) reconcileLostPod(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType, pod *v1.Pod) (bool, error) {
+	if getFinishTime(tfjob.Status) != nil {
+		// A finished tfjob is not run again.
+		return false, nil
+	}
+	reason := getInfrastructureFailure(pod)
+	force := false
+	// The node of a finished pod may be removed or not ready, it is not run again.
+	if reason == "" && pod.Status.Phase != v1.PodFailed && pod.Status.Phase != v1.PodSucceeded {
+		notReady, ok, err := tc.getNodeNotReady(pod)
+		if err != nil || !ok {
+			return false, err
+		}
+		timeout := tc.config.NodeNotReadyTimeout.Duration
+		if notReady < timeout {
+			// Check again once the timeout has passed.
+			key, err := KeyFunc(tfjob)
+			if err != nil {
+				return false, err
+			}
+			tc.WorkQueue.AddAfter(key, timeout-notReady)
+			return false, nil
+		}
+		reason = fmt.Sprintf("node %s is not ready", pod.Spec.NodeName)
+		// The kubelet of the node can not confirm the deletion of the pod.
+		force = true
+	}
+	if reason == "" {
+		return false, nil
+	}
+
+	if pod.DeletionTimestamp == nil {
+		msg := fmt.Sprintf("Recreating pod %s lost to an infrastructure failure: %s", pod.Name, reason)
+		loggerForTFJob(tfjob).Info(msg)
+		tc.Recorder.Event(tfjob, v1.EventTypeWarning, infrastructureRestartReason, msg)
+		tfjob.Status.TFReplicaStatuses[rtype].InfrastructureRestarts++
+	} else if !force {
+		// The pod is already being deleted.
+		return true, nil
+	}
+
+	if force {
+		return true, tc.deletePodWithGracePeriod(tfjob, pod, 0)
+	}
+	return true, tc.PodControl.DeletePod(pod.Namespace, pod.Name, tfjob)
+}
@@ -0,0 +1,183 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	"github.com/kubeflow/tf-operator/pkg/control"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestInfrastructureRestarts(t *testing.T) {
+	type tc struct {
+		phase         v1.PodPhase
+		reason        string
+		nodeNotReady  time.Duration
+		noNode        bool
+		finished      bool
+		expectedLost  bool
+		expectedForce bool
+	}
+	testCase := []tc{
+		// Evicted pods are recreated.
+		{
+			phase:        v1.PodFailed,
+			reason:       "Evicted",
+			expectedLost: true,
+		},
+		// Pods failed by the training code are not.
+		{
+			phase:  v1.PodFailed,
+			reason: "Error",
+		},
+		// Pods of a node which is not ready for a while are force deleted.
+		{
+			phase:         v1.PodRunning,
+			nodeNotReady:  time.Hour,
+			expectedLost:  true,
+			expectedForce: true,
+		},
+		{
+			phase:        v1.PodRunning,
+			nodeNotReady: time.Minute,
+		},
+		// And so are the pods of a node which has been removed.
+		{
+			phase:         v1.PodRunning,
+			noNode:        true,
+			expectedLost:  true,
+			expectedForce: true,
+		},
+		// But not the succeeded pods.
+		{
+			phase:  v1.PodSucceeded,
+			noNode: true,
+		},
+		// Nor the pods of a finished tfjob.
+		{
+			phase:    v1.PodRunning,
+			noNode:   true,
+			finished: true,
+		},
+	}
+
+	for i, c := range testCase {
+		tfJob := testutil.NewTFJob(2, 0)
+		if c.finished {
+			setCondition(&tfJob.Status, newCondition(tfv1alpha2.TFJobSucceeded, tfJobSucceededReason, ""))
+		}
+		pod := testutil.NewPod(tfJob, testutil.LabelWorker, 1, t)
+		pod.Spec.NodeName = "node-1"
+		pod.Status.Phase = c.phase
+		pod.Status.Reason = c.reason
+
+		// Prepare the clientset and controller for the test.
+		kubeClientSet := kubefake.NewSimpleClientset()
+		config := &rest.Config{
+			Host: "",
+			ContentConfig: rest.ContentConfig{
+				GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+			},
+		}
+		tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+		ctr, kubeInformerFactory, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+		fakePodControl := &control.FakeGracefulPodControl{}
+		ctr.PodControl = fakePodControl
+		tfJobIndexer := ctr.tfJobInformer.GetIndexer()
+		podIndexer := kubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+		nodeIndexer := kubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer()
+
+		var actual *tfv1alpha2.TFJob
+		ctr.updateStatusHandler = func(tfJob *tfv1alpha2.TFJob) error {
+			actual = tfJob
+			return nil
+		}
+
+		unstructured, err := generator.ConvertTFJobToUnstructured(tfJob)
+		if err != nil {
+			t.Errorf("Failed to convert the TFJob to Unstructured: %v", err)
+		}
+		if err := tfJobIndexer.Add(unstructured); err != nil {
+			t.Errorf("Failed to add tfjob to tfJobIndexer: %v", err)
+		}
+
+		testutil.SetPodsStatuses(podIndexer, tfJob, testutil.LabelWorker, 0, 1, 0, 0, t)
+		if err := podIndexer.Add(pod); err != nil {
+			t.Errorf("%d: unexpected error when adding pod %v", i, err)
+		}
+		if !c.noNode {
+			node := &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Status: v1.NodeStatus{
+					Conditions: []v1.NodeCondition{
+						{
+							Type:               v1.NodeReady,
+							Status:             v1.ConditionTrue,
+							LastTransitionTime: metav1.NewTime(time.Now().Add(-24 * time.Hour)),
+						},
+					},
+				},
+			}
+			if c.nodeNotReady != 0 {
+				node.Status.Conditions[0].Status = v1.ConditionUnknown
+				node.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-c.nodeNotReady))
+			}
+			if err := nodeIndexer.Add(node); err != nil {
+				t.Errorf("%d: unexpected error when adding node %v", i, err)
+			}
+		}
+
+		if _, err := ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+			t.Errorf("%d: unexpected error when syncing jobs %v", i, err)
+		}
+
+		status := actual.Status.TFReplicaStatuses[tfv1alpha2.TFReplicaTypeWorker]
+		restarts := int32(0)
+		if c.expectedLost {
+			restarts = 1
+		}
+		if status.InfrastructureRestarts != restarts {
+			t.Errorf("%d: expected %d infrastructure restarts, got %d", i, restarts, status.InfrastructureRestarts)
+		}
+		if c.expectedLost && status.Failed != 0 {
+			t.Errorf("%d: expected the lost pod not to be counted as failed, got %d", i, status.Failed)
+		}
+
+		deleted := len(fakePodControl.DeletePodName)
+		if c.expectedLost && deleted != 1 {
+			t.Errorf("%d: expected the pod to be deleted, got %v", i, fakePodControl.DeletePodName)
+		}
+		gracePeriod, forced := fakePodControl.GracePeriods[pod.Name]
+		forced = forced && gracePeriod == 0
+		if forced != c.expectedForce {
+			t.Errorf("%d: expected force deletion %v, got %v", i, c.expectedForce, forced)
+		}
+		if !c.expectedLost && c.reason == "" && deleted != 0 {
+			t.Errorf("%d: unexpected deleted pods %v", i, fakePodControl.DeletePodName)
+		}
+	}
+}
@@ -18,23 +18,13 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/common/jobcontroller"
 )
 
 func loggerForReplica(tfjob *tfv1alpha2.TFJob, rtype string) *log.Entry {
-	return log.WithFields(log.Fields{
-		// We use job to match the key used in controller.go
-		// In controller.go we log the key used with the workqueue.
-		"job":          tfjob.ObjectMeta.Namespace + "/" + tfjob.ObjectMeta.Name,
-		"uid":          tfjob.ObjectMeta.UID,
-		"replica-type": rtype,
-	})
+	return jobcontroller.LoggerForReplica(tfjob, rtype)
 }
 
 func loggerForTFJob(tfjob *tfv1alpha2.TFJob) *log.Entry {
-	return log.WithFields(log.Fields{
-		// We use job to match the key used in controller.go
-		// In controller.go we log the key used with the workqueue.
-		"job": tfjob.ObjectMeta.Namespace + "/" + tfjob.ObjectMeta.Name,
-		"uid": tfjob.ObjectMeta.UID,
-	})
+	return jobcontroller.LoggerForJob(tfjob)
 }
@@ -0,0 +1,43 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// tfJobsDeletedCount counts the deleted tfjobs by their final condition.
+	tfJobsDeletedCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tf_operator",
+		Name:      "tfjobs_deleted_total",
+		Help:      "Number of deleted TFJobs by namespace and final condition.",
+	}, []string{"namespace", "condition"})
+
+	// tfJobLifetimeSeconds observes how long the deleted tfjobs have existed.
+	tfJobLifetimeSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "tf_operator",
+		Name:      "tfjob_lifetime_seconds",
+		Help:      "Time from the creation to the deletion of the TFJobs by final condition.",
+		// From one minute to about two days.
+		Buckets: prometheus.ExponentialBuckets(60, 2, 12),
+	}, []string{"condition"})
+)
+
+func init() {
+	prometheus.MustRegister(tfJobsDeletedCount)
+	prometheus.MustRegister(tfJobLifetimeSeconds)
+}
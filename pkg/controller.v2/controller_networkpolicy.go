@@ -0,0 +1,151 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+)
+
+// genNetworkPolicyName returns the name of the NetworkPolicy isolating the tfjob.
+func genNetworkPolicyName(tfjob *tfv1alpha2.TFJob) string {
+	return tfjob.Name
+}
+
+// newNetworkPolicy returns the NetworkPolicy isolating the replicas of the
+// tfjob. They only accept connections on their ports, from the pods of the
+// tfjob and from the peers of the isolation policy.
+func newNetworkPolicy(tfjob *tfv1alpha2.TFJob) (*networkingv1.NetworkPolicy, error) {
+	var ports []int
+	seen := make(map[int32]bool)
+	for rtype := range tfjob.Spec.TFReplicaSpecs {
+		port, err := generator.GetPortFromTFJob(tfjob, rtype)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[port] {
+			seen[port] = true
+			ports = append(ports, int(port))
+		}
+	}
+	// The order of the ports is stable, so that the policy is only updated when they change.
+	sort.Ints(ports)
+	protocol := v1.ProtocolTCP
+	var policyPorts []networkingv1.NetworkPolicyPort
+	for _, port := range ports {
+		p := intstr.FromInt(port)
+		policyPorts = append(policyPorts, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &p})
+	}
+
+	labels := generator.GenLabels(tfjob.Name)
+	from := []networkingv1.NetworkPolicyPeer{{
+		PodSelector: &metav1.LabelSelector{MatchLabels: labels},
+	}}
+	from = append(from, tfjob.Spec.NetworkIsolation.AllowFrom...)
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            genNetworkPolicyName(tfjob),
+			Namespace:       tfjob.Namespace,
+			Labels:          generator.GenLabels(tfjob.Name),
+			OwnerReferences: []metav1.OwnerReference{*generator.GenOwnerReference(tfjob)},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			// Only the replicas, the TensorBoard of the tfjob has no replica type.
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: labels,
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      tfReplicaTypeLabel,
+					Operator: metav1.LabelSelectorOpExists,
+				}},
+			},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				Ports: policyPorts,
+				From:  from,
+			}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}, nil
+}
+
+// reconcileNetworkPolicy creates the NetworkPolicy isolating the tfjob if it
+// has an isolation policy, and updates it when the policy or the ports change.
+// It is deleted once the tfjob is deleted.
+func (tc *TFJobController) reconcileNetworkPolicy(tfjob *tfv1alpha2.TFJob) error {
+	if tfjob.Spec.NetworkIsolation == nil {
+		return nil
+	}
+	desired, err := newNetworkPolicy(tfjob)
+	if err != nil {
+		return err
+	}
+
+	policies := tc.KubeClientSet.NetworkingV1().NetworkPolicies(tfjob.Namespace)
+	current, err := policies.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		loggerForTFJob(tfjob).Infof("Need to create the networkpolicy %s", desired.Name)
+		_, err = policies.Create(desired)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(current.Spec, desired.Spec) {
+		return nil
+	}
+	if ref := metav1.GetControllerOf(current); ref == nil || ref.UID != tfjob.UID {
+		return fmt.Errorf("networkpolicy %s already exists and is not owned by tfjob %s", desired.Name, tfjob.Name)
+	}
+	loggerForTFJob(tfjob).Infof("Updating the networkpolicy %s", desired.Name)
+	current = current.DeepCopy()
+	current.Spec = desired.Spec
+	_, err = policies.Update(current)
+	return err
+}
+
+// deleteNetworkPolicy deletes the NetworkPolicy isolating the tfjob if it
+// exists and is owned by the tfjob.
+func (tc *TFJobController) deleteNetworkPolicy(tfjob *tfv1alpha2.TFJob) error {
+	policies := tc.KubeClientSet.NetworkingV1().NetworkPolicies(tfjob.Namespace)
+	current, err := policies.Get(genNetworkPolicyName(tfjob), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if ref := metav1.GetControllerOf(current); ref == nil || ref.UID != tfjob.UID {
+		return nil
+	}
+	loggerForTFJob(tfjob).Infof("Deleting the networkpolicy %s", current.Name)
+	err = policies.Delete(current.Name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
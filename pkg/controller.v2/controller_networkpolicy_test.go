@@ -0,0 +1,112 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestReconcileNetworkPolicy(t *testing.T) {
+	kubeClientSet := kubefake.NewSimpleClientset()
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+	ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+
+	tfJob := testutil.NewTFJob(2, 1)
+	tfv1alpha2.SetDefaults_TFJob(tfJob)
+
+	// Without an isolation policy, the replicas are not isolated.
+	if err := ctr.reconcileNetworkPolicy(tfJob); err != nil {
+		t.Fatalf("Failed to reconcile the networkpolicy: %v", err)
+	}
+	if policies, _ := kubeClientSet.NetworkingV1().NetworkPolicies(tfJob.Namespace).List(metav1.ListOptions{}); len(policies.Items) != 0 {
+		t.Errorf("Expected no networkpolicy, got %v", policies.Items)
+	}
+
+	tfJob.Spec.NetworkIsolation = &tfv1alpha2.NetworkIsolationPolicy{}
+	if err := ctr.reconcileNetworkPolicy(tfJob); err != nil {
+		t.Fatalf("Failed to reconcile the networkpolicy: %v", err)
+	}
+	policy, err := kubeClientSet.NetworkingV1().NetworkPolicies(tfJob.Namespace).Get(testutil.TestTFJobName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the networkpolicy to be created: %v", err)
+	}
+	if ref := metav1.GetControllerOf(policy); ref == nil || ref.Name != tfJob.Name {
+		t.Errorf("Expected the networkpolicy to be owned by the tfjob, got %v", ref)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+	if err != nil {
+		t.Fatalf("Failed to parse the pod selector: %v", err)
+	}
+	worker := testutil.NewPod(tfJob, "worker", 0, t)
+	if !selector.Matches(labels.Set(worker.Labels)) {
+		t.Errorf("Expected the pod selector %v to select the workers", selector)
+	}
+	if selector.Matches(labels.Set(genTensorBoardLabels(tfJob))) {
+		t.Errorf("Expected the pod selector %v not to select the tensorboard", selector)
+	}
+	rules := policy.Spec.Ingress
+	if len(rules) != 1 || len(rules[0].Ports) != 1 || rules[0].Ports[0].Port.IntValue() != tfv1alpha2.DefaultPort {
+		t.Fatalf("Expected an ingress rule on port %d, got %+v", tfv1alpha2.DefaultPort, rules)
+	}
+	if len(rules[0].From) != 1 || !reflect.DeepEqual(rules[0].From[0].PodSelector.MatchLabels, ctr.GenLabels(tfJob.Name)) {
+		t.Errorf("Expected the ingress from the pods of the tfjob, got %+v", rules[0].From)
+	}
+
+	// The policy follows the peers allowed by the tfjob.
+	monitoring := networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "monitoring"}},
+	}
+	tfJob.Spec.NetworkIsolation.AllowFrom = []networkingv1.NetworkPolicyPeer{monitoring}
+	if err := ctr.reconcileNetworkPolicy(tfJob); err != nil {
+		t.Fatalf("Failed to reconcile the networkpolicy: %v", err)
+	}
+	policy, err = kubeClientSet.NetworkingV1().NetworkPolicies(tfJob.Namespace).Get(testutil.TestTFJobName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get the networkpolicy: %v", err)
+	}
+	if from := policy.Spec.Ingress[0].From; len(from) != 2 || !reflect.DeepEqual(from[1], monitoring) {
+		t.Errorf("Expected the ingress from the monitoring namespace, got %+v", from)
+	}
+
+	// A networkpolicy of the same name which is not owned by the tfjob is not overwritten.
+	policy.OwnerReferences = nil
+	if _, err := kubeClientSet.NetworkingV1().NetworkPolicies(tfJob.Namespace).Update(policy); err != nil {
+		t.Fatalf("Failed to update the networkpolicy: %v", err)
+	}
+	tfJob.Spec.NetworkIsolation.AllowFrom = nil
+	if err := ctr.reconcileNetworkPolicy(tfJob); err == nil {
+		t.Errorf("Expected an error for a networkpolicy not owned by the tfjob")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"strings"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+)
+
+// placementWeight is the weight of the preferred terms of the placements,
+// the highest one so that they win over the preferences of the scheduler.
+const placementWeight = 100
+
+// setPlacement adds the affinity terms of the placement of the replica type
+// to the affinity of the pod template, after the ones the user specified.
+func setPlacement(podTemplate *v1.PodTemplateSpec, tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType, placement *tfv1alpha2.Placement) {
+	if placement == nil {
+		return
+	}
+	target := rtype
+	if placement.Policy == tfv1alpha2.PlacementPolicyColocate {
+		target = placement.ColocateWith
+	}
+	term := v1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: genReplicaLabels(tfjob, target),
+		},
+		TopologyKey: placement.TopologyKey,
+	}
+
+	if podTemplate.Spec.Affinity == nil {
+		podTemplate.Spec.Affinity = &v1.Affinity{}
+	}
+	affinity := podTemplate.Spec.Affinity
+	switch placement.Policy {
+	case tfv1alpha2.PlacementPolicySpread:
+		if affinity.PodAntiAffinity == nil {
+			affinity.PodAntiAffinity = &v1.PodAntiAffinity{}
+		}
+		anti := affinity.PodAntiAffinity
+		if placement.Required {
+			anti.RequiredDuringSchedulingIgnoredDuringExecution = append(anti.RequiredDuringSchedulingIgnoredDuringExecution, term)
+		} else {
+			anti.PreferredDuringSchedulingIgnoredDuringExecution = append(anti.PreferredDuringSchedulingIgnoredDuringExecution,
+				v1.WeightedPodAffinityTerm{Weight: placementWeight, PodAffinityTerm: term})
+		}
+	case tfv1alpha2.PlacementPolicyPack, tfv1alpha2.PlacementPolicyColocate:
+		if affinity.PodAffinity == nil {
+			affinity.PodAffinity = &v1.PodAffinity{}
+		}
+		pod := affinity.PodAffinity
+		if placement.Required {
+			pod.RequiredDuringSchedulingIgnoredDuringExecution = append(pod.RequiredDuringSchedulingIgnoredDuringExecution, term)
+		} else {
+			pod.PreferredDuringSchedulingIgnoredDuringExecution = append(pod.PreferredDuringSchedulingIgnoredDuringExecution,
+				v1.WeightedPodAffinityTerm{Weight: placementWeight, PodAffinityTerm: term})
+		}
+	}
+}
+
+// genReplicaLabels returns the labels of the pods of the replica type of the tfjob.
+func genReplicaLabels(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType) map[string]string {
+	labels := generator.GenLabels(tfjob.Name)
+	labels[tfReplicaTypeLabel] = strings.ToLower(string(rtype))
+	return labels
+}
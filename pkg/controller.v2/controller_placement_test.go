@@ -0,0 +1,128 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestSetPlacement(t *testing.T) {
+	userTerm := v1.WeightedPodAffinityTerm{
+		Weight: 10,
+		PodAffinityTerm: v1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			TopologyKey:   "zone",
+		},
+	}
+	userAffinity := &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{{
+					MatchExpressions: []v1.NodeSelectorRequirement{{Key: "gpu", Operator: v1.NodeSelectorOpExists}},
+				}},
+			},
+		},
+		PodAntiAffinity: &v1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{userTerm},
+		},
+	}
+	selector := func(rt string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{
+			"group_name":       tfv1alpha2.GroupName,
+			"tf_job_key":       testutil.TestTFJobName,
+			tfReplicaTypeLabel: rt,
+		}}
+	}
+
+	testCases := map[string]struct {
+		rtype     tfv1alpha2.TFReplicaType
+		placement tfv1alpha2.Placement
+		affinity  *v1.Affinity
+		expected  *v1.Affinity
+	}{
+		"spread merged with the affinity of the user": {
+			rtype:     tfv1alpha2.TFReplicaTypePS,
+			placement: tfv1alpha2.Placement{Policy: tfv1alpha2.PlacementPolicySpread},
+			affinity:  userAffinity,
+			expected: &v1.Affinity{
+				NodeAffinity: userAffinity.NodeAffinity,
+				PodAntiAffinity: &v1.PodAntiAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{userTerm, {
+						Weight: placementWeight,
+						PodAffinityTerm: v1.PodAffinityTerm{
+							LabelSelector: selector("ps"),
+							TopologyKey:   tfv1alpha2.DefaultPlacementTopologyKey,
+						},
+					}},
+				},
+			},
+		},
+		"required pack": {
+			rtype:     tfv1alpha2.TFReplicaTypeWorker,
+			placement: tfv1alpha2.Placement{Policy: tfv1alpha2.PlacementPolicyPack, TopologyKey: "rack", Required: true},
+			expected: &v1.Affinity{
+				PodAffinity: &v1.PodAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{
+						LabelSelector: selector("worker"),
+						TopologyKey:   "rack",
+					}},
+				},
+			},
+		},
+		"colocate with the PS": {
+			rtype:     tfv1alpha2.TFReplicaTypeWorker,
+			placement: tfv1alpha2.Placement{Policy: tfv1alpha2.PlacementPolicyColocate},
+			expected: &v1.Affinity{
+				PodAffinity: &v1.PodAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{{
+						Weight: placementWeight,
+						PodAffinityTerm: v1.PodAffinityTerm{
+							LabelSelector: selector("ps"),
+							TopologyKey:   tfv1alpha2.DefaultPlacementTopologyKey,
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	for name, c := range testCases {
+		tfJob := testutil.NewTFJob(2, 1)
+		spec := tfJob.Spec.TFReplicaSpecs[c.rtype]
+		spec.Template.Spec.Affinity = c.affinity.DeepCopy()
+		placement := c.placement
+		spec.Placement = &placement
+		tfv1alpha2.SetDefaults_TFJob(tfJob)
+
+		podTemplate := spec.Template.DeepCopy()
+		if err := setReplicaPodSpec(podTemplate, tfJob, c.rtype, spec); err != nil {
+			t.Fatalf("%s: failed to set the pod spec: %v", name, err)
+		}
+		if !reflect.DeepEqual(podTemplate.Spec.Affinity, c.expected) {
+			t.Errorf("%s: expected affinity %+v, got %+v", name, c.expected, podTemplate.Spec.Affinity)
+		}
+		if !reflect.DeepEqual(spec.Template.Spec.Affinity, c.affinity) {
+			t.Errorf("%s: expected the affinity of the replica spec to be unchanged, got %+v", name, spec.Template.Spec.Affinity)
+		}
+	}
+}
@@ -17,22 +17,14 @@ package controller
 
 import (
 	"fmt"
-	"reflect"
 	"strconv"
 	"strings"
 
-	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
-	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/client-go/tools/cache"
-	"k8s.io/kubernetes/pkg/controller"
 
 	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
-	"github.com/kubeflow/tf-operator/pkg/generator"
-	train_util "github.com/kubeflow/tf-operator/pkg/util/train"
+	"github.com/kubeflow/tf-operator/pkg/control"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/config"
 )
 
 const (
@@ -42,6 +34,10 @@ const (
 	// podTemplateRestartPolicyReason is the warning reason when the restart
 	// policy is setted in pod template.
 	podTemplateRestartPolicyReason = "SettedPodTemplateRestartPolicy"
+
+	// tfJobRestartingReason is added in a tfjob when it is restarted
+	// by the exit code rules.
+	tfJobRestartingReason = "TFJobRestarting"
 )
 
 // reconcilePods checks and updates pods for each given TFReplicaSpec.
@@ -54,107 +50,185 @@ func (tc *TFJobController) reconcilePods(
 
 	// Convert TFReplicaType to lower string.
 	rt := strings.ToLower(string(rtype))
+	// The replica types starting before this one must be running first.
+	startupAllowed := tc.isStartupAllowed(tfjob, pods, rtype)
 	// Get all pods for the type rt.
-	pods = filterPodsForTFReplicaType(pods, rt)
+	pods = tc.FilterPodsForReplicaType(pods, rt)
 	replicas := int(*spec.Replicas)
 
+	// The attempts of the indexes are kept from the previous status.
+	lastAttempts := getReplicaAttempts(tfjob, rtype)
 	initializeTFReplicaStatuses(tfjob, rtype)
 
-	podSlices := getPodSlices(pods, replicas, loggerForReplica(tfjob, rt))
+	// failure is the reason of the first failed pod.
+	var failure *podProblem
+	podSlices := tc.GetPodSlices(pods, replicas, loggerForReplica(tfjob, rt))
 	for index, podSlice := range podSlices {
-		if len(podSlice) > 1 {
+		// The pods being deleted do not hold their index, the next attempt
+		// is created while they terminate. They may still have to be force
+		// deleted if they have been lost with their node.
+		var current []*v1.Pod
+		for _, pod := range podSlice {
+			if pod.DeletionTimestamp == nil {
+				current = append(current, pod)
+			} else if _, err := tc.reconcileLostPod(tfjob, rtype, pod); err != nil {
+				return err
+			}
+		}
+
+		if len(current) > 1 {
 			loggerForReplica(tfjob, rt).Warningf("We have too many pods for %s %d", rt, index)
 			// TODO(gaocegege): Kill some pods.
-		} else if len(podSlice) == 0 {
-			loggerForReplica(tfjob, rt).Infof("Need to create new pod: %s-%d", rt, index)
-			err := tc.createNewPod(tfjob, rt, strconv.Itoa(index), spec)
+		} else if len(current) == 0 {
+			if !startupAllowed {
+				loggerForReplica(tfjob, rt).Infof("Waiting for the replicas starting first to create pod: %s-%d", rt, index)
+				continue
+			}
+			attempt := tc.nextAttempt(podSlice, lastAttempts[index])
+			loggerForReplica(tfjob, rt).Infof("Need to create new pod: %s-%d, attempt %d", rt, index, attempt)
+			err := tc.createNewPod(tfjob, rtype, strconv.Itoa(index), attempt, spec)
 			if err != nil {
 				return err
 			}
 		} else {
 			// Check the status of the current pod.
-			pod := podSlice[0]
-			// Check if the pod is retryable.
-			if spec.RestartPolicy == tfv1alpha2.RestartPolicyExitCode {
-				var exitCode int32
-				for _, status := range pod.Status.ContainerStatuses {
-					state := status.State
-					// Get the exit code of the tensorflow container.
-					if status.Name == tfv1alpha2.DefaultContainerName && state.Terminated != nil {
-						exitCode = state.Terminated.ExitCode
-					}
-				}
-				if pod.Status.Phase == v1.PodFailed && train_util.IsRetryableExitCode(exitCode) {
-					loggerForReplica(tfjob, rt).Infof("Need to restart the pod: %s-%d", rt, index)
-					if err := tc.podControl.DeletePod(pod.Namespace, pod.Name, tfjob); err != nil {
-						return err
-					}
+			pod := current[0]
+			updateTFReplicaIndexStatus(tfjob, rtype, index, tc.GetPodAttempt(pod), pod)
+			lost, err := tc.reconcileLostPod(tfjob, rtype, pod)
+			if err != nil {
+				return err
+			}
+			if lost {
+				continue
+			}
+			switch podExitCodeAction(pod, spec) {
+			case tfv1alpha2.ExitCodeActionRestartPod:
+				loggerForReplica(tfjob, rt).Infof("Need to restart the pod: %s-%d", rt, index)
+				if err := tc.PodControl.DeletePod(pod.Namespace, pod.Name, tfjob); err != nil {
+					return err
 				}
+			case tfv1alpha2.ExitCodeActionIgnore:
+				// The failure is ignored, count the pod as completed.
+				loggerForReplica(tfjob, rt).Infof("Ignoring the failure of the pod: %s-%d", rt, index)
+				tfjob.Status.TFReplicaStatuses[rtype].Succeeded++
+				continue
+			}
+			if pod.Status.Phase == v1.PodFailed && failure == nil {
+				failure = getPodFailure(pod)
 			}
 			updateTFJobReplicaStatuses(tfjob, rtype, pod)
 		}
 	}
 
-	return updateStatus(tfjob, rtype, replicas)
+	return tc.updateStatus(tfjob, rtype, replicas, failure)
 }
 
-// getPodSlices returns a slice, which element is the slice of pod.
-func getPodSlices(pods []*v1.Pod, replicas int, logger *log.Entry) [][]*v1.Pod {
-	podSlices := make([][]*v1.Pod, replicas)
-	for _, pod := range pods {
-		if _, ok := pod.Labels[tfReplicaIndexLabel]; !ok {
-			logger.Warning("The pod do not have the index label.")
-			continue
+// restartTFJobIfNeeded deletes all the pods of the tfjob if the exit code rules
+// of a failed pod restart the whole job, so that they are all created again.
+// It returns true if the tfjob is restarted.
+func (tc *TFJobController) restartTFJobIfNeeded(tfjob *tfv1alpha2.TFJob, pods []*v1.Pod) (bool, error) {
+	var failedPod *v1.Pod
+	for rtype, spec := range tfjob.Spec.TFReplicaSpecs {
+		for _, pod := range tc.FilterPodsForReplicaType(pods, strings.ToLower(string(rtype))) {
+			if pod.DeletionTimestamp == nil && podExitCodeAction(pod, spec) == tfv1alpha2.ExitCodeActionRestartJob {
+				failedPod = pod
+				break
+			}
 		}
-		index, err := strconv.Atoi(pod.Labels[tfReplicaIndexLabel])
-		if err != nil {
-			logger.Warningf("Error when strconv.Atoi: %v", err)
+	}
+	if failedPod == nil {
+		return false, nil
+	}
+
+	msg := fmt.Sprintf("TFJob %s is restarting because pod %s failed.", tfjob.Name, failedPod.Name)
+	loggerForTFJob(tfjob).Info(msg)
+	if err := tc.deletePods(tfjob, pods); err != nil {
+		return true, err
+	}
+	if err := updateTFJobConditions(tfjob, tfv1alpha2.TFJobRestarting, tfJobRestartingReason, msg); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// deletePods deletes the given pods of the tfjob, unless they are already being deleted.
+func (tc *TFJobController) deletePods(tfjob *tfv1alpha2.TFJob, pods []*v1.Pod) error {
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
 			continue
 		}
-		if index < 0 || index >= replicas {
-			logger.Warningf("The label index is not expected: %d", index)
-		} else {
-			podSlices[index] = append(podSlices[index], pod)
+		if err := tc.PodControl.DeletePod(pod.Namespace, pod.Name, tfjob); err != nil {
+			return err
 		}
 	}
-	return podSlices
+	return nil
 }
 
-// createNewPod creates a new pod for the given index and type.
-func (tc *TFJobController) createNewPod(tfjob *tfv1alpha2.TFJob, rt, index string, spec *tfv1alpha2.TFReplicaSpec) error {
-	tfjobKey, err := KeyFunc(tfjob)
-	if err != nil {
-		utilruntime.HandleError(fmt.Errorf("Couldn't get key for tfjob object %#v: %v", tfjob, err))
-		return err
+// deletePodWithGracePeriod deletes the pod with the grace period in seconds,
+// 0 deletes it immediately. A pod control without grace periods deletes it
+// with the grace period of the pod.
+func (tc *TFJobController) deletePodWithGracePeriod(tfjob *tfv1alpha2.TFJob, pod *v1.Pod, gracePeriod int64) error {
+	if podControl, ok := tc.PodControl.(control.GracefulPodControlInterface); ok {
+		return podControl.DeletePodWithGracePeriod(pod.Namespace, pod.Name, tfjob, gracePeriod)
 	}
-	expectationPodsKey := genExpectationPodsKey(tfjobKey, rt)
-	err = tc.expectations.ExpectCreations(expectationPodsKey, 1)
-	if err != nil {
-		return err
-	}
-
-	// Create OwnerReference.
-	controllerRef := generator.GenOwnerReference(tfjob)
-
-	// Set type and index for the worker.
-	labels := generator.GenLabels(tfjob.Name)
-	labels[tfReplicaTypeLabel] = rt
-	labels[tfReplicaIndexLabel] = index
-
-	podTemplate := spec.Template.DeepCopy()
+	return tc.PodControl.DeletePod(pod.Namespace, pod.Name, tfjob)
+}
 
-	// Set name for the template.
-	podTemplate.Name = generator.GenGeneralName(tfjob.Name, rt, index)
+// podExitCodeAction returns the action of the exit code rules for a failed pod
+// of a replica with the ExitCode restart policy, or an empty action otherwise.
+func podExitCodeAction(pod *v1.Pod, spec *tfv1alpha2.TFReplicaSpec) tfv1alpha2.ExitCodeAction {
+	if spec.RestartPolicy != tfv1alpha2.RestartPolicyExitCode || pod.Status.Phase != v1.PodFailed {
+		return ""
+	}
+	var exitCode int32
+	var reason string
+	for _, status := range pod.Status.ContainerStatuses {
+		state := status.State
+		// Get the exit code of the tensorflow container.
+		if status.Name == tfv1alpha2.DefaultContainerName && state.Terminated != nil {
+			exitCode = state.Terminated.ExitCode
+			reason = state.Terminated.Reason
+		}
+	}
+	return tfv1alpha2.ExitCodeActionFor(spec.ExitCodeRules, exitCode, reason)
+}
 
-	if podTemplate.Labels == nil {
-		podTemplate.Labels = make(map[string]string)
+// getReplicaAttempts returns the attempts of the pods of the indexes of the
+// replica type in the status of the tfjob.
+func getReplicaAttempts(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType) map[int]*int32 {
+	attempts := make(map[int]*int32)
+	status, ok := tfjob.Status.TFReplicaStatuses[rtype]
+	if !ok || status == nil {
+		return attempts
 	}
+	for _, replica := range status.Replicas {
+		attempt := replica.Attempt
+		attempts[int(replica.Index)] = &attempt
+	}
+	return attempts
+}
 
-	for key, value := range labels {
-		podTemplate.Labels[key] = value
+// nextAttempt returns the attempt of the next pod of an index, after the
+// attempts of its pods being deleted and of its last pod in the status.
+func (tc *TFJobController) nextAttempt(pods []*v1.Pod, lastAttempt *int32) int32 {
+	var next int32
+	if lastAttempt != nil {
+		next = *lastAttempt + 1
 	}
+	for _, pod := range pods {
+		if attempt := tc.GetPodAttempt(pod) + 1; attempt > next {
+			next = attempt
+		}
+	}
+	return next
+}
 
-	if err := setClusterSpec(podTemplate, tfjob, rt, index); err != nil {
+// createNewPod creates the pod of the given attempt for the given index and type.
+func (tc *TFJobController) createNewPod(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType, index string, attempt int32, spec *tfv1alpha2.TFReplicaSpec) error {
+	rt := strings.ToLower(string(rtype))
+
+	podTemplate, err := newPodTemplate(tfjob, spec, tc.configStore.Get())
+	if err != nil {
 		return err
 	}
 
@@ -163,27 +237,60 @@ func (tc *TFJobController) createNewPod(tfjob *tfv1alpha2.TFJob, rt, index strin
 	if podTemplate.Spec.RestartPolicy != v1.RestartPolicy("") {
 		errMsg := "Restart policy in pod template will be overwritten by restart policy in replica spec"
 		loggerForReplica(tfjob, rt).Warning(errMsg)
-		tc.recorder.Event(tfjob, v1.EventTypeWarning, podTemplateRestartPolicyReason, errMsg)
+		tc.Recorder.Event(tfjob, v1.EventTypeWarning, podTemplateRestartPolicyReason, errMsg)
+	}
+	if err := setReplicaPodSpec(podTemplate, tfjob, rtype, spec); err != nil {
+		return err
 	}
-	setRestartPolicy(podTemplate, spec)
 
-	err = tc.podControl.CreatePodsWithControllerRef(tfjob.Namespace, podTemplate, tfjob, controllerRef)
-	if err != nil && errors.IsTimeout(err) {
-		// Pod is created but its initialization has timed out.
-		// If the initialization is successful eventually, the
-		// controller will observe the creation via the informer.
-		// If the initialization fails, or if the pod keeps
-		// uninitialized for a long time, the informer will not
-		// receive any update, and the controller will create a new
-		// pod when the expectation expires.
-		return nil
-	} else if err != nil {
+	// The labels, the name and TF_CONFIG are set by the JobController.
+	if err := tc.CreateNewPod(tfjob, rt, index, attempt, podTemplate); err != nil {
+		updatePodProblemCondition(tfjob, getCreatePodProblem(podTemplate.Name, err))
 		return err
 	}
 	return nil
 }
 
+// newPodTemplate returns the template of a pod of the replica. It is merged over
+// the template of the tfjob and over the overlay of its namespace, and the
+// accelerators it uses are configured, following the configuration of the operator.
+func newPodTemplate(tfjob *tfv1alpha2.TFJob, spec *tfv1alpha2.TFReplicaSpec, cfg *config.OperatorConfiguration) (*v1.PodTemplateSpec, error) {
+	podTemplate, err := tfv1alpha2.MergePodTemplate(tfjob.Spec.Template, &spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	if overlay, ok := cfg.NamespaceOverlays[tfjob.Namespace]; ok {
+		podTemplate, err = tfv1alpha2.MergePodTemplate(&overlay, podTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cfg.ConfigureAccelerators(podTemplate, tfv1alpha2.DefaultContainerName)
+	return podTemplate, nil
+}
+
+// setReplicaPodSpec sets the parts of the pod template which come from the
+// replica spec and from the tfjob: the restart policy, the affinity of the
+// placement, the init container of the startup policy and the template hash.
+func setReplicaPodSpec(podTemplate *v1.PodTemplateSpec, tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType, spec *tfv1alpha2.TFReplicaSpec) error {
+	setRestartPolicy(podTemplate, spec)
+	setPlacement(podTemplate, tfjob, rtype, spec.Placement)
+	if err := setPeerWaitContainer(podTemplate, tfjob, rtype); err != nil {
+		return err
+	}
+	return setTemplateHash(podTemplate, tfjob, spec)
+}
+
 func setClusterSpec(podTemplateSpec *v1.PodTemplateSpec, tfjob *tfv1alpha2.TFJob, rt, index string) error {
+	if tfjob.Spec.HostNetwork != nil {
+		if err := setHostNetwork(podTemplateSpec, tfjob, rt, index); err != nil {
+			return err
+		}
+	}
+	if tfjob.Spec.ClusterSpecDelivery == tfv1alpha2.ClusterSpecDeliveryConfigMap {
+		return setClusterSpecConfigMap(podTemplateSpec, tfjob)
+	}
+
 	// Generate TF_CONFIG JSON string.
 	tfConfigStr, err := genTFConfigJSONStr(tfjob, rt, index)
 	if err != nil {
@@ -213,209 +320,3 @@ func setRestartPolicy(podTemplateSpec *v1.PodTemplateSpec, spec *tfv1alpha2.TFRe
 		podTemplateSpec.Spec.RestartPolicy = v1.RestartPolicy(spec.RestartPolicy)
 	}
 }
-
-// getPodsForTFJob returns the set of pods that this tfjob should manage.
-// It also reconciles ControllerRef by adopting/orphaning.
-// Note that the returned Pods are pointers into the cache.
-func (tc *TFJobController) getPodsForTFJob(tfjob *tfv1alpha2.TFJob) ([]*v1.Pod, error) {
-	// Create selector.
-	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
-		MatchLabels: generator.GenLabels(tfjob.Name),
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("couldn't convert Job selector: %v", err)
-	}
-	// List all pods to include those that don't match the selector anymore
-	// but have a ControllerRef pointing to this controller.
-	pods, err := tc.podLister.Pods(tfjob.Namespace).List(labels.Everything())
-	if err != nil {
-		return nil, err
-	}
-
-	// If any adoptions are attempted, we should first recheck for deletion
-	// with an uncached quorum read sometime after listing Pods (see #42639).
-	canAdoptFunc := RecheckDeletionTimestamp(func() (metav1.Object, error) {
-		fresh, err := tc.tfJobClientSet.KubeflowV1alpha2().TFJobs(tfjob.Namespace).Get(tfjob.Name, metav1.GetOptions{})
-		if err != nil {
-			return nil, err
-		}
-		if fresh.UID != tfjob.UID {
-			return nil, fmt.Errorf("original TFJob %v/%v is gone: got uid %v, wanted %v", tfjob.Namespace, tfjob.Name, fresh.UID, tfjob.UID)
-		}
-		return fresh, nil
-	})
-	cm := controller.NewPodControllerRefManager(tc.podControl, tfjob, selector, controllerKind, canAdoptFunc)
-	return cm.ClaimPods(pods)
-}
-
-// filterPodsForTFReplicaType returns pods belong to a TFReplicaType.
-func filterPodsForTFReplicaType(pods []*v1.Pod, tfReplicaType string) []*v1.Pod {
-	var result []*v1.Pod
-
-	tfReplicaSelector := &metav1.LabelSelector{
-		MatchLabels: make(map[string]string),
-	}
-
-	tfReplicaSelector.MatchLabels[tfReplicaTypeLabel] = tfReplicaType
-
-	for _, pod := range pods {
-		selector, _ := metav1.LabelSelectorAsSelector(tfReplicaSelector)
-		if !selector.Matches(labels.Set(pod.Labels)) {
-			continue
-		}
-		result = append(result, pod)
-	}
-	return result
-}
-
-func genExpectationPodsKey(tfjobKey, replicaType string) string {
-	return tfjobKey + "/" + strings.ToLower(replicaType) + "/pods"
-}
-
-// RecheckDeletionTimestamp returns a CanAdopt() function to recheck deletion.
-//
-// The CanAdopt() function calls getObject() to fetch the latest value,
-// and denies adoption attempts if that object has a non-nil DeletionTimestamp.
-func RecheckDeletionTimestamp(getObject func() (metav1.Object, error)) func() error {
-	return func() error {
-		obj, err := getObject()
-		if err != nil {
-			return fmt.Errorf("can't recheck DeletionTimestamp: %v", err)
-		}
-		if obj.GetDeletionTimestamp() != nil {
-			return fmt.Errorf("%v/%v has just been deleted at %v", obj.GetNamespace(), obj.GetName(), obj.GetDeletionTimestamp())
-		}
-		return nil
-	}
-}
-
-// When a pod is created, enqueue the tfjob that manages it and update its expectations.
-func (tc *TFJobController) addPod(obj interface{}) {
-	pod := obj.(*v1.Pod)
-	if pod.DeletionTimestamp != nil {
-		// on a restart of the controller controller, it's possible a new pod shows up in a state that
-		// is already pending deletion. Prevent the pod from being a creation observation.
-		// tc.deletePod(pod)
-		return
-	}
-
-	// If it has a ControllerRef, that's all that matters.
-	if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil {
-		tfjob := tc.resolveControllerRef(pod.Namespace, controllerRef)
-		if tfjob == nil {
-			log.Info("This pod's tfjob does not exists")
-			return
-		}
-
-		tfjobKey, err := KeyFunc(tfjob)
-		if err != nil {
-			loggerForTFJob(tfjob).Infof("Failed to get the key of the tfjob: %v", err)
-			return
-		}
-
-		if _, ok := pod.Labels[tfReplicaTypeLabel]; !ok {
-			loggerForTFJob(tfjob).Info("This pod maybe not created by tf-operator")
-			return
-		}
-
-		rtype := pod.Labels[tfReplicaTypeLabel]
-		expectationPodsKey := genExpectationPodsKey(tfjobKey, rtype)
-
-		tc.expectations.CreationObserved(expectationPodsKey)
-		tc.enqueueTFJob(tfjob)
-
-		return
-	}
-
-	// Otherwise, it's an orphan. Get a list of all matching controllers and sync
-	// them to see if anyone wants to adopt it.
-	// DO NOT observe creation because no controller should be waiting for an
-	// orphan.
-	// for _, tfjob := range tc.getPodJobs(pod) {
-	// 	tc.enqueueTFJob(tfjob)
-	// }
-}
-
-// When a pod is updated, figure out what tfjob/s manage it and wake them up.
-// If the labels of the pod have changed we need to awaken both the old
-// and new replica set. old and cur must be *v1.Pod types.
-func (tc *TFJobController) updatePod(old, cur interface{}) {
-	curPod := cur.(*v1.Pod)
-	oldPod := old.(*v1.Pod)
-	if curPod.ResourceVersion == oldPod.ResourceVersion {
-		// Periodic resync will send update events for all known pods.
-		// Two different versions of the same pod will always have different RVs.
-		return
-	}
-
-	curControllerRef := metav1.GetControllerOf(curPod)
-	oldControllerRef := metav1.GetControllerOf(oldPod)
-	controllerRefChanged := !reflect.DeepEqual(curControllerRef, oldControllerRef)
-	if controllerRefChanged && oldControllerRef != nil {
-		// The ControllerRef was changed. Sync the old controller, if any.
-		if job := tc.resolveControllerRef(oldPod.Namespace, oldControllerRef); job != nil {
-			log.Infof("pod ControllerRef updated: %v, %v", curPod, oldPod)
-			tc.enqueueTFJob(job)
-		}
-	}
-
-	// If it has a ControllerRef, that's all that matters.
-	if curControllerRef != nil {
-		job := tc.resolveControllerRef(curPod.Namespace, curControllerRef)
-		if job == nil {
-			return
-		}
-		log.Infof("pod has a ControllerRef: %v, %v", curPod, oldPod)
-		tc.enqueueTFJob(job)
-		return
-	}
-}
-
-// When a pod is deleted, enqueue the tfjob that manages the pod and update its expectations.
-// obj could be an *v1.Pod, or a DeletionFinalStateUnknown marker item.
-func (tc *TFJobController) deletePod(obj interface{}) {
-	pod, ok := obj.(*v1.Pod)
-
-	// When a delete is dropped, the relist will notice a pod in the store not
-	// in the list, leading to the insertion of a tombstone object which contains
-	// the deleted key/value. Note that this value might be stale. If the pod
-	// changed labels the new job will not be woken up till the periodic resync.
-	if !ok {
-		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
-		if !ok {
-			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %+v", obj))
-			return
-		}
-		pod, ok = tombstone.Obj.(*v1.Pod)
-		if !ok {
-			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a pod %+v", obj))
-			return
-		}
-	}
-
-	controllerRef := metav1.GetControllerOf(pod)
-	if controllerRef == nil {
-		// No controller should care about orphans being deleted.
-		return
-	}
-	tfJob := tc.resolveControllerRef(pod.Namespace, controllerRef)
-	if tfJob == nil {
-		return
-	}
-	tfJobKey, err := controller.KeyFunc(tfJob)
-	if err != nil {
-		return
-	}
-
-	if _, ok := pod.Labels[tfReplicaTypeLabel]; !ok {
-		loggerForTFJob(tfJob).Info("This pod maybe not created by tf-operator")
-		return
-	}
-
-	rtype := pod.Labels[tfReplicaTypeLabel]
-	expectationPodsKey := genExpectationPodsKey(tfJobKey, rtype)
-
-	tc.expectations.DeletionObserved(expectationPodsKey)
-	tc.enqueueTFJob(tfJob)
-}
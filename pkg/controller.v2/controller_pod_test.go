@@ -16,15 +16,22 @@
 package controller
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/kubernetes/pkg/controller"
 
 	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
 	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	tfconfig "github.com/kubeflow/tf-operator/pkg/controller.v2/config"
 	"github.com/kubeflow/tf-operator/pkg/generator"
 	"github.com/kubeflow/tf-operator/pkg/util/testutil"
 )
@@ -47,8 +54,8 @@ func TestAddPod(t *testing.T) {
 	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
 	ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
 	ctr.tfJobInformerSynced = testutil.AlwaysReady
-	ctr.podInformerSynced = testutil.AlwaysReady
-	ctr.serviceInformerSynced = testutil.AlwaysReady
+	ctr.PodInformerSynced = testutil.AlwaysReady
+	ctr.ServiceInformerSynced = testutil.AlwaysReady
 	tfJobIndexer := ctr.tfJobInformer.GetIndexer()
 
 	stopCh := make(chan struct{})
@@ -75,7 +82,7 @@ func TestAddPod(t *testing.T) {
 		t.Errorf("Failed to add tfjob to tfJobIndexer: %v", err)
 	}
 	pod := testutil.NewPod(tfJob, testutil.LabelWorker, 0, t)
-	ctr.addPod(pod)
+	ctr.AddPod(pod)
 
 	syncChan <- "sync"
 	if key != testutil.GetKey(tfJob, t) {
@@ -196,10 +203,10 @@ func TestExitCode(t *testing.T) {
 	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
 	ctr, kubeInformerFactory, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
 	fakePodControl := &controller.FakePodControl{}
-	ctr.podControl = fakePodControl
+	ctr.PodControl = fakePodControl
 	ctr.tfJobInformerSynced = testutil.AlwaysReady
-	ctr.podInformerSynced = testutil.AlwaysReady
-	ctr.serviceInformerSynced = testutil.AlwaysReady
+	ctr.PodInformerSynced = testutil.AlwaysReady
+	ctr.ServiceInformerSynced = testutil.AlwaysReady
 	tfJobIndexer := ctr.tfJobInformer.GetIndexer()
 	podIndexer := kubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
 
@@ -254,3 +261,317 @@ func TestExitCode(t *testing.T) {
 	}
 	close(stopCh)
 }
+
+func TestExitCodeRules(t *testing.T) {
+	type tc struct {
+		rule               tfv1alpha2.ExitCodeRule
+		exitCode           int32
+		expectedDeletions  int
+		expectedSucceeded  int32
+		expectedFailed     int32
+		expectedRestarting bool
+	}
+	testCase := []tc{
+		// The rules of the replica are evaluated before the default rules.
+		{
+			rule:              tfv1alpha2.ExitCodeRule{ExitCodes: &tfv1alpha2.ExitCodeRange{Min: 64, Max: 64}, Action: tfv1alpha2.ExitCodeActionIgnore},
+			exitCode:          64,
+			expectedSucceeded: 1,
+		},
+		{
+			rule:              tfv1alpha2.ExitCodeRule{ExitCodes: &tfv1alpha2.ExitCodeRange{Min: 1, Max: 1}, Action: tfv1alpha2.ExitCodeActionRestartPod},
+			exitCode:          1,
+			expectedDeletions: 1,
+			expectedFailed:    1,
+		},
+		// All the pods are deleted when the job is restarted.
+		{
+			rule:               tfv1alpha2.ExitCodeRule{Signal: tfv1alpha2.Int32(9), Reason: "OOMKilled", Action: tfv1alpha2.ExitCodeActionRestartJob},
+			exitCode:           137,
+			expectedDeletions:  3,
+			expectedRestarting: true,
+		},
+		// Falls back to the default rules.
+		{
+			rule:           tfv1alpha2.ExitCodeRule{ExitCodes: &tfv1alpha2.ExitCodeRange{Min: 64, Max: 64}, Action: tfv1alpha2.ExitCodeActionIgnore},
+			exitCode:       139,
+			expectedFailed: 1,
+		},
+	}
+
+	for i, c := range testCase {
+		f := newSyncFixture()
+
+		tfJob := testutil.NewTFJob(2, 1)
+		workerSpec := tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker]
+		workerSpec.RestartPolicy = tfv1alpha2.RestartPolicyExitCode
+		workerSpec.ExitCodeRules = []tfv1alpha2.ExitCodeRule{c.rule}
+		f.addTFJob(tfJob, t)
+
+		pod := testutil.NewPod(tfJob, testutil.LabelWorker, 1, t)
+		pod.Status.Phase = v1.PodFailed
+		reason := "Error"
+		if c.rule.Reason != "" {
+			reason = c.rule.Reason
+		}
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{
+			Name: tfv1alpha2.DefaultContainerName,
+			State: v1.ContainerState{
+				Terminated: &v1.ContainerStateTerminated{
+					ExitCode: c.exitCode,
+					Reason:   reason,
+				},
+			},
+		})
+		f.addPods([]*v1.Pod{pod}, t)
+		testutil.SetPodsStatuses(f.podIndexer, tfJob, testutil.LabelWorker, 0, 1, 0, 0, t)
+		testutil.SetPodsStatuses(f.podIndexer, tfJob, testutil.LabelPS, 0, 1, 0, 0, t)
+
+		if _, err := f.ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+			t.Errorf("%d: unexpected error when syncing jobs %v", i, err)
+		}
+
+		if len(f.fakePodControl.DeletePodName) != c.expectedDeletions {
+			t.Errorf("%d: expected %d deleted pods, got %v", i, c.expectedDeletions, f.fakePodControl.DeletePodName)
+		}
+		restarting := getCondition(f.actual.Status, tfv1alpha2.TFJobRestarting) != nil
+		if restarting != c.expectedRestarting {
+			t.Errorf("%d: expected restarting condition %v, got %v", i, c.expectedRestarting, restarting)
+		}
+		if c.expectedRestarting {
+			continue
+		}
+		status := f.actual.Status.TFReplicaStatuses[tfv1alpha2.TFReplicaTypeWorker]
+		if status.Succeeded != c.expectedSucceeded || status.Failed != c.expectedFailed {
+			t.Errorf("%d: expected %d succeeded and %d failed workers, got %+v", i, c.expectedSucceeded, c.expectedFailed, status)
+		}
+	}
+}
+
+func TestMergePodTemplate(t *testing.T) {
+	common := &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  tfv1alpha2.DefaultContainerName,
+					Image: "tensorflow/tensorflow:1.8.0",
+					Env: []v1.EnvVar{
+						{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: "/secret/key.json"},
+						{Name: "LOG_LEVEL", Value: "info"},
+					},
+					VolumeMounts: []v1.VolumeMount{{Name: "secret", MountPath: "/secret"}},
+				},
+				{
+					Name:  "sidecar",
+					Image: "fluentd",
+				},
+			},
+			Volumes: []v1.Volume{
+				{Name: "secret", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "gcp"}}},
+			},
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: "registry"}},
+			NodeSelector:     map[string]string{"pool": "training"},
+			Tolerations:      []v1.Toleration{{Key: "dedicated", Value: "training"}},
+		},
+	}
+	replica := &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  tfv1alpha2.DefaultContainerName,
+					Image: "tensorflow/tensorflow:1.8.0-gpu",
+					Env:   []v1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+				},
+			},
+			Volumes:     []v1.Volume{{Name: "data", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}},
+			Tolerations: []v1.Toleration{{Key: "nvidia.com/gpu", Operator: v1.TolerationOpExists}},
+		},
+	}
+
+	merged, err := tfv1alpha2.MergePodTemplate(common, replica)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spec := merged.Spec
+
+	// Containers are matched by name, the values of the replica win.
+	if len(spec.Containers) != 2 {
+		t.Fatalf("Expected 2 containers, got %+v", spec.Containers)
+	}
+	var container, sidecar *v1.Container
+	for i := range spec.Containers {
+		switch spec.Containers[i].Name {
+		case tfv1alpha2.DefaultContainerName:
+			container = &spec.Containers[i]
+		case "sidecar":
+			sidecar = &spec.Containers[i]
+		}
+	}
+	if container == nil || sidecar == nil {
+		t.Fatalf("Expected the tensorflow and sidecar containers, got %+v", spec.Containers)
+	}
+	if container.Image != "tensorflow/tensorflow:1.8.0-gpu" {
+		t.Errorf("Expected the image of the replica, got %s", container.Image)
+	}
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].Name != "secret" {
+		t.Errorf("Expected the volume mounts of the common template, got %+v", container.VolumeMounts)
+	}
+
+	// Env is matched by name.
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	if len(env) != 2 || env["LOG_LEVEL"] != "debug" || env["GOOGLE_APPLICATION_CREDENTIALS"] != "/secret/key.json" {
+		t.Errorf("Unexpected env %+v", container.Env)
+	}
+
+	// Volumes are matched by name.
+	if len(spec.Volumes) != 2 {
+		t.Errorf("Expected the volumes of both templates, got %+v", spec.Volumes)
+	}
+
+	// Lists without a merge key are replaced.
+	if len(spec.Tolerations) != 1 || spec.Tolerations[0].Key != "nvidia.com/gpu" {
+		t.Errorf("Expected the tolerations of the replica, got %+v", spec.Tolerations)
+	}
+
+	if len(spec.ImagePullSecrets) != 1 || spec.NodeSelector["pool"] != "training" {
+		t.Errorf("Expected the values only in the common template, got %+v", spec)
+	}
+
+	// The templates are not modified.
+	if common.Spec.Containers[0].Image != "tensorflow/tensorflow:1.8.0" || len(replica.Spec.Volumes) != 1 {
+		t.Errorf("Expected the templates not to be modified")
+	}
+
+	// Without a common template, the template of the replica is used as is.
+	merged, err = tfv1alpha2.MergePodTemplate(nil, replica)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if merged == replica || !reflect.DeepEqual(merged, replica) {
+		t.Errorf("Expected a copy of the template of the replica, got %+v", merged)
+	}
+}
+
+func TestNewPodTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-operator-config")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	data := `
+apiVersion: tf-operator.kubeflow.org/v1alpha2
+kind: OperatorConfiguration
+namespaceOverlays:
+  default:
+    spec:
+      containers:
+      - name: tensorflow
+        image: overlay
+      imagePullSecrets:
+      - name: registry
+accelerators:
+  nvidia.com/gpu:
+    envVars:
+    - name: LD_LIBRARY_PATH
+      value: /usr/local/nvidia/lib
+`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	kubeClientSet := kubeclientset.NewForConfigOrDie(&rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &v1.SchemeGroupVersion,
+		},
+	},
+	)
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+	ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+	ctr.configStore, err = tfconfig.NewStore(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tfJob := testutil.NewTFJob(1, 0)
+	spec := tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker]
+	spec.Template.Spec.Containers[0].Resources.Limits = v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+
+	podTemplate, err := newPodTemplate(tfJob, spec, ctr.configStore.Get())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	container := podTemplate.Spec.Containers[0]
+	if container.Image != spec.Template.Spec.Containers[0].Image {
+		t.Errorf("Expected the image of the replica to win over the overlay, got %s", container.Image)
+	}
+	if len(podTemplate.Spec.ImagePullSecrets) != 1 {
+		t.Errorf("Expected the image pull secrets of the overlay, got %+v", podTemplate.Spec.ImagePullSecrets)
+	}
+	if len(container.Env) != 1 || container.Env[0].Name != "LD_LIBRARY_PATH" {
+		t.Errorf("Expected the env of the accelerator, got %+v", container.Env)
+	}
+
+	// The overlays of other namespaces are not applied.
+	tfJob.Namespace = "research"
+	podTemplate, err = newPodTemplate(tfJob, spec, ctr.configStore.Get())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(podTemplate.Spec.ImagePullSecrets) != 0 {
+		t.Errorf("Expected no image pull secrets, got %+v", podTemplate.Spec.ImagePullSecrets)
+	}
+}
+
+func TestPodAttempts(t *testing.T) {
+	f := newSyncFixture()
+
+	// The pod of worker 0 has been deleted after its 4th attempt.
+	tfJob := testutil.NewTFJob(2, 1)
+	tfJob.Status.TFReplicaStatuses = map[tfv1alpha2.TFReplicaType]*tfv1alpha2.TFReplicaStatus{
+		tfv1alpha2.TFReplicaTypeWorker: {
+			Replicas: []tfv1alpha2.TFReplicaIndexStatus{{Index: 0, Attempt: 3}},
+		},
+	}
+	f.addTFJob(tfJob, t)
+
+	// The pod of worker 1 is terminating.
+	terminating := testutil.NewPod(tfJob, testutil.LabelWorker, 1, t)
+	terminating.Labels[tfReplicaAttemptLabel] = "1"
+	terminating.Status.Phase = v1.PodRunning
+	now := metav1.Now()
+	terminating.DeletionTimestamp = &now
+	ps := testutil.NewPod(tfJob, testutil.LabelPS, 0, t)
+	ps.Labels[tfReplicaAttemptLabel] = "5"
+	ps.Status.Phase = v1.PodRunning
+	f.addPods([]*v1.Pod{terminating, ps}, t)
+
+	if _, err := f.ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+		t.Errorf("Unexpected error when syncing jobs %v", err)
+	}
+
+	created := make(map[string]string)
+	for _, template := range f.fakePodControl.Templates {
+		created[template.Labels[tfReplicaIndexLabel]] = template.Name
+	}
+	expected := map[string]string{
+		"0": testutil.TestTFJobName + "-worker-0-4",
+		"1": testutil.TestTFJobName + "-worker-1-2",
+	}
+	if !reflect.DeepEqual(created, expected) {
+		t.Errorf("Expected the pods %v, got %v", expected, created)
+	}
+	if replicas := f.actual.Status.TFReplicaStatuses[tfv1alpha2.TFReplicaTypePS].Replicas; len(replicas) != 1 || replicas[0].Attempt != 5 {
+		t.Errorf("Expected the attempt of the PS in the status, got %+v", replicas)
+	}
+}
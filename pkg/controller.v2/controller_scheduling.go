@@ -0,0 +1,108 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/api/core/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+const (
+	// unschedulableReason is added in a tfjob when a pod can not be scheduled.
+	// Unschedulable pods are only reported in the Unschedulable condition.
+	unschedulableReason = "Unschedulable"
+	// schedulingTimeoutReason is added in a tfjob when its pods have been
+	// unschedulable for longer than its scheduling timeout.
+	schedulingTimeoutReason = "SchedulingTimeout"
+)
+
+// reconcileScheduling updates the Unschedulable condition of the tfjob from the
+// PodScheduled conditions of its pods. While a pod is unschedulable, the tfjob is
+// requeued for when its scheduling timeout passes. Once it has passed, the tfjob
+// is failed and its pods are deleted if FailOnSchedulingTimeout is set.
+// It returns true if the tfjob has been failed.
+func (tc *TFJobController) reconcileScheduling(tfjob *tfv1alpha2.TFJob, pods []*v1.Pod) (bool, error) {
+	pod, scheduled := getOldestUnschedulablePod(pods)
+	if pod == nil {
+		removementCondition(&tfjob.Status, tfv1alpha2.TFJobUnschedulable)
+		return false, nil
+	}
+
+	msg := fmt.Sprintf("Pod %s of TFJob %s is unschedulable: %s", pod.Name, tfjob.Name, scheduled.Message)
+	timeout := tfjob.Spec.SchedulingTimeoutSeconds
+	if timeout == nil {
+		setCondition(&tfjob.Status, newCondition(tfv1alpha2.TFJobUnschedulable, unschedulableReason, msg))
+		return false, nil
+	}
+
+	remaining := scheduled.LastTransitionTime.Add(time.Duration(*timeout) * time.Second).Sub(time.Now())
+	if remaining > 0 {
+		setCondition(&tfjob.Status, newCondition(tfv1alpha2.TFJobUnschedulable, unschedulableReason, msg))
+		// Check again once the timeout has passed.
+		key, err := KeyFunc(tfjob)
+		if err != nil {
+			return false, err
+		}
+		tc.WorkQueue.AddAfter(key, remaining)
+		return false, nil
+	}
+
+	msg = fmt.Sprintf("%s, for more than %d seconds", msg, *timeout)
+	setCondition(&tfjob.Status, newCondition(tfv1alpha2.TFJobUnschedulable, schedulingTimeoutReason, msg))
+	if !tfjob.Spec.FailOnSchedulingTimeout {
+		return false, nil
+	}
+
+	loggerForTFJob(tfjob).Infof("Failing the tfjob: %s", msg)
+	failedMsg := fmt.Sprintf("TFJob %s is failed because its pods could not be scheduled in %d seconds.", tfjob.Name, *timeout)
+	if err := updateTFJobConditions(tfjob, tfv1alpha2.TFJobFailed, schedulingTimeoutReason, failedMsg); err != nil {
+		return true, err
+	}
+	return true, tc.deletePods(tfjob, pods)
+}
+
+// isSchedulingTimedOut returns true if the tfjob has been failed by its scheduling timeout.
+func isSchedulingTimedOut(status tfv1alpha2.TFJobStatus) bool {
+	c := getCondition(status, tfv1alpha2.TFJobFailed)
+	return c != nil && c.Reason == schedulingTimeoutReason
+}
+
+// getOldestUnschedulablePod returns the pod which has been unschedulable
+// for the longest time, and its PodScheduled condition.
+func getOldestUnschedulablePod(pods []*v1.Pod) (*v1.Pod, *v1.PodCondition) {
+	var oldest *v1.Pod
+	var oldestCondition *v1.PodCondition
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != v1.PodPending {
+			continue
+		}
+		for i := range pod.Status.Conditions {
+			condition := &pod.Status.Conditions[i]
+			if condition.Type != v1.PodScheduled || condition.Status != v1.ConditionFalse || condition.Reason != v1.PodReasonUnschedulable {
+				continue
+			}
+			if oldest == nil || condition.LastTransitionTime.Before(&oldestCondition.LastTransitionTime) {
+				oldest = pod
+				oldestCondition = condition
+			}
+		}
+	}
+	return oldest, oldestCondition
+}
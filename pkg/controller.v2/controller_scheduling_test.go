@@ -0,0 +1,160 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestSchedulingTimeout(t *testing.T) {
+	type tc struct {
+		timeoutSeconds    *int64
+		fail              bool
+		unschedulableFor  time.Duration
+		expectedReason    string
+		expectedDeletions int
+		expectedFailed    bool
+	}
+	testCase := []tc{
+		{
+			unschedulableFor: time.Hour,
+			expectedReason:   unschedulableReason,
+		},
+		{
+			timeoutSeconds:   tfv1alpha2.Int64(600),
+			fail:             true,
+			unschedulableFor: time.Minute,
+			expectedReason:   unschedulableReason,
+		},
+		{
+			timeoutSeconds:   tfv1alpha2.Int64(600),
+			unschedulableFor: time.Hour,
+			expectedReason:   schedulingTimeoutReason,
+		},
+		{
+			timeoutSeconds:    tfv1alpha2.Int64(600),
+			fail:              true,
+			unschedulableFor:  time.Hour,
+			expectedReason:    schedulingTimeoutReason,
+			expectedDeletions: 2,
+			expectedFailed:    true,
+		},
+	}
+
+	for i, c := range testCase {
+		// Prepare the clientset and controller for the test.
+		kubeClientSet := kubeclientset.NewForConfigOrDie(&rest.Config{
+			Host: "",
+			ContentConfig: rest.ContentConfig{
+				GroupVersion: &v1.SchemeGroupVersion,
+			},
+		},
+		)
+		config := &rest.Config{
+			Host: "",
+			ContentConfig: rest.ContentConfig{
+				GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+			},
+		}
+		tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+		ctr, kubeInformerFactory, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+		fakePodControl := &controller.FakePodControl{}
+		ctr.PodControl = fakePodControl
+		tfJobIndexer := ctr.tfJobInformer.GetIndexer()
+		podIndexer := kubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+
+		var actual *tfv1alpha2.TFJob
+		ctr.updateStatusHandler = func(tfJob *tfv1alpha2.TFJob) error {
+			actual = tfJob
+			return nil
+		}
+
+		tfJob := testutil.NewTFJob(1, 1)
+		tfJob.Spec.SchedulingTimeoutSeconds = c.timeoutSeconds
+		tfJob.Spec.FailOnSchedulingTimeout = c.fail
+		unstructured, err := generator.ConvertTFJobToUnstructured(tfJob)
+		if err != nil {
+			t.Errorf("Failed to convert the TFJob to Unstructured: %v", err)
+		}
+		if err := tfJobIndexer.Add(unstructured); err != nil {
+			t.Errorf("Failed to add tfjob to tfJobIndexer: %v", err)
+		}
+
+		testutil.SetPodsStatuses(podIndexer, tfJob, testutil.LabelPS, 0, 1, 0, 0, t)
+		pod := testutil.NewPod(tfJob, testutil.LabelWorker, 0, t)
+		pod.Status.Phase = v1.PodPending
+		pod.Status.Conditions = []v1.PodCondition{
+			{
+				Type:               v1.PodScheduled,
+				Status:             v1.ConditionFalse,
+				Reason:             v1.PodReasonUnschedulable,
+				Message:            "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-c.unschedulableFor)),
+			},
+		}
+		if err := podIndexer.Add(pod); err != nil {
+			t.Errorf("%d: unexpected error when adding pod %v", i, err)
+		}
+
+		if _, err := ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+			t.Errorf("%d: unexpected error when syncing jobs %v", i, err)
+		}
+
+		condition := getCondition(actual.Status, tfv1alpha2.TFJobUnschedulable)
+		if condition == nil || condition.Reason != c.expectedReason {
+			t.Errorf("%d: expected unschedulable condition with reason %s, got %+v", i, c.expectedReason, condition)
+		}
+		if len(fakePodControl.DeletePodName) != c.expectedDeletions {
+			t.Errorf("%d: expected %d deleted pods, got %v", i, c.expectedDeletions, fakePodControl.DeletePodName)
+		}
+		if failed := isSchedulingTimedOut(actual.Status); failed != c.expectedFailed {
+			t.Errorf("%d: expected failed %v, got %v", i, c.expectedFailed, failed)
+		}
+		if condition := getCondition(actual.Status, tfv1alpha2.TFJobStalled); condition != nil {
+			t.Errorf("%d: expected the unschedulable pod to be reported once, got %+v", i, condition)
+		}
+
+		// The status of a failed tfjob is still saved on the next syncs.
+		if c.expectedFailed {
+			unstructured, err := generator.ConvertTFJobToUnstructured(actual)
+			if err != nil {
+				t.Fatalf("%d: failed to convert the TFJob to Unstructured: %v", i, err)
+			}
+			if err := tfJobIndexer.Update(unstructured); err != nil {
+				t.Fatalf("%d: failed to update tfjob in tfJobIndexer: %v", i, err)
+			}
+			actual = nil
+			if _, err := ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+				t.Errorf("%d: unexpected error when syncing jobs %v", i, err)
+			}
+			if actual == nil || !isSchedulingTimedOut(actual.Status) {
+				t.Errorf("%d: expected the status of the failed tfjob to be saved, got %+v", i, actual)
+			}
+		}
+	}
+}
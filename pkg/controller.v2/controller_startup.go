@@ -0,0 +1,115 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"strings"
+
+	"k8s.io/api/core/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+const (
+	// peerWaitContainerName is the name of the init container waiting for
+	// the peers of a pod.
+	peerWaitContainerName = "wait-for-peers"
+
+	// peerAddressesEnv is the environment variable of the init container
+	// with the addresses to wait for, separated by spaces.
+	peerAddressesEnv = "TF_PEER_ADDRESSES"
+
+	// peerWaitScript blocks until every address of peerAddressesEnv
+	// resolves and accepts connections.
+	peerWaitScript = `for peer in $TF_PEER_ADDRESSES; do
+  host=${peer%:*}
+  port=${peer##*:}
+  until nslookup "$host" >/dev/null 2>&1 && nc -w 2 "$host" "$port" </dev/null >/dev/null 2>&1; do
+    echo "Waiting for $peer"
+    sleep 2
+  done
+done`
+)
+
+// startupPredecessors returns the replica types of the tfjob which start before
+// the given one, in the order of the startup policy. Nothing starts before
+// any replica type if the tfjob has no startup policy.
+func startupPredecessors(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType) []tfv1alpha2.TFReplicaType {
+	policy := tfjob.Spec.StartupPolicy
+	if policy == nil {
+		return nil
+	}
+	var predecessors []tfv1alpha2.TFReplicaType
+	for _, t := range policy.Order {
+		if t == rtype {
+			break
+		}
+		if _, ok := tfjob.Spec.TFReplicaSpecs[t]; ok {
+			predecessors = append(predecessors, t)
+		}
+	}
+	return predecessors
+}
+
+// isStartupAllowed returns true if the pods of the replica type can be created,
+// that is if all the pods of the replica types starting before it are running.
+// A pod which has succeeded counts as running.
+func (tc *TFJobController) isStartupAllowed(tfjob *tfv1alpha2.TFJob, pods []*v1.Pod, rtype tfv1alpha2.TFReplicaType) bool {
+	for _, t := range startupPredecessors(tfjob, rtype) {
+		running := 0
+		for _, pod := range tc.FilterPodsForReplicaType(pods, strings.ToLower(string(t))) {
+			if pod.DeletionTimestamp == nil && (pod.Status.Phase == v1.PodRunning || pod.Status.Phase == v1.PodSucceeded) {
+				running++
+			}
+		}
+		if running < int(*tfjob.Spec.TFReplicaSpecs[t].Replicas) {
+			return false
+		}
+	}
+	return true
+}
+
+// setPeerWaitContainer adds the init container waiting for the addresses of
+// the replica types starting before the given one, if the startup policy of
+// the tfjob waits for the peers.
+func setPeerWaitContainer(podTemplateSpec *v1.PodTemplateSpec, tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType) error {
+	policy := tfjob.Spec.StartupPolicy
+	if policy == nil || !policy.WaitForPeers {
+		return nil
+	}
+	cluster, err := genClusterSpec(tfjob)
+	if err != nil {
+		return err
+	}
+	var peers []string
+	for _, t := range startupPredecessors(tfjob, rtype) {
+		peers = append(peers, cluster[strings.ToLower(string(t))]...)
+	}
+	if len(peers) == 0 {
+		return nil
+	}
+
+	podTemplateSpec.Spec.InitContainers = append(podTemplateSpec.Spec.InitContainers, v1.Container{
+		Name:    peerWaitContainerName,
+		Image:   policy.PeerWaitImage,
+		Command: []string{"sh", "-c", peerWaitScript},
+		Env: []v1.EnvVar{{
+			Name:  peerAddressesEnv,
+			Value: strings.Join(peers, " "),
+		}},
+	})
+	return nil
+}
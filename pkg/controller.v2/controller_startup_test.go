@@ -0,0 +1,148 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	kubeclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	"github.com/kubeflow/tf-operator/pkg/control"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestStartupPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		policy *tfv1alpha2.StartupPolicy
+
+		pendingPSPods int32
+		activePSPods  int32
+
+		expectedPSPodCreations     int
+		expectedWorkerPodCreations int
+		expectedPeers              string
+	}{
+		"no startup policy": {
+			expectedPSPodCreations:     2,
+			expectedWorkerPodCreations: 2,
+		},
+		"PS first, no PS": {
+			policy:                 &tfv1alpha2.StartupPolicy{},
+			expectedPSPodCreations: 2,
+		},
+		"PS first, a PS pending": {
+			policy:        &tfv1alpha2.StartupPolicy{},
+			pendingPSPods: 1,
+			activePSPods:  1,
+		},
+		"PS first, all PS running": {
+			policy:                     &tfv1alpha2.StartupPolicy{},
+			activePSPods:               2,
+			expectedWorkerPodCreations: 2,
+		},
+		"PS first, waiting for peers": {
+			policy:                     &tfv1alpha2.StartupPolicy{WaitForPeers: true},
+			activePSPods:               2,
+			expectedWorkerPodCreations: 2,
+			expectedPeers:              "test-tfjob-ps-0.default.svc.cluster.local:2222 test-tfjob-ps-1.default.svc.cluster.local:2222",
+		},
+		"workers first": {
+			policy:                     &tfv1alpha2.StartupPolicy{Order: []tfv1alpha2.TFReplicaType{tfv1alpha2.TFReplicaTypeWorker}, WaitForPeers: true},
+			expectedWorkerPodCreations: 2,
+		},
+	}
+
+	for name, tc := range testCases {
+		// Prepare the clientset and controller for the test.
+		kubeClientSet := kubeclientset.NewForConfigOrDie(&rest.Config{
+			Host: "",
+			ContentConfig: rest.ContentConfig{
+				GroupVersion: &v1.SchemeGroupVersion,
+			},
+		},
+		)
+		config := &rest.Config{
+			Host: "",
+			ContentConfig: rest.ContentConfig{
+				GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+			},
+		}
+		tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+		ctr, kubeInformerFactory, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+		fakePodControl := &controller.FakePodControl{}
+		ctr.PodControl = fakePodControl
+		ctr.ServiceControl = &control.FakeServiceControl{}
+		ctr.tfJobInformerSynced = testutil.AlwaysReady
+		ctr.PodInformerSynced = testutil.AlwaysReady
+		ctr.ServiceInformerSynced = testutil.AlwaysReady
+		ctr.updateStatusHandler = func(tfJob *tfv1alpha2.TFJob) error {
+			return nil
+		}
+		tfJobIndexer := ctr.tfJobInformer.GetIndexer()
+		podIndexer := kubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+
+		tfJob := testutil.NewTFJob(2, 2)
+		tfJob.Spec.StartupPolicy = tc.policy
+		unstructured, err := generator.ConvertTFJobToUnstructured(tfJob)
+		if err != nil {
+			t.Errorf("%s: failed to convert the TFJob to Unstructured: %v", name, err)
+		}
+		if err := tfJobIndexer.Add(unstructured); err != nil {
+			t.Errorf("%s: failed to add tfjob to tfJobIndexer: %v", name, err)
+		}
+		testutil.SetPodsStatuses(podIndexer, tfJob, testutil.LabelPS, tc.pendingPSPods, tc.activePSPods, 0, 0, t)
+
+		if _, err := ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+			t.Errorf("%s: unexpected error when syncing jobs %v", name, err)
+		}
+
+		psPodCreations, workerPodCreations := 0, 0
+		for _, template := range fakePodControl.Templates {
+			switch template.Labels[tfReplicaTypeLabel] {
+			case testutil.LabelPS:
+				psPodCreations++
+				if len(template.Spec.InitContainers) != 0 {
+					t.Errorf("%s: expected no init container in the PS, got %v", name, template.Spec.InitContainers)
+				}
+			case testutil.LabelWorker:
+				workerPodCreations++
+				peers := ""
+				for _, container := range template.Spec.InitContainers {
+					if container.Name == peerWaitContainerName {
+						peers = container.Env[0].Value
+						if container.Image != tfv1alpha2.DefaultPeerWaitImage {
+							t.Errorf("%s: expected image %s, got %s", name, tfv1alpha2.DefaultPeerWaitImage, container.Image)
+						}
+					}
+				}
+				if peers != tc.expectedPeers {
+					t.Errorf("%s: expected peers %q, got %q", name, tc.expectedPeers, peers)
+				}
+			}
+		}
+		if psPodCreations != tc.expectedPSPodCreations {
+			t.Errorf("%s: expected %d PS pod creations, got %d", name, tc.expectedPSPodCreations, psPodCreations)
+		}
+		if workerPodCreations != tc.expectedWorkerPodCreations {
+			t.Errorf("%s: expected %d worker pod creations, got %d", name, tc.expectedWorkerPodCreations, workerPodCreations)
+		}
+	}
+}
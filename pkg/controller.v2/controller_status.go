@@ -22,7 +22,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
-	"github.com/kubeflow/tf-operator/pkg/generator"
+	"github.com/kubeflow/tf-operator/pkg/common/jobcontroller"
 )
 
 const (
@@ -37,85 +37,38 @@ const (
 )
 
 // updateStatus updates the status of the tfjob.
-func updateStatus(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType, replicas int) error {
-	// Expect to have `replicas - succeeded` pods alive.
-	expected := replicas - int(tfjob.Status.TFReplicaStatuses[rtype].Succeeded)
-	running := int(tfjob.Status.TFReplicaStatuses[rtype].Active)
-	failed := int(tfjob.Status.TFReplicaStatuses[rtype].Failed)
+// failure is the reason why a pod of the replica type failed, if known.
+func (tc *TFJobController) updateStatus(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType, replicas int, failure *podProblem) error {
+	status := tfjob.Status.TFReplicaStatuses[rtype]
+	counts := jobcontroller.ReplicaCounts{
+		Replicas:  replicas,
+		Active:    int(status.Active),
+		Succeeded: int(status.Succeeded),
+		Failed:    int(status.Failed),
+	}
 
 	// All workers are running, set StartTime.
-	if running == replicas && tfjob.Status.StartTime == nil {
+	if counts.Active == replicas && tfjob.Status.StartTime == nil {
 		now := metav1.Now()
 		tfjob.Status.StartTime = &now
 	}
 
-	if generator.ContainChiefSpec(tfjob) {
-		if rtype == tfv1alpha2.TFReplicaTypeChief {
-			if running > 0 {
-				msg := fmt.Sprintf("TFJob %s is running.", tfjob.Name)
-				err := updateTFJobConditions(tfjob, tfv1alpha2.TFJobRunning, tfJobRunningReason, msg)
-				if err != nil {
-					loggerForTFJob(tfjob).Infof("Append tfjob condition error: %v", err)
-					return err
-				}
-			}
-			if expected == 0 {
-				msg := fmt.Sprintf("TFJob %s is successfully completed.", tfjob.Name)
-				err := updateTFJobConditions(tfjob, tfv1alpha2.TFJobSucceeded, tfJobSucceededReason, msg)
-				if err != nil {
-					loggerForTFJob(tfjob).Infof("Append tfjob condition error: %v", err)
-					return err
-				}
-			}
-
-			// Some workers or pss are failed , leave a failed condition.
-			if failed > 0 {
-				msg := fmt.Sprintf("TFJob %s is failed.", tfjob.Name)
-				err := updateTFJobConditions(tfjob, tfv1alpha2.TFJobFailed, tfJobFailedReason, msg)
-				if err != nil {
-					loggerForTFJob(tfjob).Infof("Append tfjob condition error: %v", err)
-					return err
-				}
-			}
-			return nil
-		}
-	} else {
-		if rtype == tfv1alpha2.TFReplicaTypeWorker {
-			// Some workers are still running, leave a running condition.
-			if running > 0 {
-				msg := fmt.Sprintf("TFJob %s is running.", tfjob.Name)
-				err := updateTFJobConditions(tfjob, tfv1alpha2.TFJobRunning, tfJobRunningReason, msg)
-				if err != nil {
-					loggerForTFJob(tfjob).Infof("Append tfjob condition error: %v", err)
-					return err
-				}
-			}
-
-			// All workers are succeeded, leave a succeeded condition.
-			if expected == 0 {
-				msg := fmt.Sprintf("TFJob %s is successfully completed.", tfjob.Name)
-				err := updateTFJobConditions(tfjob, tfv1alpha2.TFJobSucceeded, tfJobSucceededReason, msg)
-				if err != nil {
-					loggerForTFJob(tfjob).Infof("Append tfjob condition error: %v", err)
-					return err
-				}
-			}
-
-			// Some workers or pss are failed , leave a failed condition.
-			if failed > 0 {
-				msg := fmt.Sprintf("TFJob %s is failed.", tfjob.Name)
-				err := updateTFJobConditions(tfjob, tfv1alpha2.TFJobFailed, tfJobFailedReason, msg)
-				if err != nil {
-					loggerForTFJob(tfjob).Infof("Append tfjob condition error: %v", err)
-					return err
-				}
-			}
-			return nil
-		}
+	// The chief, or the workers without chief, decide the conditions of the tfjob.
+	reason, msg := failedConditionReason(tfjob, failure)
+	for _, condition := range tc.ReplicaConditions(tfjob, string(rtype), counts, reason, msg) {
+		setCondition(&tfjob.Status, condition)
 	}
 	return nil
 }
 
+// failedConditionReason returns the reason and message of the failed condition.
+func failedConditionReason(tfjob *tfv1alpha2.TFJob, failure *podProblem) (string, string) {
+	if failure == nil {
+		return tfJobFailedReason, fmt.Sprintf("TFJob %s is failed.", tfjob.Name)
+	}
+	return failure.reason, fmt.Sprintf("TFJob %s is failed because %s.", tfjob.Name, failure.message)
+}
+
 // updateTFJobStatus updates the status of the given TFJob.
 func (tc *TFJobController) updateTFJobStatus(tfjob *tfv1alpha2.TFJob) error {
 	_, err := tc.tfJobClientSet.KubeflowV1alpha2().TFJobs(tfjob.Namespace).Update(tfjob)
@@ -135,12 +88,19 @@ func initializeTFReplicaStatuses(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFRep
 		tfjob.Status.TFReplicaStatuses = make(map[tfv1alpha2.TFReplicaType]*tfv1alpha2.TFReplicaStatus)
 	}
 
-	tfjob.Status.TFReplicaStatuses[rtype] = &tfv1alpha2.TFReplicaStatus{}
+	status := &tfv1alpha2.TFReplicaStatus{}
+	// The counters which are not computed from the current pods are kept.
+	if old, ok := tfjob.Status.TFReplicaStatuses[rtype]; ok && old != nil {
+		status.InfrastructureRestarts = old.InfrastructureRestarts
+	}
+	tfjob.Status.TFReplicaStatuses[rtype] = status
 }
 
 // updateTFJobReplicaStatuses updates the TFJobReplicaStatuses according to the pod.
 func updateTFJobReplicaStatuses(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType, pod *v1.Pod) {
 	switch pod.Status.Phase {
+	case v1.PodPending:
+		tfjob.Status.TFReplicaStatuses[rtype].Pending++
 	case v1.PodRunning:
 		tfjob.Status.TFReplicaStatuses[rtype].Active++
 	case v1.PodSucceeded:
@@ -150,27 +110,46 @@ func updateTFJobReplicaStatuses(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFRepl
 	}
 }
 
+// updateTFReplicaIndexStatus records the status of the pod of the given index
+// and attempt. It must be called in the order of the indexes.
+func updateTFReplicaIndexStatus(tfjob *tfv1alpha2.TFJob, rtype tfv1alpha2.TFReplicaType, index int, attempt int32, pod *v1.Pod) {
+	status := tfv1alpha2.TFReplicaIndexStatus{
+		Index:     int32(index),
+		Attempt:   attempt,
+		PodName:   pod.Name,
+		NodeName:  pod.Spec.NodeName,
+		PodIP:     pod.Status.PodIP,
+		Phase:     pod.Status.Phase,
+		StartTime: pod.Status.StartTime,
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name != tfv1alpha2.DefaultContainerName {
+			continue
+		}
+		status.RestartCount = containerStatus.RestartCount
+		// Prefer the current termination over the previous one.
+		terminated := containerStatus.State.Terminated
+		if terminated == nil {
+			terminated = containerStatus.LastTerminationState.Terminated
+		}
+		if terminated != nil {
+			exitCode := terminated.ExitCode
+			status.LastExitCode = &exitCode
+			status.LastTerminationReason = terminated.Reason
+		}
+	}
+	replicaStatus := tfjob.Status.TFReplicaStatuses[rtype]
+	replicaStatus.Replicas = append(replicaStatus.Replicas, status)
+}
+
 // newCondition creates a new tfjob condition.
 func newCondition(conditionType tfv1alpha2.TFJobConditionType, reason, message string) tfv1alpha2.TFJobCondition {
-	return tfv1alpha2.TFJobCondition{
-		Type:               conditionType,
-		Status:             v1.ConditionTrue,
-		LastUpdateTime:     metav1.Now(),
-		LastTransitionTime: metav1.Now(),
-		Reason:             reason,
-		Message:            message,
-	}
+	return jobcontroller.NewCondition(conditionType, reason, message)
 }
 
 // getCondition returns the condition with the provided type.
 func getCondition(status tfv1alpha2.TFJobStatus, condType tfv1alpha2.TFJobConditionType) *tfv1alpha2.TFJobCondition {
-	for i := range status.Conditions {
-		c := status.Conditions[i]
-		if c.Type == condType {
-			return &c
-		}
-	}
-	return nil
+	return jobcontroller.GetCondition(status.Conditions, condType)
 }
 
 // setCondition updates the tfjob to include the provided condition.
@@ -178,42 +157,18 @@ func getCondition(status tfv1alpha2.TFJobStatus, condType tfv1alpha2.TFJobCondit
 // and has the same status and reason then we are not going to update.
 // If condition is TFJobSucceeded, set CompletionTime.
 func setCondition(status *tfv1alpha2.TFJobStatus, condition tfv1alpha2.TFJobCondition) {
-	currentCond := getCondition(*status, condition.Type)
-
-	// Do nothing if condition doesn't change
-	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason {
+	if !jobcontroller.SetCondition(&status.Conditions, condition) {
 		return
 	}
 
-	// Do not update lastTransitionTime if the status of the condition doesn't change.
-	if currentCond != nil && currentCond.Status == condition.Status {
-		condition.LastTransitionTime = currentCond.LastTransitionTime
-	}
-
 	// if success, update with complete time
 	if condition.Type == tfv1alpha2.TFJobSucceeded && status.CompletionTime == nil {
 		now := metav1.Now()
 		status.CompletionTime = &now
 	}
-
-	// Append the updated condition to the
-	newConditions := filterOutCondition(status.Conditions, condition.Type)
-	status.Conditions = append(newConditions, condition)
 }
 
 // removeCondition removes the tfjob condition with the provided type.
 func removementCondition(status *tfv1alpha2.TFJobStatus, condType tfv1alpha2.TFJobConditionType) {
-	status.Conditions = filterOutCondition(status.Conditions, condType)
-}
-
-// filterOutCondition returns a new slice of tfjob conditions without conditions with the provided type.
-func filterOutCondition(conditions []tfv1alpha2.TFJobCondition, condType tfv1alpha2.TFJobConditionType) []tfv1alpha2.TFJobCondition {
-	var newConditions []tfv1alpha2.TFJobCondition
-	for _, c := range conditions {
-		if c.Type == condType {
-			continue
-		}
-		newConditions = append(newConditions, c)
-	}
-	return newConditions
+	status.Conditions = jobcontroller.FilterOutCondition(status.Conditions, condType)
 }
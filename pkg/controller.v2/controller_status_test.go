@@ -21,9 +21,18 @@ import (
 	"k8s.io/api/core/v1"
 
 	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/common/jobcontroller"
 	"github.com/kubeflow/tf-operator/pkg/util/testutil"
 )
 
+// newStatusController returns a controller which is only able to update
+// the status of the tfjobs.
+func newStatusController() *TFJobController {
+	tc := &TFJobController{}
+	tc.JobController = &jobcontroller.JobController{Controller: tc}
+	return tc
+}
+
 func TestFailed(t *testing.T) {
 	tfJob := testutil.NewTFJob(3, 0)
 	initializeTFReplicaStatuses(tfJob, tfv1alpha2.TFReplicaTypeWorker)
@@ -33,7 +42,7 @@ func TestFailed(t *testing.T) {
 	if tfJob.Status.TFReplicaStatuses[tfv1alpha2.TFReplicaTypeWorker].Failed != 1 {
 		t.Errorf("Failed to set the failed to 1")
 	}
-	err := updateStatus(tfJob, tfv1alpha2.TFReplicaTypeWorker, 3)
+	err := newStatusController().updateStatus(tfJob, tfv1alpha2.TFReplicaTypeWorker, 3, nil)
 	if err != nil {
 		t.Errorf("Expected error %v to be nil", err)
 	}
@@ -48,6 +57,55 @@ func TestFailed(t *testing.T) {
 	}
 }
 
+func TestReplicaIndexStatus(t *testing.T) {
+	tfJob := testutil.NewTFJob(2, 0)
+	initializeTFReplicaStatuses(tfJob, tfv1alpha2.TFReplicaTypeWorker)
+
+	pending := testutil.NewPod(tfJob, testutil.LabelWorker, 0, t)
+	pending.Status.Phase = v1.PodPending
+	failed := testutil.NewPod(tfJob, testutil.LabelWorker, 1, t)
+	failed.Spec.NodeName = "node-1"
+	failed.Status = v1.PodStatus{
+		Phase: v1.PodFailed,
+		PodIP: "10.0.0.1",
+		ContainerStatuses: []v1.ContainerStatus{
+			{
+				Name:         "sidecar",
+				RestartCount: 5,
+			},
+			{
+				Name:         tfv1alpha2.DefaultContainerName,
+				RestartCount: 2,
+				State: v1.ContainerState{
+					Terminated: &v1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+				},
+			},
+		},
+	}
+	for index, pod := range []*v1.Pod{pending, failed} {
+		updateTFReplicaIndexStatus(tfJob, tfv1alpha2.TFReplicaTypeWorker, index, 0, pod)
+		updateTFJobReplicaStatuses(tfJob, tfv1alpha2.TFReplicaTypeWorker, pod)
+	}
+
+	status := tfJob.Status.TFReplicaStatuses[tfv1alpha2.TFReplicaTypeWorker]
+	if status.Pending != 1 || status.Failed != 1 {
+		t.Errorf("Expected 1 pending and 1 failed pod, got %+v", status)
+	}
+	if len(status.Replicas) != 2 {
+		t.Fatalf("Expected the status of 2 replicas, got %+v", status.Replicas)
+	}
+	if r := status.Replicas[0]; r.Index != 0 || r.PodName != pending.Name || r.Phase != v1.PodPending || r.LastExitCode != nil {
+		t.Errorf("Unexpected status of worker 0: %+v", r)
+	}
+	r := status.Replicas[1]
+	if r.Index != 1 || r.PodName != failed.Name || r.NodeName != "node-1" || r.PodIP != "10.0.0.1" || r.Phase != v1.PodFailed {
+		t.Errorf("Unexpected status of worker 1: %+v", r)
+	}
+	if r.RestartCount != 2 || r.LastExitCode == nil || *r.LastExitCode != 137 || r.LastTerminationReason != "OOMKilled" {
+		t.Errorf("Expected the termination of the tensorflow container, got %+v", r)
+	}
+}
+
 func TestStatus(t *testing.T) {
 	type testCase struct {
 		description string
@@ -263,13 +321,13 @@ func TestStatus(t *testing.T) {
 		setStatusForTest(c.tfJob, tfv1alpha2.TFReplicaTypeChief, c.expectedFailedChief, c.expectedSucceededChief, c.expectedActiveChief, t)
 
 		if _, ok := c.tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeChief]; ok {
-			err := updateStatus(c.tfJob, tfv1alpha2.TFReplicaTypeChief, 1)
+			err := newStatusController().updateStatus(c.tfJob, tfv1alpha2.TFReplicaTypeChief, 1, nil)
 			if err != nil {
 				t.Errorf("%s: Expected error %v to be nil", c.description, err)
 			}
 		} else {
 			replicas := c.tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker].Replicas
-			err := updateStatus(c.tfJob, tfv1alpha2.TFReplicaTypeWorker, int(*replicas))
+			err := newStatusController().updateStatus(c.tfJob, tfv1alpha2.TFReplicaTypeWorker, int(*replicas), nil)
 			if err != nil {
 				t.Errorf("%s: Expected error %v to be nil", c.description, err)
 			}
@@ -0,0 +1,267 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+)
+
+const (
+	// tensorBoardLabel is set on the deployment, pods and service of the
+	// TensorBoard of a tfjob. They do not have the replica type label,
+	// so that they are not mistaken for replicas.
+	tensorBoardLabel = "tf-tensorboard"
+
+	tensorBoardContainerName = "tensorboard"
+	tensorBoardPortName      = "tensorboard"
+)
+
+// genTensorBoardName returns the name of the deployment and service of the TensorBoard.
+func genTensorBoardName(tfjob *tfv1alpha2.TFJob) string {
+	return tfjob.Name + "-tensorboard"
+}
+
+// genTensorBoardURL returns the in-cluster URL of the TensorBoard.
+func genTensorBoardURL(tfjob *tfv1alpha2.TFJob) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d", genTensorBoardName(tfjob), tfjob.Namespace, tfv1alpha2.DefaultTensorBoardPort)
+}
+
+// getFinishTime returns when the tfjob has succeeded or failed, nil if it is still running.
+func getFinishTime(status tfv1alpha2.TFJobStatus) *metav1.Time {
+	for _, condition := range status.Conditions {
+		if (condition.Type == tfv1alpha2.TFJobSucceeded || condition.Type == tfv1alpha2.TFJobFailed) &&
+			condition.Status == v1.ConditionTrue {
+			t := condition.LastTransitionTime
+			return &t
+		}
+	}
+	return nil
+}
+
+// reconcileTensorBoard creates the deployment and service of the TensorBoard of
+// the tfjob, updates them when its spec changes, and deletes them once the
+// tfjob has been finished for SecondsAfterFinished.
+func (tc *TFJobController) reconcileTensorBoard(tfjob *tfv1alpha2.TFJob) error {
+	spec := tfjob.Spec.TensorBoard
+	if spec == nil {
+		return nil
+	}
+
+	if finishTime := getFinishTime(tfjob.Status); finishTime != nil && spec.SecondsAfterFinished != nil {
+		keep := time.Duration(*spec.SecondsAfterFinished)*time.Second - time.Since(finishTime.Time)
+		if keep <= 0 {
+			return tc.deleteTensorBoard(tfjob)
+		}
+		// Check again once TensorBoard has to be deleted.
+		key, err := KeyFunc(tfjob)
+		if err != nil {
+			return err
+		}
+		tc.WorkQueue.AddAfter(key, keep)
+	}
+
+	if err := tc.reconcileTensorBoardDeployment(tfjob); err != nil {
+		return err
+	}
+	if err := tc.reconcileTensorBoardService(tfjob); err != nil {
+		return err
+	}
+
+	tfjob.Status.TensorBoardURL = genTensorBoardURL(tfjob)
+	return nil
+}
+
+// reconcileTensorBoardDeployment creates the deployment of the TensorBoard of
+// the tfjob, and updates it when its spec changes.
+func (tc *TFJobController) reconcileTensorBoardDeployment(tfjob *tfv1alpha2.TFJob) error {
+	desired := newTensorBoardDeployment(tfjob)
+	deployments := tc.KubeClientSet.AppsV1().Deployments(tfjob.Namespace)
+	current, err := deployments.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		loggerForTFJob(tfjob).Infof("Need to create the tensorboard deployment %s", desired.Name)
+		_, err = deployments.Create(desired)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if current.Labels[tfTemplateHashLabel] == desired.Labels[tfTemplateHashLabel] {
+		return nil
+	}
+	if ref := metav1.GetControllerOf(current); ref == nil || ref.UID != tfjob.UID {
+		return fmt.Errorf("deployment %s already exists and is not owned by tfjob %s", desired.Name, tfjob.Name)
+	}
+	loggerForTFJob(tfjob).Infof("Updating the tensorboard deployment %s", desired.Name)
+	current = current.DeepCopy()
+	current.Labels = desired.Labels
+	// The selector is immutable and does not change.
+	current.Spec.Replicas = desired.Spec.Replicas
+	current.Spec.Template = desired.Spec.Template
+	_, err = deployments.Update(current)
+	return err
+}
+
+// reconcileTensorBoardService creates the service of the TensorBoard of the
+// tfjob, and updates it when its spec changes.
+func (tc *TFJobController) reconcileTensorBoardService(tfjob *tfv1alpha2.TFJob) error {
+	desired := newTensorBoardService(tfjob)
+	services := tc.KubeClientSet.CoreV1().Services(tfjob.Namespace)
+	current, err := services.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		loggerForTFJob(tfjob).Infof("Need to create the tensorboard service %s", desired.Name)
+		_, err = services.Create(desired)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if current.Labels[tfTemplateHashLabel] == desired.Labels[tfTemplateHashLabel] {
+		return nil
+	}
+	if ref := metav1.GetControllerOf(current); ref == nil || ref.UID != tfjob.UID {
+		return fmt.Errorf("service %s already exists and is not owned by tfjob %s", desired.Name, tfjob.Name)
+	}
+	loggerForTFJob(tfjob).Infof("Updating the tensorboard service %s", desired.Name)
+	current = current.DeepCopy()
+	current.Labels = desired.Labels
+	// The cluster IP is kept.
+	current.Spec.Selector = desired.Spec.Selector
+	current.Spec.Ports = desired.Spec.Ports
+	_, err = services.Update(current)
+	return err
+}
+
+// setTensorBoardHash sets the hash of the spec in the labels of the deployment
+// or service of the TensorBoard. The hashes are compared rather than the specs,
+// which the apiserver defaults.
+func setTensorBoardHash(meta *metav1.ObjectMeta, spec interface{}) {
+	hasher := fnv.New32a()
+	hashutil.DeepHashObject(hasher, spec)
+	meta.Labels[tfTemplateHashLabel] = fmt.Sprintf("%08x", hasher.Sum32())
+}
+
+// deleteTensorBoard deletes the deployment and service of the TensorBoard of the tfjob.
+func (tc *TFJobController) deleteTensorBoard(tfjob *tfv1alpha2.TFJob) error {
+	if tfjob.Status.TensorBoardURL == "" {
+		// Already deleted.
+		return nil
+	}
+	name := genTensorBoardName(tfjob)
+	loggerForTFJob(tfjob).Infof("Deleting the tensorboard %s of the finished tfjob", name)
+	propagation := metav1.DeletePropagationBackground
+	err := tc.KubeClientSet.AppsV1().Deployments(tfjob.Namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	err = tc.KubeClientSet.CoreV1().Services(tfjob.Namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	tfjob.Status.TensorBoardURL = ""
+	return nil
+}
+
+// genTensorBoardLabels returns the labels of the TensorBoard of the tfjob.
+// The service keeps the labels of the tfjob, otherwise the tfjob would release it.
+func genTensorBoardLabels(tfjob *tfv1alpha2.TFJob) map[string]string {
+	labels := generator.GenLabels(tfjob.Name)
+	labels[tensorBoardLabel] = tfjob.Name
+	return labels
+}
+
+// newTensorBoardDeployment returns the deployment running the TensorBoard of the tfjob.
+func newTensorBoardDeployment(tfjob *tfv1alpha2.TFJob) *appsv1.Deployment {
+	spec := tfjob.Spec.TensorBoard
+	labels := genTensorBoardLabels(tfjob)
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            genTensorBoardName(tfjob),
+			Labels:          genTensorBoardLabels(tfjob),
+			OwnerReferences: []metav1.OwnerReference{*generator.GenOwnerReference(tfjob)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  tensorBoardContainerName,
+							Image: spec.Image,
+							Command: []string{
+								"tensorboard",
+								"--logdir=" + spec.LogDir,
+								fmt.Sprintf("--port=%d", tfv1alpha2.DefaultTensorBoardPort),
+							},
+							Ports: []v1.ContainerPort{
+								{
+									Name:          tensorBoardPortName,
+									ContainerPort: tfv1alpha2.DefaultTensorBoardPort,
+								},
+							},
+							VolumeMounts: spec.VolumeMounts,
+						},
+					},
+					Volumes: spec.Volumes,
+				},
+			},
+		},
+	}
+	setTensorBoardHash(&deployment.ObjectMeta, deployment.Spec)
+	return deployment
+}
+
+// newTensorBoardService returns the service exposing the TensorBoard of the tfjob.
+func newTensorBoardService(tfjob *tfv1alpha2.TFJob) *v1.Service {
+	labels := genTensorBoardLabels(tfjob)
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            genTensorBoardName(tfjob),
+			Labels:          genTensorBoardLabels(tfjob),
+			OwnerReferences: []metav1.OwnerReference{*generator.GenOwnerReference(tfjob)},
+		},
+		Spec: v1.ServiceSpec{
+			Selector: labels,
+			Ports: []v1.ServicePort{
+				{
+					Name: tensorBoardPortName,
+					Port: tfv1alpha2.DefaultTensorBoardPort,
+				},
+			},
+		},
+	}
+	setTensorBoardHash(&service.ObjectMeta, service.Spec)
+	return service
+}
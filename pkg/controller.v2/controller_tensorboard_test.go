@@ -0,0 +1,158 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestTensorBoard(t *testing.T) {
+	type tc struct {
+		finishedFor    time.Duration
+		exists         bool
+		oldLogDir      string
+		expectedExists bool
+	}
+	testCase := []tc{
+		// TensorBoard is created for a running tfjob.
+		{
+			expectedExists: true,
+		},
+		// It keeps running for a while after the tfjob finished.
+		{
+			finishedFor:    time.Minute,
+			exists:         true,
+			expectedExists: true,
+		},
+		// It is updated when its spec changes.
+		{
+			exists:         true,
+			oldLogDir:      "gs://bucket/old",
+			expectedExists: true,
+		},
+		// And is deleted afterwards.
+		{
+			finishedFor: time.Hour,
+			exists:      true,
+		},
+	}
+
+	for i, c := range testCase {
+		tfJob := testutil.NewTFJob(1, 0)
+		tfJob.Spec.TensorBoard = &tfv1alpha2.TensorBoardSpec{
+			LogDir:               "gs://bucket/mnist",
+			SecondsAfterFinished: tfv1alpha2.Int64(600),
+		}
+		if c.finishedFor != 0 {
+			tfJob.Status.Conditions = append(tfJob.Status.Conditions, newCondition(tfv1alpha2.TFJobSucceeded, tfJobSucceededReason, "TFJob succeeded."))
+			tfJob.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-c.finishedFor))
+		}
+
+		// Prepare the clientset and controller for the test.
+		kubeClientSet := kubefake.NewSimpleClientset()
+		if c.exists {
+			tfJob.Status.TensorBoardURL = genTensorBoardURL(tfJob)
+			existing := tfJob.DeepCopy()
+			tfv1alpha2.SetDefaults_TFJob(existing)
+			if c.oldLogDir != "" {
+				existing.Spec.TensorBoard.LogDir = c.oldLogDir
+			}
+			kubeClientSet = kubefake.NewSimpleClientset(newTensorBoardDeployment(existing), newTensorBoardService(existing))
+		}
+		config := &rest.Config{
+			Host: "",
+			ContentConfig: rest.ContentConfig{
+				GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+			},
+		}
+		tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+		ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+		ctr.PodControl = &controller.FakePodControl{}
+		tfJobIndexer := ctr.tfJobInformer.GetIndexer()
+
+		var actual *tfv1alpha2.TFJob
+		ctr.updateStatusHandler = func(tfJob *tfv1alpha2.TFJob) error {
+			actual = tfJob
+			return nil
+		}
+
+		unstructured, err := generator.ConvertTFJobToUnstructured(tfJob)
+		if err != nil {
+			t.Errorf("Failed to convert the TFJob to Unstructured: %v", err)
+		}
+		if err := tfJobIndexer.Add(unstructured); err != nil {
+			t.Errorf("Failed to add tfjob to tfJobIndexer: %v", err)
+		}
+
+		if _, err := ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+			t.Errorf("%d: unexpected error when syncing jobs %v", i, err)
+		}
+
+		name := genTensorBoardName(tfJob)
+		deployment, err := kubeClientSet.AppsV1().Deployments(tfJob.Namespace).Get(name, metav1.GetOptions{})
+		if exists := err == nil; exists != c.expectedExists {
+			t.Errorf("%d: expected the deployment to exist %v, got %v", i, c.expectedExists, err)
+		}
+		_, err = kubeClientSet.CoreV1().Services(tfJob.Namespace).Get(name, metav1.GetOptions{})
+		if exists := err == nil; exists != c.expectedExists {
+			t.Errorf("%d: expected the service to exist %v, got %v", i, c.expectedExists, err)
+		}
+
+		expectedURL := ""
+		if c.expectedExists {
+			expectedURL = "http://" + name + "." + tfJob.Namespace + ".svc:6006"
+			container := deployment.Spec.Template.Spec.Containers[0]
+			if container.Image != tfv1alpha2.DefaultTensorBoardImage || container.Command[1] != "--logdir=gs://bucket/mnist" {
+				t.Errorf("%d: unexpected tensorboard container %+v", i, container)
+			}
+			if ref := metav1.GetControllerOf(deployment); ref == nil || ref.Name != tfJob.Name {
+				t.Errorf("%d: expected the deployment to be owned by the tfjob, got %v", i, ref)
+			}
+		}
+		if actual.Status.TensorBoardURL != expectedURL {
+			t.Errorf("%d: expected tensorboard URL %q, got %q", i, expectedURL, actual.Status.TensorBoardURL)
+		}
+	}
+}
+
+func TestGetFinishTime(t *testing.T) {
+	status := tfv1alpha2.TFJobStatus{}
+	if getFinishTime(status) != nil {
+		t.Errorf("Expected no finish time for a tfjob without conditions")
+	}
+	status.Conditions = []tfv1alpha2.TFJobCondition{
+		newCondition(tfv1alpha2.TFJobRunning, tfJobRunningReason, ""),
+	}
+	if getFinishTime(status) != nil {
+		t.Errorf("Expected no finish time for a running tfjob")
+	}
+	failed := newCondition(tfv1alpha2.TFJobFailed, tfJobFailedReason, "")
+	status.Conditions = append(status.Conditions, failed)
+	if finishTime := getFinishTime(status); finishTime == nil || !finishTime.Equal(&failed.LastTransitionTime) {
+		t.Errorf("Expected the finish time to be %v, got %v", failed.LastTransitionTime, finishTime)
+	}
+}
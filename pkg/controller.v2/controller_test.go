@@ -23,12 +23,14 @@ import (
 	kubeinformers "k8s.io/client-go/informers"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/kubernetes/pkg/controller"
 
 	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
 	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
 	tfjobinformers "github.com/kubeflow/tf-operator/pkg/client/informers/externalversions"
 	"github.com/kubeflow/tf-operator/pkg/control"
+	tfconfig "github.com/kubeflow/tf-operator/pkg/controller.v2/config"
 	"github.com/kubeflow/tf-operator/pkg/generator"
 	"github.com/kubeflow/tf-operator/pkg/util/testutil"
 )
@@ -50,14 +52,75 @@ func newTFJobController(
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClientSet, resyncPeriod())
 	tfJobInformerFactory := tfjobinformers.NewSharedInformerFactory(tfJobClientSet, resyncPeriod())
 
-	tfJobInformer := NewUnstructuredTFJobInformer(config)
+	tfJobInformer := NewUnstructuredTFJobInformer(config, resyncPeriod())
 
-	ctr := NewTFJobController(tfJobInformer, kubeClientSet, tfJobClientSet, kubeInformerFactory, tfJobInformerFactory)
-	ctr.podControl = &controller.FakePodControl{}
-	ctr.serviceControl = &control.FakeServiceControl{}
+	configStore, _ := tfconfig.NewStore("")
+	ctr := NewTFJobController(tfJobInformer, kubeClientSet, tfJobClientSet, kubeInformerFactory, tfJobInformerFactory, configStore)
+	ctr.PodControl = &controller.FakePodControl{}
+	ctr.ServiceControl = &control.FakeServiceControl{}
+	ctr.nodeInformerSynced = testutil.AlwaysReady
 	return ctr, kubeInformerFactory, tfJobInformerFactory
 }
 
+// syncFixture is a controller syncing tfjobs with a fake pod control. The
+// tfjob saved by the last sync is kept in actual.
+type syncFixture struct {
+	ctr            *TFJobController
+	fakePodControl *controller.FakePodControl
+	podIndexer     cache.Indexer
+	actual         *tfv1alpha2.TFJob
+}
+
+// newSyncFixture prepares the clientset and controller for a test syncing tfjobs.
+func newSyncFixture() *syncFixture {
+	kubeClientSet := kubeclientset.NewForConfigOrDie(&rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &v1.SchemeGroupVersion,
+		},
+	},
+	)
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+	ctr, kubeInformerFactory, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+	f := &syncFixture{
+		ctr:            ctr,
+		fakePodControl: &controller.FakePodControl{},
+		podIndexer:     kubeInformerFactory.Core().V1().Pods().Informer().GetIndexer(),
+	}
+	ctr.PodControl = f.fakePodControl
+	ctr.updateStatusHandler = func(tfJob *tfv1alpha2.TFJob) error {
+		f.actual = tfJob
+		return nil
+	}
+	return f
+}
+
+// addTFJob adds the tfjob to the informer of the controller.
+func (f *syncFixture) addTFJob(tfJob *tfv1alpha2.TFJob, t *testing.T) {
+	unstructured, err := generator.ConvertTFJobToUnstructured(tfJob)
+	if err != nil {
+		t.Fatalf("Failed to convert the TFJob to Unstructured: %v", err)
+	}
+	if err := f.ctr.tfJobInformer.GetIndexer().Add(unstructured); err != nil {
+		t.Fatalf("Failed to add tfjob to tfJobIndexer: %v", err)
+	}
+}
+
+// addPods adds the pods to the informer of the controller.
+func (f *syncFixture) addPods(pods []*v1.Pod, t *testing.T) {
+	for _, pod := range pods {
+		if err := f.podIndexer.Add(pod); err != nil {
+			t.Fatalf("Unexpected error when adding pod %v", err)
+		}
+	}
+}
+
 func TestNormalPath(t *testing.T) {
 	testCases := map[string]struct {
 		worker int
@@ -216,8 +279,8 @@ func TestNormalPath(t *testing.T) {
 		tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
 		ctr, kubeInformerFactory, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
 		ctr.tfJobInformerSynced = testutil.AlwaysReady
-		ctr.podInformerSynced = testutil.AlwaysReady
-		ctr.serviceInformerSynced = testutil.AlwaysReady
+		ctr.PodInformerSynced = testutil.AlwaysReady
+		ctr.ServiceInformerSynced = testutil.AlwaysReady
 		tfJobIndexer := ctr.tfJobInformer.GetIndexer()
 
 		var actual *tfv1alpha2.TFJob
@@ -260,8 +323,8 @@ func TestNormalPath(t *testing.T) {
 			t.Errorf("%s: unexpected forget value. Expected %v, saw %v\n", name, tc.jobKeyForget, forget)
 		}
 
-		fakePodControl := ctr.podControl.(*controller.FakePodControl)
-		fakeServiceControl := ctr.serviceControl.(*control.FakeServiceControl)
+		fakePodControl := ctr.PodControl.(*controller.FakePodControl)
+		fakeServiceControl := ctr.ServiceControl.(*control.FakeServiceControl)
 		if int32(len(fakePodControl.Templates)) != tc.expectedPodCreations {
 			t.Errorf("%s: unexpected number of pod creates.  Expected %d, saw %d\n", name, tc.expectedPodCreations, len(fakePodControl.Templates))
 		}
@@ -346,8 +409,8 @@ func TestRun(t *testing.T) {
 	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
 	ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
 	ctr.tfJobInformerSynced = testutil.AlwaysReady
-	ctr.podInformerSynced = testutil.AlwaysReady
-	ctr.serviceInformerSynced = testutil.AlwaysReady
+	ctr.PodInformerSynced = testutil.AlwaysReady
+	ctr.ServiceInformerSynced = testutil.AlwaysReady
 
 	stopCh := make(chan struct{})
 	go func() {
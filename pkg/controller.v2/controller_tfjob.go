@@ -8,10 +8,14 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 
 	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/validation"
 )
 
 const (
 	failedMarshalTFJobReason = "FailedMarshalTFJob"
+	// invalidTFJobSpecReason is added in a tfjob when it is failed because
+	// its spec is invalid.
+	invalidTFJobSpecReason = "InvalidTFJobSpec"
 )
 
 // When a pod is added, set the defaults and enqueue the current tfjob.
@@ -24,7 +28,7 @@ func (tc *TFJobController) addTFJob(obj interface{}) {
 		if err == errFailedMarshal {
 			errMsg := fmt.Sprintf("Failed to unmarshal the object to TFJob object: %v", err)
 			log.Warn(errMsg)
-			tc.recorder.Event(tfJob, v1.EventTypeWarning, failedMarshalTFJobReason, errMsg)
+			tc.Recorder.Event(tfJob, v1.EventTypeWarning, failedMarshalTFJobReason, errMsg)
 		}
 		return
 	}
@@ -54,3 +58,24 @@ func (tc *TFJobController) updateTFJob(old, cur interface{}) {
 	log.Infof("Updating tfjob: %s", oldTFJob.Name)
 	tc.enqueueTFJob(cur)
 }
+
+// reconcileInvalidTFJob fails the tfjob and deletes its pods if its spec is
+// invalid. The tfjobs created without a validating client, e.g. with kubectl,
+// are only validated here. It returns true if the tfjob is invalid.
+func (tc *TFJobController) reconcileInvalidTFJob(tfjob *tfv1alpha2.TFJob, pods []*v1.Pod) (bool, error) {
+	err := validation.ValidateV1Alpha2TFJobSpec(&tfjob.Spec)
+	if err == nil {
+		return false, nil
+	}
+	oldConditions := append([]tfv1alpha2.TFJobCondition{}, tfjob.Status.Conditions...)
+	msg := fmt.Sprintf("TFJob %s is failed because its spec is invalid: %v", tfjob.Name, err)
+	if c := getCondition(tfjob.Status, tfv1alpha2.TFJobFailed); c == nil || c.Reason != invalidTFJobSpecReason {
+		loggerForTFJob(tfjob).Info(msg)
+	}
+	setCondition(&tfjob.Status, newCondition(tfv1alpha2.TFJobFailed, invalidTFJobSpecReason, msg))
+	if err := tc.deletePods(tfjob, pods); err != nil {
+		return true, err
+	}
+	tc.recordConditionEvents(tfjob, oldConditions)
+	return true, tc.updateStatusHandler(tfjob)
+}
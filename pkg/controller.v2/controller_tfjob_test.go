@@ -46,8 +46,8 @@ func TestAddTFJob(t *testing.T) {
 	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
 	ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
 	ctr.tfJobInformerSynced = testutil.AlwaysReady
-	ctr.podInformerSynced = testutil.AlwaysReady
-	ctr.serviceInformerSynced = testutil.AlwaysReady
+	ctr.PodInformerSynced = testutil.AlwaysReady
+	ctr.ServiceInformerSynced = testutil.AlwaysReady
 	tfJobIndexer := ctr.tfJobInformer.GetIndexer()
 
 	stopCh := make(chan struct{})
@@ -102,10 +102,10 @@ func TestCopyLabelsAndAnnotation(t *testing.T) {
 	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
 	ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
 	fakePodControl := &controller.FakePodControl{}
-	ctr.podControl = fakePodControl
+	ctr.PodControl = fakePodControl
 	ctr.tfJobInformerSynced = testutil.AlwaysReady
-	ctr.podInformerSynced = testutil.AlwaysReady
-	ctr.serviceInformerSynced = testutil.AlwaysReady
+	ctr.PodInformerSynced = testutil.AlwaysReady
+	ctr.ServiceInformerSynced = testutil.AlwaysReady
 	tfJobIndexer := ctr.tfJobInformer.GetIndexer()
 
 	stopCh := make(chan struct{})
@@ -163,3 +163,30 @@ func TestCopyLabelsAndAnnotation(t *testing.T) {
 
 	close(stopCh)
 }
+
+func TestInvalidTFJob(t *testing.T) {
+	f := newSyncFixture()
+
+	// The ports allocated on the nodes can only be delivered by a ConfigMap.
+	tfJob := newHostNetworkTFJob(1, 0, 30000, 30010)
+	tfJob.Spec.ClusterSpecDelivery = tfv1alpha2.ClusterSpecDeliveryEnv
+	f.addTFJob(tfJob, t)
+	pod := testutil.NewPod(tfJob, testutil.LabelWorker, 0, t)
+	pod.Status.Phase = v1.PodRunning
+	f.addPods([]*v1.Pod{pod}, t)
+
+	if _, err := f.ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+		t.Errorf("Unexpected error when syncing jobs %v", err)
+	}
+
+	condition := getCondition(f.actual.Status, tfv1alpha2.TFJobFailed)
+	if condition == nil || condition.Reason != invalidTFJobSpecReason {
+		t.Errorf("Expected the tfjob to be failed because of its spec, got %+v", f.actual.Status.Conditions)
+	}
+	if len(f.fakePodControl.Templates) != 0 {
+		t.Errorf("Expected no created pods, got %d", len(f.fakePodControl.Templates))
+	}
+	if len(f.fakePodControl.DeletePodName) != 1 {
+		t.Errorf("Expected the pod to be deleted, got %v", f.fakePodControl.DeletePodName)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+const (
+	// tfTemplateHashLabel is set on the pods to the hash of the templates and
+	// of the cluster spec they have been created from.
+	tfTemplateHashLabel = "tf-template-hash"
+
+	// tfJobUpdatingReason is added in a tfjob when its pods are recreated
+	// because its templates changed.
+	tfJobUpdatingReason = "TFJobUpdating"
+	// outdatedPodsReason is added in a tfjob when its pods are outdated
+	// but its update strategy leaves them running.
+	outdatedPodsReason = "OutdatedPods"
+)
+
+// genTemplateHash returns the hash of what the pods of the replica are created
+// from and which the user may change: the template of the replica, the template
+// of the tfjob and the cluster spec.
+func genTemplateHash(tfjob *tfv1alpha2.TFJob, spec *tfv1alpha2.TFReplicaSpec) (string, error) {
+	cluster, err := genClusterSpec(tfjob)
+	if err != nil {
+		return "", err
+	}
+	hasher := fnv.New32a()
+	hashutil.DeepHashObject(hasher, struct {
+		Template    v1.PodTemplateSpec
+		JobTemplate *v1.PodTemplateSpec
+		Cluster     ClusterSpec
+	}{spec.Template, tfjob.Spec.Template, cluster})
+	return fmt.Sprintf("%08x", hasher.Sum32()), nil
+}
+
+// setTemplateHash sets the hash of the templates of the replica in the labels of the pod template.
+func setTemplateHash(podTemplate *v1.PodTemplateSpec, tfjob *tfv1alpha2.TFJob, spec *tfv1alpha2.TFReplicaSpec) error {
+	hash, err := genTemplateHash(tfjob, spec)
+	if err != nil {
+		return err
+	}
+	if podTemplate.Labels == nil {
+		podTemplate.Labels = make(map[string]string)
+	}
+	podTemplate.Labels[tfTemplateHashLabel] = hash
+	return nil
+}
+
+// reconcileUpdate compares the hash of the pods of the running tfjob with the
+// hash of its current templates, and updates its pods according to its update
+// strategy. The Updating condition is set while there are outdated pods. The
+// pods without hash, created by an older operator, are considered up to date.
+// With the Recreate strategy, it returns true while outdated pods exist, so
+// that no pod is created until all of them are gone: TF clusters cannot mix
+// versions.
+func (tc *TFJobController) reconcileUpdate(tfjob *tfv1alpha2.TFJob, pods []*v1.Pod) (bool, error) {
+	if getFinishTime(tfjob.Status) != nil {
+		// A finished tfjob is not run again.
+		return false, nil
+	}
+
+	// outdated counts the outdated pods, running counts the ones not being
+	// deleted, terminating keeps the others with their replica type.
+	outdated, running := 0, 0
+	terminating := make(map[*v1.Pod]tfv1alpha2.TFReplicaType)
+	for rtype, spec := range tfjob.Spec.TFReplicaSpecs {
+		hash, err := genTemplateHash(tfjob, spec)
+		if err != nil {
+			return false, err
+		}
+		for _, pod := range tc.FilterPodsForReplicaType(pods, strings.ToLower(string(rtype))) {
+			if podHash, ok := pod.Labels[tfTemplateHashLabel]; !ok || podHash == hash {
+				continue
+			}
+			outdated++
+			if pod.DeletionTimestamp == nil {
+				running++
+			} else {
+				terminating[pod] = rtype
+			}
+		}
+	}
+	if outdated == 0 {
+		removementCondition(&tfjob.Status, tfv1alpha2.TFJobUpdating)
+		return false, nil
+	}
+
+	if tfjob.Spec.UpdateStrategy == tfv1alpha2.UpdateStrategyOnDelete {
+		msg := fmt.Sprintf("%d pods of TFJob %s run an outdated template, they are updated once deleted.", outdated, tfjob.Name)
+		setCondition(&tfjob.Status, newCondition(tfv1alpha2.TFJobUpdating, outdatedPodsReason, msg))
+		return false, nil
+	}
+
+	msg := fmt.Sprintf("TFJob %s is recreating its pods because its template changed.", tfjob.Name)
+	setCondition(&tfjob.Status, newCondition(tfv1alpha2.TFJobUpdating, tfJobUpdatingReason, msg))
+	if running == 0 {
+		// The outdated pods are being deleted, the new ones are created once
+		// they are gone. The pods of lost nodes never terminate on their own.
+		for pod, rtype := range terminating {
+			if _, err := tc.reconcileLostPod(tfjob, rtype, pod); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+	loggerForTFJob(tfjob).Info(msg)
+	// All the pods, the up to date ones can not join a cluster of outdated ones.
+	return true, tc.deletePods(tfjob, pods)
+}
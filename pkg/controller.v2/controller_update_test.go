@@ -0,0 +1,150 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestGenTemplateHash(t *testing.T) {
+	tfJob := testutil.NewTFJob(2, 1)
+	tfv1alpha2.SetDefaults_TFJob(tfJob)
+	workerSpec := tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker]
+	hash := func() string {
+		h, err := genTemplateHash(tfJob, workerSpec)
+		if err != nil {
+			t.Fatalf("Failed to hash the template: %v", err)
+		}
+		return h
+	}
+
+	original := hash()
+	tfJob.Status.Conditions = append(tfJob.Status.Conditions, newCondition(tfv1alpha2.TFJobRunning, tfJobRunningReason, ""))
+	if h := hash(); h != original {
+		t.Errorf("Expected the hash not to depend on the status, got %s and %s", original, h)
+	}
+
+	workerSpec.Template.Spec.Containers[0].Args = []string{"--steps=100"}
+	changed := hash()
+	if changed == original {
+		t.Errorf("Expected the hash to change with the args of the container")
+	}
+
+	*tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypePS].Replicas = 2
+	if h := hash(); h == changed {
+		t.Errorf("Expected the hash of the workers to change with the cluster spec")
+	}
+}
+
+func TestReconcileUpdate(t *testing.T) {
+	type tc struct {
+		strategy          tfv1alpha2.UpdateStrategy
+		outdated          bool
+		withoutHash       bool
+		terminating       bool
+		expectedDeletions int
+		expectedReason    string
+	}
+	testCases := map[string]tc{
+		"up to date": {
+			strategy: tfv1alpha2.UpdateStrategyRecreate,
+		},
+		"recreate": {
+			strategy:          tfv1alpha2.UpdateStrategyRecreate,
+			outdated:          true,
+			expectedDeletions: 3,
+			expectedReason:    tfJobUpdatingReason,
+		},
+		// No pod is created until the outdated ones are gone.
+		"recreating": {
+			strategy:       tfv1alpha2.UpdateStrategyRecreate,
+			outdated:       true,
+			terminating:    true,
+			expectedReason: tfJobUpdatingReason,
+		},
+		"on delete": {
+			strategy:       tfv1alpha2.UpdateStrategyOnDelete,
+			outdated:       true,
+			expectedReason: outdatedPodsReason,
+		},
+		"pods without hash": {
+			strategy:    tfv1alpha2.UpdateStrategyRecreate,
+			outdated:    true,
+			withoutHash: true,
+		},
+	}
+
+	for name, c := range testCases {
+		f := newSyncFixture()
+
+		// The pods are created from the templates of the tfjob before it is edited.
+		tfJob := testutil.NewTFJob(2, 1)
+		tfJob.Spec.UpdateStrategy = c.strategy
+		created := tfJob.DeepCopy()
+		tfv1alpha2.SetDefaults_TFJob(created)
+		for rt, replicas := range map[string]int{testutil.LabelWorker: 2, testutil.LabelPS: 1} {
+			spec := created.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker]
+			if rt == testutil.LabelPS {
+				spec = created.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypePS]
+			}
+			hash, err := genTemplateHash(created, spec)
+			if err != nil {
+				t.Fatalf("%s: failed to hash the template: %v", name, err)
+			}
+			for index := 0; index < replicas; index++ {
+				pod := testutil.NewPod(tfJob, rt, index, t)
+				pod.Status.Phase = v1.PodRunning
+				if !c.withoutHash {
+					pod.Labels[tfTemplateHashLabel] = hash
+				}
+				if c.terminating {
+					pod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				}
+				f.addPods([]*v1.Pod{pod}, t)
+			}
+		}
+
+		if c.outdated {
+			tfJob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker].Template.Spec.Containers[0].Image = "tensorflow/tensorflow:1.9.0"
+		}
+		f.addTFJob(tfJob, t)
+
+		if _, err := f.ctr.syncTFJob(testutil.GetKey(tfJob, t)); err != nil {
+			t.Errorf("%s: unexpected error when syncing jobs %v", name, err)
+		}
+
+		if len(f.fakePodControl.DeletePodName) != c.expectedDeletions {
+			t.Errorf("%s: expected %d deleted pods, got %v", name, c.expectedDeletions, f.fakePodControl.DeletePodName)
+		}
+		if len(f.fakePodControl.Templates) != 0 {
+			t.Errorf("%s: expected no created pods, got %d", name, len(f.fakePodControl.Templates))
+		}
+		condition := getCondition(f.actual.Status, tfv1alpha2.TFJobUpdating)
+		if c.expectedReason == "" && condition != nil {
+			t.Errorf("%s: expected no updating condition, got %+v", name, condition)
+		}
+		if c.expectedReason != "" && (condition == nil || condition.Reason != c.expectedReason) {
+			t.Errorf("%s: expected updating condition with reason %s, got %+v", name, c.expectedReason, condition)
+		}
+	}
+}
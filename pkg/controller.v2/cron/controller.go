@@ -0,0 +1,252 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cron provides a Kubernetes controller for a CronTFJob resource,
+// which runs a TFJob on a cron schedule.
+package cron
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	tfjobscheme "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned/scheme"
+	tfjobinformersv1alpha2 "github.com/kubeflow/tf-operator/pkg/client/informers/externalversions/kubeflow/v1alpha2"
+	tfjoblisters "github.com/kubeflow/tf-operator/pkg/client/listers/kubeflow/v1alpha2"
+)
+
+const controllerName = "crontfjob-controller"
+
+var (
+	// controllerKind is GroupVersionKind for this controller type.
+	controllerKind = tfv1alpha2.CronGroupVersionKind
+
+	// KeyFunc is the short name to DeletionHandlingMetaNamespaceKeyFunc.
+	KeyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
+)
+
+// CronTFJobController is the type for CronTFJob Controller, which creates
+// the TFJobs of the CronTFJobs on their schedule.
+type CronTFJobController struct {
+	// clock is the time source of the schedules, injected for testing.
+	clock clock.Clock
+
+	// tfJobClientSet is a clientset for CRD TFJob and CronTFJob.
+	tfJobClientSet tfjobclientset.Interface
+
+	// To allow injection of syncCronTFJob for testing.
+	syncHandler func(cronKey string) error
+
+	// To allow injection of updateCronTFJobStatus for testing.
+	updateStatusHandler func(cron *tfv1alpha2.CronTFJob) error
+
+	// cronLister can list/get crontfjobs from the shared informer's store.
+	cronLister tfjoblisters.CronTFJobLister
+
+	// tfJobInformer is the unstructured informer of the TFJob controller,
+	// shared to follow the TFJobs of the runs.
+	tfJobInformer cache.SharedIndexInformer
+
+	// cronInformerSynced returns true if the crontfjob store has been synced at least once.
+	cronInformerSynced cache.InformerSynced
+
+	// tfJobInformerSynced returns true if the tfjob store has been synced at least once.
+	tfJobInformerSynced cache.InformerSynced
+
+	// workQueue is a rate limited work queue of crontfjob keys. A crontfjob
+	// is added back after the time of its next run.
+	workQueue workqueue.RateLimitingInterface
+
+	// recorder is an event recorder for recording Event resources to the
+	// Kubernetes API.
+	recorder record.EventRecorder
+}
+
+// NewCronTFJobController returns a new CronTFJob controller.
+func NewCronTFJobController(
+	cronInformer tfjobinformersv1alpha2.CronTFJobInformer,
+	// This is the unstructured informer of the TFJob controller.
+	tfJobInformer tfjobinformersv1alpha2.TFJobInformer,
+	kubeClientSet kubeclientset.Interface,
+	tfJobClientSet tfjobclientset.Interface) *CronTFJobController {
+
+	tfjobscheme.AddToScheme(scheme.Scheme)
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(log.Infof)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
+
+	cc := &CronTFJobController{
+		clock:          clock.RealClock{},
+		tfJobClientSet: tfJobClientSet,
+		workQueue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), tfv1alpha2.CronPlural),
+		recorder:       eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: controllerName}),
+	}
+	cc.syncHandler = cc.syncCronTFJob
+	cc.updateStatusHandler = cc.updateCronTFJobStatus
+
+	cronInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: cc.enqueueCronTFJob,
+		UpdateFunc: func(old, cur interface{}) {
+			cc.enqueueCronTFJob(cur)
+		},
+		DeleteFunc: cc.enqueueCronTFJob,
+	})
+	cc.cronLister = cronInformer.Lister()
+	cc.cronInformerSynced = cronInformer.Informer().HasSynced
+
+	// The crontfjob of a TFJob is synced when the TFJob changes, e.g. to
+	// start a run forbidden while the TFJob was running.
+	tfJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: cc.enqueueCronTFJobOfTFJob,
+		UpdateFunc: func(old, cur interface{}) {
+			cc.enqueueCronTFJobOfTFJob(cur)
+		},
+		DeleteFunc: cc.enqueueCronTFJobOfTFJob,
+	})
+	cc.tfJobInformer = tfJobInformer.Informer()
+	cc.tfJobInformerSynced = tfJobInformer.Informer().HasSynced
+
+	return cc
+}
+
+// Run syncs the informer caches and starts the workers. It blocks until
+// stopCh is closed.
+func (cc *CronTFJobController) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer cc.workQueue.ShutDown()
+
+	log.Info("Starting CronTFJob controller")
+
+	if ok := cache.WaitForCacheSync(stopCh, cc.cronInformerSynced, cc.tfJobInformerSynced); !ok {
+		return fmt.Errorf("failed to wait for crontfjob and tfjob caches to sync")
+	}
+
+	log.Infof("Starting %v CronTFJob workers", threadiness)
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(cc.runWorker, time.Second, stopCh)
+	}
+
+	<-stopCh
+	log.Info("Shutting down CronTFJob workers")
+
+	return nil
+}
+
+func (cc *CronTFJobController) runWorker() {
+	for cc.processNextWorkItem() {
+	}
+}
+
+func (cc *CronTFJobController) processNextWorkItem() bool {
+	key, quit := cc.workQueue.Get()
+	if quit {
+		return false
+	}
+	defer cc.workQueue.Done(key)
+
+	if err := cc.syncHandler(key.(string)); err != nil {
+		utilruntime.HandleError(fmt.Errorf("Error syncing crontfjob: %v", err))
+		cc.workQueue.AddRateLimited(key)
+		return true
+	}
+	cc.workQueue.Forget(key)
+	return true
+}
+
+func (cc *CronTFJobController) enqueueCronTFJob(cron interface{}) {
+	key, err := KeyFunc(cron)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for crontfjob object %#v: %v", cron, err))
+		return
+	}
+	cc.workQueue.Add(key)
+}
+
+// enqueueCronTFJobOfTFJob enqueues the crontfjob controlling the tfjob, if any.
+func (cc *CronTFJobController) enqueueCronTFJobOfTFJob(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	tfjob, ok := obj.(metav1.Object)
+	if !ok {
+		return
+	}
+	controllerRef := metav1.GetControllerOf(tfjob)
+	if controllerRef == nil || controllerRef.Kind != controllerKind.Kind {
+		return
+	}
+	cc.workQueue.Add(tfjob.GetNamespace() + "/" + controllerRef.Name)
+}
+
+// syncCronTFJob starts the due run of the crontfjob with the given key, and
+// requeues it for its next run.
+// This function is not meant to be invoked concurrently with the same key.
+func (cc *CronTFJobController) syncCronTFJob(key string) error {
+	startTime := time.Now()
+	defer func() {
+		log.Infof("Finished syncing crontfjob %q (%v)", key, time.Since(startTime))
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	sharedCron, err := cc.cronLister.CronTFJobs(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Infof("CronTFJob has been deleted: %v", key)
+			return nil
+		}
+		return err
+	}
+
+	cron := sharedCron.DeepCopy()
+	scheme.Scheme.Default(cron)
+
+	requeueAfter, err := cc.reconcileCronTFJob(cron)
+	if err != nil {
+		return err
+	}
+	if !apiequality.Semantic.DeepEqual(sharedCron.Status, cron.Status) {
+		if err := cc.updateStatusHandler(cron); err != nil {
+			return err
+		}
+	}
+	if requeueAfter > 0 {
+		cc.workQueue.AddAfter(key, requeueAfter)
+	}
+	return nil
+}
+
+func (cc *CronTFJobController) updateCronTFJobStatus(cron *tfv1alpha2.CronTFJob) error {
+	_, err := cc.tfJobClientSet.KubeflowV1alpha2().CronTFJobs(cron.Namespace).Update(cron)
+	return err
+}
@@ -0,0 +1,299 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobfake "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned/fake"
+	tfjobinformers "github.com/kubeflow/tf-operator/pkg/client/informers/externalversions"
+	controller "github.com/kubeflow/tf-operator/pkg/controller.v2"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+var (
+	// The crontfjob runs hourly from 10:00.
+	creationTime = time.Date(2018, time.June, 13, 9, 30, 0, 0, time.UTC)
+	firstRun     = time.Date(2018, time.June, 13, 10, 0, 0, 0, time.UTC)
+)
+
+func newCronTFJob() *tfv1alpha2.CronTFJob {
+	return &tfv1alpha2.CronTFJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "retrain",
+			Namespace:         metav1.NamespaceDefault,
+			UID:               "b6b5bd2c-6c2b-11e8-9c5a-42010a8a0002",
+			CreationTimestamp: metav1.NewTime(creationTime),
+		},
+		Spec: tfv1alpha2.CronTFJobSpec{
+			Schedule: "0 * * * *",
+			JobTemplate: tfv1alpha2.TFJobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"model": "mnist"},
+				},
+				Spec: testutil.NewTFJob(1, 0).Spec,
+			},
+		},
+	}
+}
+
+func newCronTFJobController(now time.Time) (*CronTFJobController, *tfjobfake.Clientset, cache.Indexer, cache.Indexer) {
+	tfJobClientSet := tfjobfake.NewSimpleClientset()
+	tfJobInformerFactory := tfjobinformers.NewSharedInformerFactory(tfJobClientSet, 0)
+	cronInformer := tfJobInformerFactory.Kubeflow().V1alpha2().CronTFJobs()
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobInformer := controller.NewUnstructuredTFJobInformer(config, 0)
+
+	cc := NewCronTFJobController(cronInformer, tfJobInformer, kubefake.NewSimpleClientset(), tfJobClientSet)
+	cc.clock = clock.NewFakeClock(now)
+	return cc, tfJobClientSet, cronInformer.Informer().GetIndexer(), tfJobInformer.Informer().GetIndexer()
+}
+
+// addTFJob adds a tfjob of the crontfjob, scheduled at scheduledTime, to the
+// clientset and the tfjob indexer.
+func addTFJob(t *testing.T, tfJobClientSet *tfjobfake.Clientset, tfJobIndexer cache.Indexer, cron *tfv1alpha2.CronTFJob, scheduledTime time.Time, condition tfv1alpha2.TFJobConditionType) {
+	tfjob := newTFJob(cron, scheduledTime)
+	tfjob.TypeMeta = metav1.TypeMeta{Kind: tfv1alpha2.Kind, APIVersion: tfv1alpha2.SchemeGroupVersion.String()}
+	tfjob.Status.Conditions = []tfv1alpha2.TFJobCondition{
+		{
+			Type:               condition,
+			Status:             v1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(scheduledTime.Add(10 * time.Minute)),
+		},
+	}
+	if _, err := tfJobClientSet.KubeflowV1alpha2().TFJobs(tfjob.Namespace).Create(tfjob); err != nil {
+		t.Fatalf("Failed to create the tfjob: %v", err)
+	}
+	unstructured, err := generator.ConvertTFJobToUnstructured(tfjob)
+	if err != nil {
+		t.Fatalf("Failed to convert the TFJob to Unstructured: %v", err)
+	}
+	if err := tfJobIndexer.Add(unstructured); err != nil {
+		t.Fatalf("Failed to add tfjob to tfJobIndexer: %v", err)
+	}
+}
+
+func TestReconcileCronTFJob(t *testing.T) {
+	testCases := map[string]struct {
+		now              time.Time
+		policy           tfv1alpha2.ConcurrencyPolicy
+		deadlineSeconds  *int64
+		suspend          bool
+		lastScheduleTime *time.Time
+		// runningSince adds a running tfjob scheduled at this time.
+		runningSince *time.Time
+
+		expectedCreated      *time.Time
+		expectedDeleted      bool
+		expectedActive       int
+		expectedRequeueAfter time.Duration
+	}{
+		"before the first run": {
+			now:                  firstRun.Add(-time.Minute),
+			expectedRequeueAfter: time.Minute,
+		},
+		"first run": {
+			now:                  firstRun.Add(10 * time.Second),
+			expectedCreated:      &firstRun,
+			expectedActive:       1,
+			expectedRequeueAfter: time.Hour - 10*time.Second,
+		},
+		"most recent of the missed runs": {
+			now:                  firstRun.Add(3*time.Hour + time.Minute),
+			expectedCreated:      timePtr(firstRun.Add(3 * time.Hour)),
+			expectedActive:       1,
+			expectedRequeueAfter: time.Hour - time.Minute,
+		},
+		"already run": {
+			now:                  firstRun.Add(time.Minute),
+			lastScheduleTime:     &firstRun,
+			expectedRequeueAfter: time.Hour - time.Minute,
+		},
+		"missed deadline": {
+			now:                  firstRun.Add(10 * time.Minute),
+			deadlineSeconds:      tfv1alpha2.Int64(300),
+			expectedRequeueAfter: 50 * time.Minute,
+		},
+		"within deadline": {
+			now:                  firstRun.Add(4 * time.Minute),
+			deadlineSeconds:      tfv1alpha2.Int64(300),
+			expectedCreated:      &firstRun,
+			expectedActive:       1,
+			expectedRequeueAfter: 56 * time.Minute,
+		},
+		"suspended": {
+			now:     firstRun.Add(time.Minute),
+			suspend: true,
+		},
+		"concurrent runs allowed": {
+			now:                  firstRun.Add(time.Hour + time.Minute),
+			lastScheduleTime:     &firstRun,
+			runningSince:         &firstRun,
+			expectedCreated:      timePtr(firstRun.Add(time.Hour)),
+			expectedActive:       2,
+			expectedRequeueAfter: time.Hour - time.Minute,
+		},
+		"concurrent runs forbidden": {
+			now:                  firstRun.Add(time.Hour + time.Minute),
+			policy:               tfv1alpha2.ConcurrencyPolicyForbid,
+			lastScheduleTime:     &firstRun,
+			runningSince:         &firstRun,
+			expectedActive:       1,
+			expectedRequeueAfter: time.Hour - time.Minute,
+		},
+		"concurrent runs replaced": {
+			now:                  firstRun.Add(time.Hour + time.Minute),
+			policy:               tfv1alpha2.ConcurrencyPolicyReplace,
+			lastScheduleTime:     &firstRun,
+			runningSince:         &firstRun,
+			expectedCreated:      timePtr(firstRun.Add(time.Hour)),
+			expectedDeleted:      true,
+			expectedActive:       1,
+			expectedRequeueAfter: time.Hour - time.Minute,
+		},
+	}
+
+	for name, c := range testCases {
+		cron := newCronTFJob()
+		cron.Spec.ConcurrencyPolicy = c.policy
+		cron.Spec.StartingDeadlineSeconds = c.deadlineSeconds
+		cron.Spec.Suspend = &c.suspend
+		if c.lastScheduleTime != nil {
+			cron.Status.LastScheduleTime = &metav1.Time{Time: *c.lastScheduleTime}
+		}
+
+		tfv1alpha2.SetObjectDefaults_CronTFJob(cron)
+
+		cc, tfJobClientSet, _, tfJobIndexer := newCronTFJobController(c.now)
+		if c.runningSince != nil {
+			addTFJob(t, tfJobClientSet, tfJobIndexer, cron, *c.runningSince, tfv1alpha2.TFJobRunning)
+		}
+		actual := cron.DeepCopy()
+		requeueAfter, err := cc.reconcileCronTFJob(actual)
+		if err != nil {
+			t.Errorf("%s: unexpected error when reconciling the crontfjob: %v", name, err)
+			continue
+		}
+		if requeueAfter != c.expectedRequeueAfter {
+			t.Errorf("%s: expected to be requeued after %v, got %v", name, c.expectedRequeueAfter, requeueAfter)
+		}
+
+		tfjobs, err := tfJobClientSet.KubeflowV1alpha2().TFJobs(metav1.NamespaceDefault).List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Failed to list the tfjobs: %v", err)
+		}
+		created := map[string]bool{}
+		for _, tfjob := range tfjobs.Items {
+			created[tfjob.Name] = true
+		}
+		if c.expectedCreated != nil {
+			tfJobName := genTFJobName(cron.Name, *c.expectedCreated)
+			if !created[tfJobName] {
+				t.Errorf("%s: expected tfjob %s to be created, got %v", name, tfJobName, created)
+			}
+			if actual.Status.LastScheduleTime == nil || !actual.Status.LastScheduleTime.Time.Equal(*c.expectedCreated) {
+				t.Errorf("%s: expected the last schedule time %v, got %v", name, c.expectedCreated, actual.Status.LastScheduleTime)
+			}
+		}
+		expectedCount := 0
+		if c.runningSince != nil && !c.expectedDeleted {
+			expectedCount++
+		}
+		if c.expectedCreated != nil {
+			expectedCount++
+		}
+		if len(tfjobs.Items) != expectedCount {
+			t.Errorf("%s: expected %d tfjobs, got %v", name, expectedCount, created)
+		}
+		if len(actual.Status.Active) != c.expectedActive {
+			t.Errorf("%s: expected %d active tfjobs, got %v", name, c.expectedActive, actual.Status.Active)
+		}
+	}
+}
+
+func TestPruneHistory(t *testing.T) {
+	cron := newCronTFJob()
+	cron.Spec.SuccessfulJobsHistoryLimit = tfv1alpha2.Int32(1)
+	cron.Spec.FailedJobsHistoryLimit = tfv1alpha2.Int32(0)
+	now := firstRun.Add(3*time.Hour + 30*time.Minute)
+	cron.Status.LastScheduleTime = &metav1.Time{Time: firstRun.Add(3 * time.Hour)}
+
+	cc, tfJobClientSet, cronIndexer, tfJobIndexer := newCronTFJobController(now)
+	addTFJob(t, tfJobClientSet, tfJobIndexer, cron, firstRun, tfv1alpha2.TFJobSucceeded)
+	addTFJob(t, tfJobClientSet, tfJobIndexer, cron, firstRun.Add(time.Hour), tfv1alpha2.TFJobFailed)
+	addTFJob(t, tfJobClientSet, tfJobIndexer, cron, firstRun.Add(2*time.Hour), tfv1alpha2.TFJobSucceeded)
+	addTFJob(t, tfJobClientSet, tfJobIndexer, cron, firstRun.Add(3*time.Hour), tfv1alpha2.TFJobRunning)
+	if err := cronIndexer.Add(cron); err != nil {
+		t.Fatalf("Failed to add crontfjob to cronIndexer: %v", err)
+	}
+	cc.updateStatusHandler = func(cron *tfv1alpha2.CronTFJob) error {
+		return nil
+	}
+
+	if err := cc.syncCronTFJob(metav1.NamespaceDefault + "/" + cron.Name); err != nil {
+		t.Fatalf("Unexpected error when syncing the crontfjob: %v", err)
+	}
+
+	// The last succeeded and the running tfjobs are kept.
+	tfjobs, err := tfJobClientSet.KubeflowV1alpha2().TFJobs(metav1.NamespaceDefault).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list the tfjobs: %v", err)
+	}
+	expected := map[string]bool{
+		genTFJobName(cron.Name, firstRun.Add(2*time.Hour)): true,
+		genTFJobName(cron.Name, firstRun.Add(3*time.Hour)): true,
+	}
+	if len(tfjobs.Items) != len(expected) {
+		t.Errorf("Expected the tfjobs %v, got %v", expected, tfjobs.Items)
+	}
+	for _, tfjob := range tfjobs.Items {
+		if !expected[tfjob.Name] {
+			t.Errorf("Expected tfjob %s to be deleted", tfjob.Name)
+		}
+	}
+}
+
+func TestNewTFJob(t *testing.T) {
+	cron := newCronTFJob()
+	tfjob := newTFJob(cron, firstRun)
+	if tfjob.Labels["model"] != "mnist" || tfjob.Labels[cronNameLabel] != cron.Name {
+		t.Errorf("Expected the labels of the template and the crontfjob, got %v", tfjob.Labels)
+	}
+	if ref := metav1.GetControllerOf(tfjob); ref == nil || ref.UID != cron.UID || ref.Kind != tfv1alpha2.CronKind {
+		t.Errorf("Expected the tfjob to be controlled by the crontfjob, got %v", tfjob.OwnerReferences)
+	}
+	if cron.Spec.JobTemplate.Labels[cronNameLabel] != "" {
+		t.Errorf("Expected the template to be unchanged, got %v", cron.Spec.JobTemplate.Labels)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,310 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/validation"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+	cronutil "github.com/kubeflow/tf-operator/pkg/util/cron"
+)
+
+const (
+	// cronNameLabel is the label of the tfjobs with the name of their crontfjob.
+	cronNameLabel = "cron_tf_job_name"
+
+	// maxMissedRuns is the number of missed runs above which no run is
+	// started, e.g. when the clock is skewed.
+	maxMissedRuns = 100
+
+	invalidReason          = "InvalidCronTFJob"
+	successfulCreateReason = "SuccessfulCreate"
+	failedCreateReason     = "FailedCreate"
+	successfulDeleteReason = "SuccessfulDelete"
+	failedDeleteReason     = "FailedDelete"
+	jobAlreadyActiveReason = "JobAlreadyActive"
+	tooManyMissedReason    = "TooManyMissedTimes"
+)
+
+// reconcileCronTFJob updates the active tfjobs of the crontfjob, prunes its
+// history and starts its due run. It returns how long to wait until the next run.
+func (cc *CronTFJobController) reconcileCronTFJob(cron *tfv1alpha2.CronTFJob) (time.Duration, error) {
+	if cron.DeletionTimestamp != nil {
+		return 0, nil
+	}
+	if err := validation.ValidateCronTFJobSpec(&cron.Spec); err != nil {
+		msg := fmt.Sprintf("CronTFJob %s is invalid: %v", cron.Name, err)
+		log.Warn(msg)
+		cc.recorder.Event(cron, v1.EventTypeWarning, invalidReason, msg)
+		return 0, nil
+	}
+	schedule, err := cronutil.Parse(cron.Spec.Schedule)
+	if err != nil {
+		return 0, err
+	}
+
+	tfjobs, err := cc.getTFJobsForCronTFJob(cron)
+	if err != nil {
+		return 0, err
+	}
+	updateActive(cron, tfjobs)
+	if err := cc.pruneHistory(cron, tfjobs); err != nil {
+		return 0, err
+	}
+
+	if *cron.Spec.Suspend {
+		return 0, nil
+	}
+
+	now := cc.clock.Now()
+	var requeueAfter time.Duration
+	if next := schedule.Next(now); !next.IsZero() {
+		requeueAfter = next.Sub(now)
+	}
+
+	scheduledTime, missed := mostRecentScheduleTime(cron, schedule, now)
+	if missed > maxMissedRuns {
+		cc.recorder.Eventf(cron, v1.EventTypeWarning, tooManyMissedReason,
+			"Too many missed runs (> %d), set or decrease startingDeadlineSeconds or check the clock", maxMissedRuns)
+		return requeueAfter, nil
+	}
+	if scheduledTime.IsZero() {
+		return requeueAfter, nil
+	}
+
+	if len(cron.Status.Active) > 0 {
+		switch cron.Spec.ConcurrencyPolicy {
+		case tfv1alpha2.ConcurrencyPolicyForbid:
+			// The run is started when the active tfjobs finish, unless it
+			// misses its deadline.
+			log.Infof("Not starting the run of %s for CronTFJob %s since a TFJob is still active", scheduledTime, cron.Name)
+			cc.recorder.Eventf(cron, v1.EventTypeNormal, jobAlreadyActiveReason, "Not starting the run of %s, a TFJob is still active", scheduledTime)
+			return requeueAfter, nil
+		case tfv1alpha2.ConcurrencyPolicyReplace:
+			if err := cc.deleteActive(cron); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := cc.createTFJob(cron, scheduledTime); err != nil {
+		return 0, err
+	}
+	return requeueAfter, nil
+}
+
+// mostRecentScheduleTime returns the most recent scheduled time of the
+// crontfjob not run yet, and the number of missed runs. It returns the zero
+// time if no run is due.
+func mostRecentScheduleTime(cron *tfv1alpha2.CronTFJob, schedule *cronutil.Schedule, now time.Time) (time.Time, int) {
+	earliest := cron.CreationTimestamp.Time
+	if cron.Status.LastScheduleTime != nil {
+		earliest = cron.Status.LastScheduleTime.Time
+	}
+	if deadline := cron.Spec.StartingDeadlineSeconds; deadline != nil {
+		if start := now.Add(-time.Duration(*deadline) * time.Second); start.After(earliest) {
+			earliest = start
+		}
+	}
+
+	var mostRecent time.Time
+	missed := 0
+	for t := schedule.Next(earliest); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		mostRecent = t
+		missed++
+		if missed > maxMissedRuns {
+			break
+		}
+	}
+	return mostRecent, missed
+}
+
+// createTFJob creates the tfjob of the run scheduled at scheduledTime.
+func (cc *CronTFJobController) createTFJob(cron *tfv1alpha2.CronTFJob, scheduledTime time.Time) error {
+	tfjob := newTFJob(cron, scheduledTime)
+	created, err := cc.tfJobClientSet.KubeflowV1alpha2().TFJobs(cron.Namespace).Create(tfjob)
+	switch {
+	case errors.IsAlreadyExists(err):
+		// The tfjob was created by a previous sync, it is added to the
+		// active tfjobs once observed.
+		log.Infof("TFJob %s of CronTFJob %s already exists", tfjob.Name, cron.Name)
+	case err != nil:
+		cc.recorder.Eventf(cron, v1.EventTypeWarning, failedCreateReason, "Error creating TFJob %s: %v", tfjob.Name, err)
+		return err
+	default:
+		cc.recorder.Eventf(cron, v1.EventTypeNormal, successfulCreateReason, "Created TFJob %s", tfjob.Name)
+		cron.Status.Active = append(cron.Status.Active, tfJobReference(created))
+	}
+	cron.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+	return nil
+}
+
+// deleteActive deletes the active tfjobs of the crontfjob.
+func (cc *CronTFJobController) deleteActive(cron *tfv1alpha2.CronTFJob) error {
+	for _, ref := range cron.Status.Active {
+		if err := cc.deleteTFJob(cron, ref.Name); err != nil {
+			return err
+		}
+	}
+	cron.Status.Active = nil
+	return nil
+}
+
+// pruneHistory deletes the oldest finished tfjobs beyond the history limits.
+func (cc *CronTFJobController) pruneHistory(cron *tfv1alpha2.CronTFJob, tfjobs []*tfv1alpha2.TFJob) error {
+	var succeeded, failed []*tfv1alpha2.TFJob
+	for _, tfjob := range tfjobs {
+		if condition := finishedCondition(tfjob); condition != nil {
+			if condition.Type == tfv1alpha2.TFJobSucceeded {
+				succeeded = append(succeeded, tfjob)
+			} else {
+				failed = append(failed, tfjob)
+			}
+		}
+	}
+	for _, history := range []struct {
+		tfjobs []*tfv1alpha2.TFJob
+		limit  int32
+	}{
+		{succeeded, *cron.Spec.SuccessfulJobsHistoryLimit},
+		{failed, *cron.Spec.FailedJobsHistoryLimit},
+	} {
+		if len(history.tfjobs) <= int(history.limit) {
+			continue
+		}
+		sort.Sort(byFinishTime(history.tfjobs))
+		for _, tfjob := range history.tfjobs[:len(history.tfjobs)-int(history.limit)] {
+			if err := cc.deleteTFJob(cron, tfjob.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cc *CronTFJobController) deleteTFJob(cron *tfv1alpha2.CronTFJob, name string) error {
+	policy := metav1.DeletePropagationBackground
+	err := cc.tfJobClientSet.KubeflowV1alpha2().TFJobs(cron.Namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &policy})
+	if err != nil && !errors.IsNotFound(err) {
+		cc.recorder.Eventf(cron, v1.EventTypeWarning, failedDeleteReason, "Error deleting TFJob %s: %v", name, err)
+		return err
+	}
+	cc.recorder.Eventf(cron, v1.EventTypeNormal, successfulDeleteReason, "Deleted TFJob %s", name)
+	return nil
+}
+
+// getTFJobsForCronTFJob returns the tfjobs controlled by the crontfjob.
+func (cc *CronTFJobController) getTFJobsForCronTFJob(cron *tfv1alpha2.CronTFJob) ([]*tfv1alpha2.TFJob, error) {
+	var tfjobs []*tfv1alpha2.TFJob
+	for _, obj := range cc.tfJobInformer.GetIndexer().List() {
+		un, ok := obj.(*metav1unstructured.Unstructured)
+		if !ok || un.GetNamespace() != cron.Namespace {
+			continue
+		}
+		if ref := metav1.GetControllerOf(un); ref == nil || ref.UID != cron.UID {
+			continue
+		}
+		var tfjob tfv1alpha2.TFJob
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, &tfjob); err != nil {
+			return nil, err
+		}
+		tfjobs = append(tfjobs, &tfjob)
+	}
+	return tfjobs, nil
+}
+
+// updateActive sets the active tfjobs of the crontfjob to its unfinished tfjobs.
+func updateActive(cron *tfv1alpha2.CronTFJob, tfjobs []*tfv1alpha2.TFJob) {
+	var active []v1.ObjectReference
+	for _, tfjob := range tfjobs {
+		if finishedCondition(tfjob) == nil && tfjob.DeletionTimestamp == nil {
+			active = append(active, tfJobReference(tfjob))
+		}
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Name < active[j].Name
+	})
+	cron.Status.Active = active
+}
+
+// finishedCondition returns the succeeded or failed condition of the tfjob,
+// or nil if it has not finished.
+func finishedCondition(tfjob *tfv1alpha2.TFJob) *tfv1alpha2.TFJobCondition {
+	for i := range tfjob.Status.Conditions {
+		condition := &tfjob.Status.Conditions[i]
+		if (condition.Type == tfv1alpha2.TFJobSucceeded || condition.Type == tfv1alpha2.TFJobFailed) && condition.Status == v1.ConditionTrue {
+			return condition
+		}
+	}
+	return nil
+}
+
+// byFinishTime sorts the finished tfjobs from the oldest.
+type byFinishTime []*tfv1alpha2.TFJob
+
+func (s byFinishTime) Len() int      { return len(s) }
+func (s byFinishTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byFinishTime) Less(i, j int) bool {
+	return finishedCondition(s[i]).LastTransitionTime.Before(&finishedCondition(s[j]).LastTransitionTime)
+}
+
+func tfJobReference(tfjob *tfv1alpha2.TFJob) v1.ObjectReference {
+	return v1.ObjectReference{
+		APIVersion: tfv1alpha2.SchemeGroupVersion.String(),
+		Kind:       tfv1alpha2.Kind,
+		Namespace:  tfjob.Namespace,
+		Name:       tfjob.Name,
+		UID:        tfjob.UID,
+	}
+}
+
+// genTFJobName returns the name of the tfjob of a run, which is the same
+// for the same scheduled time so that the run is only started once.
+func genTFJobName(cronName string, scheduledTime time.Time) string {
+	return fmt.Sprintf("%s-%d", cronName, scheduledTime.Unix()/60)
+}
+
+// newTFJob returns the tfjob of the run scheduled at scheduledTime.
+func newTFJob(cron *tfv1alpha2.CronTFJob, scheduledTime time.Time) *tfv1alpha2.TFJob {
+	template := cron.Spec.JobTemplate.DeepCopy()
+	labels := template.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[generator.LabelGroupName] = tfv1alpha2.GroupName
+	labels[cronNameLabel] = cron.Name
+
+	return &tfv1alpha2.TFJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            genTFJobName(cron.Name, scheduledTime),
+			Namespace:       cron.Namespace,
+			Labels:          labels,
+			Annotations:     template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cron, controllerKind)},
+		},
+		Spec: template.Spec,
+	}
+}
@@ -18,17 +18,14 @@ import (
 	"github.com/kubeflow/tf-operator/pkg/util/unstructured"
 )
 
-const (
-	resyncPeriod = 30 * time.Second
-)
-
 var (
 	errGetFromKey    = fmt.Errorf("Failed to get TFJob from key")
 	errNotExists     = fmt.Errorf("The object is not found")
 	errFailedMarshal = fmt.Errorf("Failed to marshal the object to TFJob")
 )
 
-func NewUnstructuredTFJobInformer(restConfig *restclientset.Config) tfjobinformersv1alpha2.TFJobInformer {
+// NewUnstructuredTFJobInformer returns a TFJobInformer which reads the tfjobs as unstructured objects.
+func NewUnstructuredTFJobInformer(restConfig *restclientset.Config, resyncPeriod time.Duration) tfjobinformersv1alpha2.TFJobInformer {
 	dynClientPool := dynamic.NewDynamicClientPool(restConfig)
 	dclient, err := dynClientPool.ClientForGroupVersionKind(controllerKind)
 	if err != nil {
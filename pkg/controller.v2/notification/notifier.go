@@ -0,0 +1,241 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notification posts the conditions of the TFJobs to HTTP webhooks.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/config"
+)
+
+// SignatureHeader is the header with the HMAC-SHA256 of the payload, signed
+// with the key of the configuration, as "sha256=" and the hex digest.
+const SignatureHeader = "X-TFJob-Signature"
+
+// Payload is the JSON body posted to the webhooks when a condition of a
+// tfjob changes.
+type Payload struct {
+	// Key is the namespace/name of the tfjob.
+	Key string    `json:"key"`
+	UID types.UID `json:"uid"`
+	// Condition is the changed condition, with its reason and timings.
+	Condition       tfv1alpha2.TFJobCondition                                `json:"condition"`
+	StartTime       *metav1.Time                                             `json:"startTime,omitempty"`
+	CompletionTime  *metav1.Time                                             `json:"completionTime,omitempty"`
+	ReplicaStatuses map[tfv1alpha2.TFReplicaType]*tfv1alpha2.TFReplicaStatus `json:"replicaStatuses"`
+}
+
+// NewPayload returns the payload of the condition of the tfjob.
+func NewPayload(tfjob *tfv1alpha2.TFJob, condition tfv1alpha2.TFJobCondition) *Payload {
+	return &Payload{
+		Key:             tfjob.Namespace + "/" + tfjob.Name,
+		UID:             tfjob.UID,
+		Condition:       condition,
+		StartTime:       tfjob.Status.StartTime,
+		CompletionTime:  tfjob.Status.CompletionTime,
+		ReplicaStatuses: tfjob.Status.TFReplicaStatuses,
+	}
+}
+
+// Webhooks returns the webhooks notified for the tfjob: the ones of the
+// configuration and the ones of its annotation which are valid URLs of the
+// allowed hosts. The others are logged and ignored.
+func Webhooks(tfjob *tfv1alpha2.TFJob, c config.NotificationConfiguration) []string {
+	webhooks := append([]string{}, c.Webhooks...)
+	for _, webhook := range strings.Split(tfjob.Annotations[tfv1alpha2.NotificationWebhooksAnnotation], ",") {
+		if webhook = strings.TrimSpace(webhook); webhook == "" {
+			continue
+		}
+		if err := validateJobWebhook(webhook, c.AllowedHosts); err != nil {
+			log.Warnf("Ignoring the notification webhook of tfjob %s/%s: %v", tfjob.Namespace, tfjob.Name, err)
+			continue
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks
+}
+
+// validateJobWebhook checks that the webhook of a tfjob is a valid URL of one
+// of the allowed hosts.
+func validateJobWebhook(webhook string, allowedHosts []string) error {
+	if err := config.ValidateWebhook(webhook); err != nil {
+		return err
+	}
+	u, _ := url.Parse(webhook)
+	for _, host := range allowedHosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %s of %s is not allowed", u.Hostname(), webhook)
+}
+
+// Sign returns the value of the signature header of the body.
+func Sign(body, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// maxPending bounds the deliveries of the notifier which are not final yet,
+// including the ones waiting for their retry, the notifications are dropped
+// once it is reached.
+const maxPending = 1000
+
+// delivery is a payload to post to a webhook.
+type delivery struct {
+	webhook string
+	// key is the key of the tfjob, for the logs.
+	key     string
+	body    []byte
+	secret  []byte
+	config  config.NotificationConfiguration
+	attempt int
+}
+
+// Notifier delivers the payloads to the webhooks from a queue, which its
+// workers process. A failed delivery is queued again after its backoff.
+type Notifier struct {
+	client *http.Client
+	queue  workqueue.DelayingInterface
+	// pending counts the deliveries which are queued, being posted or waiting
+	// for their retry, the queue does not count the latter.
+	pending int32
+}
+
+// NewNotifier returns a new Notifier. The payloads are delivered once it runs.
+func NewNotifier() *Notifier {
+	return &Notifier{
+		client: &http.Client{},
+		queue:  workqueue.NewNamedDelayingQueue("notifications"),
+	}
+}
+
+// Run delivers the payloads with the given number of workers until stopCh is closed.
+func (n *Notifier) Run(workers int, stopCh <-chan struct{}) {
+	defer n.queue.ShutDown()
+	for i := 0; i < workers; i++ {
+		go wait.Until(n.runWorker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+// Notify queues the payload for each webhook. The delivery is at least once:
+// the webhooks may receive a payload again if the status of the tfjob fails to be saved.
+func (n *Notifier) Notify(webhooks []string, payload *Payload, c config.NotificationConfiguration) {
+	if len(webhooks) == 0 {
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Errorf("Failed to marshal the notification of tfjob %s: %v", payload.Key, err)
+		return
+	}
+	var secret []byte
+	if c.SecretFile != "" {
+		// Read for each notification, so that the key can be rotated.
+		if secret, err = ioutil.ReadFile(c.SecretFile); err != nil {
+			log.Errorf("Failed to read the notification secret file %s: %v", c.SecretFile, err)
+			return
+		}
+	}
+	for _, webhook := range webhooks {
+		if atomic.AddInt32(&n.pending, 1) > maxPending {
+			atomic.AddInt32(&n.pending, -1)
+			log.Errorf("Dropping the notification of tfjob %s to %s, %d notifications are pending", payload.Key, webhook, maxPending)
+			continue
+		}
+		n.queue.Add(&delivery{webhook: webhook, key: payload.Key, body: body, secret: secret, config: c})
+	}
+}
+
+func (n *Notifier) runWorker() {
+	for n.processNextDelivery() {
+	}
+}
+
+// processNextDelivery posts the next payload of the queue. The server errors
+// and the failed requests are retried after an exponential backoff, the other
+// responses are final.
+func (n *Notifier) processNextDelivery() bool {
+	item, quit := n.queue.Get()
+	if quit {
+		return false
+	}
+	defer n.queue.Done(item)
+
+	d := item.(*delivery)
+	retry, err := n.post(d.webhook, d.body, d.secret, d.config.Timeout.Duration)
+	if err == nil {
+		atomic.AddInt32(&n.pending, -1)
+		return true
+	}
+	if !retry || d.attempt >= d.config.Retries {
+		atomic.AddInt32(&n.pending, -1)
+		log.Errorf("Failed to notify %s of tfjob %s: %v", d.webhook, d.key, err)
+		return true
+	}
+	delay := d.config.BaseDelay.Duration << uint(d.attempt)
+	d.attempt++
+	log.Warnf("Failed to notify %s of tfjob %s, retrying in %v: %v", d.webhook, d.key, delay, err)
+	n.queue.AddAfter(d, delay)
+	return true
+}
+
+// post posts the body to the webhook once, signed with the secret if it is
+// not empty. It returns whether a failure may be retried.
+func (n *Notifier) post(webhook string, body, secret []byte, timeout time.Duration) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(secret) != 0 {
+		req.Header.Set(SignatureHeader, Sign(body, secret))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := n.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook responded %s", resp.Status)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/config"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestNotify(t *testing.T) {
+	testCases := map[string]struct {
+		statuses         []int
+		key              string
+		expectedRequests int32
+	}{
+		"delivered": {
+			statuses:         []int{http.StatusOK},
+			key:              "secret",
+			expectedRequests: 1,
+		},
+		"retried after server errors": {
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusNoContent},
+			expectedRequests: 3,
+		},
+		"retries exhausted": {
+			statuses:         []int{http.StatusInternalServerError},
+			expectedRequests: 3,
+		},
+		"client error not retried": {
+			statuses:         []int{http.StatusBadRequest},
+			expectedRequests: 1,
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "notification")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tfJob := testutil.NewTFJob(1, 0)
+	payload := NewPayload(tfJob, tfv1alpha2.TFJobCondition{
+		Type:   tfv1alpha2.TFJobSucceeded,
+		Reason: "TFJobSucceeded",
+	})
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Failed to marshal the payload: %v", err)
+	}
+
+	for name, tc := range testCases {
+		c := config.Default().Notifications
+		c.Retries = 2
+		c.BaseDelay = metav1.Duration{Duration: time.Millisecond}
+		if tc.key != "" {
+			c.SecretFile = filepath.Join(dir, "secret")
+			if err := ioutil.WriteFile(c.SecretFile, []byte(tc.key), 0600); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&requests, 1)
+			data, err := ioutil.ReadAll(r.Body)
+			if err != nil || !reflect.DeepEqual(data, body) {
+				t.Errorf("%s: expected the payload %s, got %s", name, body, data)
+			}
+			if signature := r.Header.Get(SignatureHeader); tc.key != "" && signature != Sign(body, []byte(tc.key)) {
+				t.Errorf("%s: wrong signature %q", name, signature)
+			} else if tc.key == "" && signature != "" {
+				t.Errorf("%s: expected no signature, got %q", name, signature)
+			}
+			status := tc.statuses[len(tc.statuses)-1]
+			if int(n) <= len(tc.statuses) {
+				status = tc.statuses[n-1]
+			}
+			w.WriteHeader(status)
+		}))
+
+		stopCh := make(chan struct{})
+		n := NewNotifier()
+		go n.Run(1, stopCh)
+		n.Notify([]string{server.URL}, payload, c)
+
+		err := wait.Poll(time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+			return atomic.LoadInt32(&requests) >= tc.expectedRequests, nil
+		})
+		if err != nil {
+			t.Errorf("%s: expected %d requests, got %d", name, tc.expectedRequests, atomic.LoadInt32(&requests))
+		}
+		// No request follows the final one.
+		time.Sleep(50 * time.Millisecond)
+		if got := atomic.LoadInt32(&requests); got != tc.expectedRequests {
+			t.Errorf("%s: expected %d requests, got %d", name, tc.expectedRequests, got)
+		}
+		if pending := atomic.LoadInt32(&n.pending); pending != 0 {
+			t.Errorf("%s: expected no pending delivery, got %d", name, pending)
+		}
+		close(stopCh)
+		server.Close()
+	}
+}
+
+func TestNotifyPendingBound(t *testing.T) {
+	tfJob := testutil.NewTFJob(1, 0)
+	payload := NewPayload(tfJob, tfv1alpha2.TFJobCondition{Type: tfv1alpha2.TFJobSucceeded})
+	c := config.Default().Notifications
+
+	// The deliveries waiting for their retry are not in the queue, but they
+	// are pending.
+	n := NewNotifier()
+	n.pending = maxPending - 1
+	n.Notify([]string{"http://a.example.com", "http://b.example.com"}, payload, c)
+	if n.queue.Len() != 1 {
+		t.Errorf("Expected 1 queued delivery, got %d", n.queue.Len())
+	}
+	if n.pending != maxPending {
+		t.Errorf("Expected %d pending deliveries, got %d", maxPending, n.pending)
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	tfJob := testutil.NewTFJob(1, 0)
+	c := config.NotificationConfiguration{Webhooks: []string{"https://pipeline.example.com/tfjobs"}}
+	if webhooks := Webhooks(tfJob, c); !reflect.DeepEqual(webhooks, c.Webhooks) {
+		t.Errorf("Expected the webhooks of the configuration, got %v", webhooks)
+	}
+
+	tfJob.Annotations = map[string]string{
+		tfv1alpha2.NotificationWebhooksAnnotation: "http://a.example.com, https://B.example.com:8443/tfjobs,http://c.example.com,ftp://a.example.com,a.example.com,",
+	}
+	// The webhooks of the annotation are ignored without allowed hosts.
+	if webhooks := Webhooks(tfJob, c); !reflect.DeepEqual(webhooks, c.Webhooks) {
+		t.Errorf("Expected the webhooks of the configuration, got %v", webhooks)
+	}
+
+	c.AllowedHosts = []string{"a.example.com", "b.example.com"}
+	expected := []string{"https://pipeline.example.com/tfjobs", "http://a.example.com", "https://B.example.com:8443/tfjobs"}
+	if webhooks := Webhooks(tfJob, c); !reflect.DeepEqual(webhooks, expected) {
+		t.Errorf("Expected the webhooks %v, got %v", expected, webhooks)
+	}
+	if len(c.Webhooks) != 1 {
+		t.Errorf("Expected the webhooks of the configuration to be unchanged, got %v", c.Webhooks)
+	}
+}
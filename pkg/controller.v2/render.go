@@ -0,0 +1,128 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/common/jobcontroller"
+	"github.com/kubeflow/tf-operator/pkg/control"
+	"github.com/kubeflow/tf-operator/pkg/controller.v2/config"
+)
+
+// Render returns the pods and the services the controller creates for the
+// tfjob, without a cluster. The tfjob is expected to be defaulted. The pods
+// are the first attempts of a tfjob which has just been created, every
+// replica type is rendered even if the startup policy delays its pods. They
+// are ordered by replica type and index, and the services are in the same order.
+func Render(tfjob *tfv1alpha2.TFJob, cfg *config.OperatorConfiguration) ([]*v1.Pod, []*v1.Service, error) {
+	tc := &TFJobController{}
+	tc.JobController = &jobcontroller.JobController{Controller: tc}
+	controllerRef := tc.GenOwnerReference(tfjob)
+
+	rtypes := make([]string, 0, len(tfjob.Spec.TFReplicaSpecs))
+	for rtype := range tfjob.Spec.TFReplicaSpecs {
+		rtypes = append(rtypes, string(rtype))
+	}
+	sort.Strings(rtypes)
+
+	var pods []*v1.Pod
+	var services []*v1.Service
+	for _, t := range rtypes {
+		rtype := tfv1alpha2.TFReplicaType(t)
+		spec := tfjob.Spec.TFReplicaSpecs[rtype]
+		rt := strings.ToLower(t)
+		for i := 0; i < int(*spec.Replicas); i++ {
+			index := strconv.Itoa(i)
+			port, err := getReplicaPort(tfjob, rtype, index)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			podTemplate, err := newPodTemplate(tfjob, spec, cfg)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := setReplicaPodSpec(podTemplate, tfjob, rtype, spec); err != nil {
+				return nil, nil, err
+			}
+			if err := tc.SetPodTemplate(tfjob, rt, index, 0, podTemplate); err != nil {
+				return nil, nil, err
+			}
+			pod, err := control.GetPodFromTemplate(podTemplate, tfjob, controllerRef)
+			if err != nil {
+				return nil, nil, err
+			}
+			pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+			pod.Namespace = tfjob.Namespace
+			pods = append(pods, pod)
+
+			service := tc.GenService(tfjob, rt, index, port)
+			service.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+			service.Namespace = tfjob.Namespace
+			service.OwnerReferences = append(service.OwnerReferences, *controllerRef)
+			services = append(services, service)
+		}
+	}
+	return pods, services, nil
+}
+
+// RenderClusterSpecConfigMap returns the ConfigMap with the cluster spec the
+// controller creates for the tfjob, nil if its cluster spec is not delivered
+// by a ConfigMap. The tfjob is expected to be defaulted. In the host network,
+// the addresses of the nodes are unknown and rendered as <unscheduled>.
+func RenderClusterSpecConfigMap(tfjob *tfv1alpha2.TFJob) (*v1.ConfigMap, error) {
+	if tfjob.Spec.ClusterSpecDelivery != tfv1alpha2.ClusterSpecDeliveryConfigMap {
+		return nil, nil
+	}
+	var cluster ClusterSpec
+	if tfjob.Spec.HostNetwork != nil {
+		cluster, _ = genHostNetworkClusterSpec(tfjob, nil)
+	} else {
+		var err error
+		cluster, err = genClusterSpec(tfjob)
+		if err != nil {
+			return nil, err
+		}
+	}
+	configMap, err := newClusterSpecConfigMap(tfjob, cluster)
+	if err != nil {
+		return nil, err
+	}
+	configMap.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+	return configMap, nil
+}
+
+// RenderNetworkPolicy returns the NetworkPolicy the controller creates for the
+// tfjob, nil if it has no isolation policy. The tfjob is expected to be defaulted.
+func RenderNetworkPolicy(tfjob *tfv1alpha2.TFJob) (*networkingv1.NetworkPolicy, error) {
+	if tfjob.Spec.NetworkIsolation == nil {
+		return nil, nil
+	}
+	policy, err := newNetworkPolicy(tfjob)
+	if err != nil {
+		return nil, err
+	}
+	policy.TypeMeta = metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"}
+	return policy, nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller provides a Kubernetes controller for a TFJob resource.
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubeclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/controller"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	"github.com/kubeflow/tf-operator/pkg/control"
+	tfconfig "github.com/kubeflow/tf-operator/pkg/controller.v2/config"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func TestRender(t *testing.T) {
+	tfJob := testutil.NewTFJob(2, 1)
+	tfv1alpha2.SetDefaults_TFJob(tfJob)
+
+	pods, services, err := Render(tfJob, tfconfig.Default())
+	if err != nil {
+		t.Fatalf("Failed to render the tfjob: %v", err)
+	}
+
+	expectedNames := []string{
+		testutil.TestTFJobName + "-ps-0",
+		testutil.TestTFJobName + "-worker-0",
+		testutil.TestTFJobName + "-worker-1",
+	}
+	if len(pods) != len(expectedNames) || len(services) != len(expectedNames) {
+		t.Fatalf("Expected %d pods and services, got %d pods and %d services", len(expectedNames), len(pods), len(services))
+	}
+	for i, name := range expectedNames {
+		pod, service := pods[i], services[i]
+		// The pods are the first attempts of the replicas.
+		if pod.Name != name+"-0" || service.Name != name {
+			t.Errorf("Expected pod %s-0 and service %s, got %s and %s", name, name, pod.Name, service.Name)
+		}
+		if pod.Namespace != tfJob.Namespace || service.Namespace != tfJob.Namespace {
+			t.Errorf("Expected the namespace of the tfjob for %s, got %s and %s", name, pod.Namespace, service.Namespace)
+		}
+		if len(pod.OwnerReferences) != 1 || pod.OwnerReferences[0].Name != tfJob.Name ||
+			len(service.OwnerReferences) != 1 || service.OwnerReferences[0].Name != tfJob.Name {
+			t.Errorf("Expected the tfjob to own %s, got %v and %v", name, pod.OwnerReferences, service.OwnerReferences)
+		}
+		if pod.Spec.RestartPolicy != v1.RestartPolicyNever {
+			t.Errorf("Expected the restart policy of %s to be %s, got %s", name, v1.RestartPolicyNever, pod.Spec.RestartPolicy)
+		}
+		env := pod.Spec.Containers[0].Env
+		if len(env) != 1 || env[0].Name != tfConfig || env[0].Value == "" {
+			t.Errorf("Expected %s in the env of %s, got %v", tfConfig, name, env)
+		}
+		if !labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+			t.Errorf("Expected the service %s to select the labels %v, got %v", name, pod.Labels, service.Spec.Selector)
+		}
+		if pod.Labels[tfTemplateHashLabel] == "" {
+			t.Errorf("Expected the template hash in the labels of %s, got %v", name, pod.Labels)
+		}
+	}
+}
+
+func TestRenderMatchesController(t *testing.T) {
+	kubeClientSet := kubeclientset.NewForConfigOrDie(&rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &v1.SchemeGroupVersion,
+		},
+	},
+	)
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobClientSet := tfjobclientset.NewForConfigOrDie(config)
+	ctr, _, _ := newTFJobController(config, kubeClientSet, tfJobClientSet, controller.NoResyncPeriodFunc)
+	fakePodControl := &controller.FakePodControl{}
+	fakeServiceControl := &control.FakeServiceControl{}
+	ctr.PodControl = fakePodControl
+	ctr.ServiceControl = fakeServiceControl
+
+	tfJob := testutil.NewTFJobWithChief(1, 1)
+	tfv1alpha2.SetDefaults_TFJob(tfJob)
+	pods, services, err := Render(tfJob, ctr.configStore.Get())
+	if err != nil {
+		t.Fatalf("Failed to render the tfjob: %v", err)
+	}
+
+	// The replicas of the tfjob in the order of the rendering.
+	rtypes := []tfv1alpha2.TFReplicaType{tfv1alpha2.TFReplicaTypeChief, tfv1alpha2.TFReplicaTypePS, tfv1alpha2.TFReplicaTypeWorker}
+	if len(pods) != len(rtypes) {
+		t.Fatalf("Expected %d pods, got %d", len(rtypes), len(pods))
+	}
+	for i, pod := range pods {
+		rtype := rtypes[i]
+		spec := tfJob.Spec.TFReplicaSpecs[rtype]
+		if err := ctr.createNewPod(tfJob, rtype, "0", 0, spec); err != nil {
+			t.Fatalf("Failed to create the pod %s: %v", pod.Name, err)
+		}
+		if err := ctr.createNewService(tfJob, rtype, "0", spec); err != nil {
+			t.Fatalf("Failed to create the service %s: %v", pod.Name, err)
+		}
+		created := fakePodControl.Templates[i]
+		if created.Name != pod.Name || !reflect.DeepEqual(created.Labels, pod.Labels) || !reflect.DeepEqual(created.Spec, pod.Spec) {
+			t.Errorf("Expected the rendered pod %s to match the created one:\nrendered: %+v\ncreated:  %+v", pod.Name, pod, created)
+		}
+		if !reflect.DeepEqual(fakePodControl.ControllerRefs[i], pod.OwnerReferences[0]) {
+			t.Errorf("Expected the owner of %s to be %v, got %v", pod.Name, fakePodControl.ControllerRefs[i], pod.OwnerReferences[0])
+		}
+		createdService := fakeServiceControl.Templates[i]
+		if createdService.Name != services[i].Name || !reflect.DeepEqual(createdService.Spec, services[i].Spec) {
+			t.Errorf("Expected the rendered service %s to match the created one:\nrendered: %+v\ncreated:  %+v", services[i].Name, services[i], createdService)
+		}
+	}
+}
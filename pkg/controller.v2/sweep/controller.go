@@ -0,0 +1,243 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sweep provides a Kubernetes controller for a TFJobSweep resource,
+// which runs a TFJob for each trial of a hyperparameter sweep.
+package sweep
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobclientset "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned"
+	tfjobscheme "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned/scheme"
+	tfjobinformersv1alpha2 "github.com/kubeflow/tf-operator/pkg/client/informers/externalversions/kubeflow/v1alpha2"
+	tfjoblisters "github.com/kubeflow/tf-operator/pkg/client/listers/kubeflow/v1alpha2"
+)
+
+const controllerName = "tfjobsweep-controller"
+
+var (
+	// controllerKind is GroupVersionKind for this controller type.
+	controllerKind = tfv1alpha2.SweepGroupVersionKind
+
+	// KeyFunc is the short name to DeletionHandlingMetaNamespaceKeyFunc.
+	KeyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
+)
+
+// TFJobSweepController is the type for TFJobSweep Controller, which runs the
+// trials of the TFJobSweeps as TFJobs.
+type TFJobSweepController struct {
+	// tfJobClientSet is a clientset for CRD TFJob and TFJobSweep.
+	tfJobClientSet tfjobclientset.Interface
+
+	// To allow injection of syncTFJobSweep for testing.
+	syncHandler func(sweepKey string) error
+
+	// To allow injection of updateSweepStatus for testing.
+	updateStatusHandler func(sweep *tfv1alpha2.TFJobSweep) error
+
+	// sweepLister can list/get tfjobsweeps from the shared informer's store.
+	sweepLister tfjoblisters.TFJobSweepLister
+
+	// tfJobInformer is the unstructured informer of the TFJob controller,
+	// shared to follow the TFJobs of the trials.
+	tfJobInformer cache.SharedIndexInformer
+
+	// sweepInformerSynced returns true if the tfjobsweep store has been synced at least once.
+	sweepInformerSynced cache.InformerSynced
+
+	// tfJobInformerSynced returns true if the tfjob store has been synced at least once.
+	tfJobInformerSynced cache.InformerSynced
+
+	// workQueue is a rate limited work queue of tfjobsweep keys.
+	workQueue workqueue.RateLimitingInterface
+
+	// recorder is an event recorder for recording Event resources to the
+	// Kubernetes API.
+	recorder record.EventRecorder
+}
+
+// NewTFJobSweepController returns a new TFJobSweep controller.
+func NewTFJobSweepController(
+	sweepInformer tfjobinformersv1alpha2.TFJobSweepInformer,
+	// This is the unstructured informer of the TFJob controller.
+	tfJobInformer tfjobinformersv1alpha2.TFJobInformer,
+	kubeClientSet kubeclientset.Interface,
+	tfJobClientSet tfjobclientset.Interface) *TFJobSweepController {
+
+	tfjobscheme.AddToScheme(scheme.Scheme)
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(log.Infof)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
+
+	sc := &TFJobSweepController{
+		tfJobClientSet: tfJobClientSet,
+		workQueue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), tfv1alpha2.SweepPlural),
+		recorder:       eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: controllerName}),
+	}
+	sc.syncHandler = sc.syncTFJobSweep
+	sc.updateStatusHandler = sc.updateSweepStatus
+
+	sweepInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: sc.enqueueSweep,
+		UpdateFunc: func(old, cur interface{}) {
+			sc.enqueueSweep(cur)
+		},
+		DeleteFunc: sc.enqueueSweep,
+	})
+	sc.sweepLister = sweepInformer.Lister()
+	sc.sweepInformerSynced = sweepInformer.Informer().HasSynced
+
+	// The sweep of a TFJob is synced when the TFJob changes.
+	tfJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: sc.enqueueSweepOfTFJob,
+		UpdateFunc: func(old, cur interface{}) {
+			sc.enqueueSweepOfTFJob(cur)
+		},
+		DeleteFunc: sc.enqueueSweepOfTFJob,
+	})
+	sc.tfJobInformer = tfJobInformer.Informer()
+	sc.tfJobInformerSynced = tfJobInformer.Informer().HasSynced
+
+	return sc
+}
+
+// Run syncs the informer caches and starts the workers. It blocks until
+// stopCh is closed.
+func (sc *TFJobSweepController) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer sc.workQueue.ShutDown()
+
+	log.Info("Starting TFJobSweep controller")
+
+	if ok := cache.WaitForCacheSync(stopCh, sc.sweepInformerSynced, sc.tfJobInformerSynced); !ok {
+		return fmt.Errorf("failed to wait for tfjobsweep and tfjob caches to sync")
+	}
+
+	log.Infof("Starting %v TFJobSweep workers", threadiness)
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(sc.runWorker, time.Second, stopCh)
+	}
+
+	<-stopCh
+	log.Info("Shutting down TFJobSweep workers")
+
+	return nil
+}
+
+func (sc *TFJobSweepController) runWorker() {
+	for sc.processNextWorkItem() {
+	}
+}
+
+func (sc *TFJobSweepController) processNextWorkItem() bool {
+	key, quit := sc.workQueue.Get()
+	if quit {
+		return false
+	}
+	defer sc.workQueue.Done(key)
+
+	if err := sc.syncHandler(key.(string)); err != nil {
+		utilruntime.HandleError(fmt.Errorf("Error syncing tfjobsweep: %v", err))
+		sc.workQueue.AddRateLimited(key)
+		return true
+	}
+	sc.workQueue.Forget(key)
+	return true
+}
+
+func (sc *TFJobSweepController) enqueueSweep(sweep interface{}) {
+	key, err := KeyFunc(sweep)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for tfjobsweep object %#v: %v", sweep, err))
+		return
+	}
+	sc.workQueue.Add(key)
+}
+
+// enqueueSweepOfTFJob enqueues the sweep controlling the tfjob, if any.
+func (sc *TFJobSweepController) enqueueSweepOfTFJob(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	tfjob, ok := obj.(metav1.Object)
+	if !ok {
+		return
+	}
+	controllerRef := metav1.GetControllerOf(tfjob)
+	if controllerRef == nil || controllerRef.Kind != controllerKind.Kind {
+		return
+	}
+	sc.workQueue.Add(tfjob.GetNamespace() + "/" + controllerRef.Name)
+}
+
+// syncTFJobSweep runs the trials of the sweep with the given key.
+// This function is not meant to be invoked concurrently with the same key.
+func (sc *TFJobSweepController) syncTFJobSweep(key string) error {
+	startTime := time.Now()
+	defer func() {
+		log.Infof("Finished syncing tfjobsweep %q (%v)", key, time.Since(startTime))
+	}()
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	sharedSweep, err := sc.sweepLister.TFJobSweeps(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Infof("TFJobSweep has been deleted: %v", key)
+			return nil
+		}
+		return err
+	}
+	if sharedSweep.DeletionTimestamp != nil {
+		return nil
+	}
+
+	sweep := sharedSweep.DeepCopy()
+	scheme.Scheme.Default(sweep)
+	if isFinished(sweep.Status) {
+		return nil
+	}
+
+	if err := sc.reconcileTrials(sweep); err != nil {
+		return err
+	}
+	if statusEqual(sharedSweep.Status, sweep.Status) {
+		return nil
+	}
+	return sc.updateStatusHandler(sweep)
+}
+
+func (sc *TFJobSweepController) updateSweepStatus(sweep *tfv1alpha2.TFJobSweep) error {
+	_, err := sc.tfJobClientSet.KubeflowV1alpha2().TFJobSweeps(sweep.Namespace).Update(sweep)
+	return err
+}
@@ -0,0 +1,232 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	tfjobfake "github.com/kubeflow/tf-operator/pkg/client/clientset/versioned/fake"
+	tfjobinformers "github.com/kubeflow/tf-operator/pkg/client/informers/externalversions"
+	controller "github.com/kubeflow/tf-operator/pkg/controller.v2"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+	"github.com/kubeflow/tf-operator/pkg/util/testutil"
+)
+
+func newTFJobSweep() *tfv1alpha2.TFJobSweep {
+	return &tfv1alpha2.TFJobSweep{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mnist",
+			Namespace: metav1.NamespaceDefault,
+			UID:       "b6b5bd2c-6c2b-11e8-9c5a-42010a8a0002",
+		},
+		Spec: tfv1alpha2.TFJobSweepSpec{
+			Template: tfv1alpha2.TFJobTemplateSpec{
+				Spec: testutil.NewTFJob(1, 0).Spec,
+			},
+			Parameters: []tfv1alpha2.SweepParameter{
+				{Name: "learning_rate", Values: []string{"0.1", "0.01"}},
+				{Name: "optimizer", Values: []string{"adam", "sgd"}},
+			},
+			Parallelism:           tfv1alpha2.Int32(2),
+			ParameterInjection:    tfv1alpha2.ParameterInjectionArgs,
+			TargetSucceededTrials: tfv1alpha2.Int32(1),
+		},
+	}
+}
+
+func newTFJobSweepController() (*TFJobSweepController, *tfjobfake.Clientset, cache.Indexer, cache.Indexer) {
+	tfJobClientSet := tfjobfake.NewSimpleClientset()
+	tfJobInformerFactory := tfjobinformers.NewSharedInformerFactory(tfJobClientSet, 0)
+	sweepInformer := tfJobInformerFactory.Kubeflow().V1alpha2().TFJobSweeps()
+	config := &rest.Config{
+		Host: "",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion: &tfv1alpha2.SchemeGroupVersion,
+		},
+	}
+	tfJobInformer := controller.NewUnstructuredTFJobInformer(config, 0)
+
+	sc := NewTFJobSweepController(sweepInformer, tfJobInformer, kubefake.NewSimpleClientset(), tfJobClientSet)
+	return sc, tfJobClientSet, sweepInformer.Informer().GetIndexer(), tfJobInformer.Informer().GetIndexer()
+}
+
+// setTrialTFJob adds the created tfjob of a trial to the tfjob indexer, with the given condition.
+func setTrialTFJob(t *testing.T, tfJobClientSet *tfjobfake.Clientset, tfJobIndexer cache.Indexer, name string, condition tfv1alpha2.TFJobConditionType) {
+	tfjob, err := tfJobClientSet.KubeflowV1alpha2().TFJobs(metav1.NamespaceDefault).Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TFJob %s to be created: %v", name, err)
+	}
+	tfjob.TypeMeta = metav1.TypeMeta{Kind: tfv1alpha2.Kind, APIVersion: tfv1alpha2.SchemeGroupVersion.String()}
+	tfjob.Status.Conditions = []tfv1alpha2.TFJobCondition{newCondition(condition, "", "")}
+	unstructured, err := generator.ConvertTFJobToUnstructured(tfjob)
+	if err != nil {
+		t.Fatalf("Failed to convert the TFJob to Unstructured: %v", err)
+	}
+	if err := tfJobIndexer.Update(unstructured); err != nil {
+		t.Fatalf("Failed to add tfjob to tfJobIndexer: %v", err)
+	}
+}
+
+func TestSyncTFJobSweep(t *testing.T) {
+	sweep := newTFJobSweep()
+	sc, tfJobClientSet, sweepIndexer, tfJobIndexer := newTFJobSweepController()
+
+	var actual *tfv1alpha2.TFJobSweep
+	sc.updateStatusHandler = func(sweep *tfv1alpha2.TFJobSweep) error {
+		actual = sweep
+		return nil
+	}
+	sync := func() {
+		if err := sweepIndexer.Update(sweep); err != nil {
+			t.Fatalf("Failed to add tfjobsweep to sweepIndexer: %v", err)
+		}
+		actual = nil
+		if err := sc.syncTFJobSweep(metav1.NamespaceDefault + "/" + sweep.Name); err != nil {
+			t.Fatalf("Unexpected error when syncing the tfjobsweep: %v", err)
+		}
+		if actual == nil {
+			t.Fatalf("Expected the status of the tfjobsweep to be updated")
+		}
+		sweep = actual
+	}
+
+	// The trials are generated and the first ones are run.
+	sync()
+	status := sweep.Status
+	if len(status.Trials) != 4 || status.Running != 2 || status.Pending != 2 {
+		t.Fatalf("Expected 2 running and 2 pending trials, got %+v", status)
+	}
+	tfjob, err := tfJobClientSet.KubeflowV1alpha2().TFJobs(metav1.NamespaceDefault).Get("mnist-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the tfjob of the first trial to be created: %v", err)
+	}
+	if ref := metav1.GetControllerOf(tfjob); ref == nil || ref.UID != sweep.UID {
+		t.Errorf("Expected the tfjob to be controlled by the sweep, got %v", tfjob.OwnerReferences)
+	}
+	// The parameters are appended to the args of the template.
+	args := tfjob.Spec.TFReplicaSpecs[tfv1alpha2.TFReplicaTypeWorker].Template.Spec.Containers[0].Args
+	if len(args) != 4 || args[2] != "--learning_rate=0.1" || args[3] != "--optimizer=adam" {
+		t.Errorf("Expected the parameters of the trial in the args, got %v", args)
+	}
+
+	// A failed trial is replaced by the next one.
+	setTrialTFJob(t, tfJobClientSet, tfJobIndexer, "mnist-0", tfv1alpha2.TFJobFailed)
+	setTrialTFJob(t, tfJobClientSet, tfJobIndexer, "mnist-1", tfv1alpha2.TFJobRunning)
+	sync()
+	status = sweep.Status
+	if status.Failed != 1 || status.Running != 2 || status.Pending != 1 {
+		t.Fatalf("Expected 1 failed, 2 running and 1 pending trials, got %+v", status)
+	}
+	if status.Trials[2].TFJobName != "mnist-2" {
+		t.Errorf("Expected the third trial to run, got %+v", status.Trials[2])
+	}
+
+	// The sweep stops once it reaches its target.
+	setTrialTFJob(t, tfJobClientSet, tfJobIndexer, "mnist-2", tfv1alpha2.TFJobSucceeded)
+	sync()
+	status = sweep.Status
+	if status.Succeeded != 1 || status.Failed != 1 || status.Stopped != 2 || status.Running != 0 || status.Pending != 0 {
+		t.Fatalf("Expected the other trials to be stopped, got %+v", status)
+	}
+	if _, err := tfJobClientSet.KubeflowV1alpha2().TFJobs(metav1.NamespaceDefault).Get("mnist-1", metav1.GetOptions{}); err == nil {
+		t.Errorf("Expected the tfjob of the stopped trial to be deleted")
+	}
+	if !isFinished(status) || status.CompletionTime == nil {
+		t.Errorf("Expected the sweep to be finished, got %+v", status.Conditions)
+	}
+	condition := status.Conditions[len(status.Conditions)-1]
+	if condition.Type != tfv1alpha2.TFJobSucceeded || condition.Status != v1.ConditionTrue {
+		t.Errorf("Expected the sweep to be succeeded, got %+v", condition)
+	}
+}
+
+func TestSyncTFJobSweepCompletion(t *testing.T) {
+	testCases := map[string]struct {
+		target            *int32
+		phases            []tfv1alpha2.TrialPhase
+		expectedCondition tfv1alpha2.TFJobConditionType
+	}{
+		"some trials succeeded": {
+			phases:            []tfv1alpha2.TrialPhase{tfv1alpha2.TrialFailed, tfv1alpha2.TrialSucceeded},
+			expectedCondition: tfv1alpha2.TFJobSucceeded,
+		},
+		"all trials failed": {
+			phases:            []tfv1alpha2.TrialPhase{tfv1alpha2.TrialFailed, tfv1alpha2.TrialFailed},
+			expectedCondition: tfv1alpha2.TFJobFailed,
+		},
+		"target not reached": {
+			target:            tfv1alpha2.Int32(2),
+			phases:            []tfv1alpha2.TrialPhase{tfv1alpha2.TrialFailed, tfv1alpha2.TrialSucceeded},
+			expectedCondition: tfv1alpha2.TFJobFailed,
+		},
+	}
+
+	for name, c := range testCases {
+		sweep := newTFJobSweep()
+		sweep.Spec.Algorithm = tfv1alpha2.SweepAlgorithmList
+		sweep.Spec.Parameters = nil
+		sweep.Spec.Trials = []map[string]string{{"learning_rate": "0.1"}, {"learning_rate": "0.01"}}
+		sweep.Spec.TargetSucceededTrials = c.target
+		now := metav1.Now()
+		sweep.Status.StartTime = &now
+		for i, phase := range c.phases {
+			sweep.Status.Trials = append(sweep.Status.Trials, tfv1alpha2.SweepTrialStatus{
+				Index:     int32(i),
+				TFJobName: genTrialName(sweep.Name, int32(i)),
+				Phase:     phase,
+			})
+		}
+
+		sc, _, sweepIndexer, _ := newTFJobSweepController()
+		var actual *tfv1alpha2.TFJobSweep
+		sc.updateStatusHandler = func(sweep *tfv1alpha2.TFJobSweep) error {
+			actual = sweep
+			return nil
+		}
+		if err := sweepIndexer.Add(sweep); err != nil {
+			t.Fatalf("Failed to add tfjobsweep to sweepIndexer: %v", err)
+		}
+		if err := sc.syncTFJobSweep(metav1.NamespaceDefault + "/" + sweep.Name); err != nil {
+			t.Errorf("%s: unexpected error when syncing the tfjobsweep: %v", name, err)
+			continue
+		}
+		if actual == nil || len(actual.Status.Conditions) == 0 {
+			t.Errorf("%s: expected the tfjobsweep to be completed", name)
+			continue
+		}
+		condition := actual.Status.Conditions[len(actual.Status.Conditions)-1]
+		if condition.Type != c.expectedCondition || actual.Status.CompletionTime == nil {
+			t.Errorf("%s: expected condition %v, got %+v", name, c.expectedCondition, condition)
+		}
+	}
+}
+
+func TestInjectParameters(t *testing.T) {
+	spec := testutil.NewTFJob(2, 1).Spec
+	injectParameters(&spec, map[string]string{"optimizer": "adam", "learning_rate": "0.1"}, tfv1alpha2.ParameterInjectionEnv)
+	for rtype, replicaSpec := range spec.TFReplicaSpecs {
+		env := replicaSpec.Template.Spec.Containers[0].Env
+		if len(env) != 2 || env[0].Name != "learning_rate" || env[0].Value != "0.1" || env[1].Name != "optimizer" {
+			t.Errorf("Expected the parameters in the env of %v, got %v", rtype, env)
+		}
+	}
+}
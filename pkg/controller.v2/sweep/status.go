@@ -0,0 +1,110 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+const (
+	// sweepCreatedReason is added in a tfjobsweep when its trials are generated.
+	sweepCreatedReason = "TFJobSweepCreated"
+	// sweepRunningReason is added in a tfjobsweep when trials are running.
+	sweepRunningReason = "TFJobSweepRunning"
+	// sweepSucceededReason is added in a tfjobsweep when it is succeeded.
+	sweepSucceededReason = "TFJobSweepSucceeded"
+	// sweepFailedReason is added in a tfjobsweep when it is failed.
+	sweepFailedReason = "TFJobSweepFailed"
+	// sweepInvalidReason is added in a tfjobsweep when its spec is invalid.
+	sweepInvalidReason = "TFJobSweepInvalid"
+)
+
+// updateTrialCounts counts the trials in each phase.
+func updateTrialCounts(status *tfv1alpha2.TFJobSweepStatus) {
+	status.Pending, status.Running, status.Succeeded, status.Failed, status.Stopped = 0, 0, 0, 0, 0
+	for _, trial := range status.Trials {
+		switch trial.Phase {
+		case tfv1alpha2.TrialPending:
+			status.Pending++
+		case tfv1alpha2.TrialRunning:
+			status.Running++
+		case tfv1alpha2.TrialSucceeded:
+			status.Succeeded++
+		case tfv1alpha2.TrialFailed:
+			status.Failed++
+		case tfv1alpha2.TrialStopped:
+			status.Stopped++
+		}
+	}
+}
+
+// completeSweep sets the final condition and the completion time of a sweep.
+func completeSweep(status *tfv1alpha2.TFJobSweepStatus, conditionType tfv1alpha2.TFJobConditionType, reason, message string) {
+	setSweepCondition(status, newCondition(conditionType, reason, message))
+	if status.CompletionTime == nil {
+		now := metav1.Now()
+		status.CompletionTime = &now
+	}
+}
+
+// isFinished returns true if the sweep is succeeded or failed.
+func isFinished(status tfv1alpha2.TFJobSweepStatus) bool {
+	for _, condition := range status.Conditions {
+		if (condition.Type == tfv1alpha2.TFJobSucceeded || condition.Type == tfv1alpha2.TFJobFailed) && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func statusEqual(a, b tfv1alpha2.TFJobSweepStatus) bool {
+	return apiequality.Semantic.DeepEqual(a, b)
+}
+
+// newCondition creates a new tfjobsweep condition.
+func newCondition(conditionType tfv1alpha2.TFJobConditionType, reason, message string) tfv1alpha2.TFJobCondition {
+	return tfv1alpha2.TFJobCondition{
+		Type:               conditionType,
+		Status:             v1.ConditionTrue,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// setSweepCondition updates the tfjobsweep to include the provided condition.
+// If the condition that we are about to add already exists
+// and has the same status and reason then we are not going to update.
+func setSweepCondition(status *tfv1alpha2.TFJobSweepStatus, condition tfv1alpha2.TFJobCondition) {
+	var conditions []tfv1alpha2.TFJobCondition
+	for _, c := range status.Conditions {
+		if c.Type != condition.Type {
+			conditions = append(conditions, c)
+			continue
+		}
+		if c.Status == condition.Status && c.Reason == condition.Reason {
+			return
+		}
+		if c.Status == condition.Status {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+	}
+	status.Conditions = append(conditions, condition)
+}
@@ -0,0 +1,297 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+	"github.com/kubeflow/tf-operator/pkg/apis/tensorflow/validation"
+	"github.com/kubeflow/tf-operator/pkg/generator"
+)
+
+const (
+	// Labels of the tfjobs of the trials.
+	sweepNameLabel  = "tf_job_sweep_name"
+	trialIndexLabel = "tf_job_sweep_trial"
+
+	trialCreatedReason = "TrialCreated"
+	trialStoppedReason = "TrialStopped"
+	trialFailedReason  = "TrialFailed"
+)
+
+// reconcileTrials creates the tfjobs of the pending trials, up to the
+// parallelism of the sweep, and updates the status of the trials from their
+// tfjobs.
+func (sc *TFJobSweepController) reconcileTrials(sweep *tfv1alpha2.TFJobSweep) error {
+	if err := validation.ValidateTFJobSweepSpec(&sweep.Spec); err != nil {
+		msg := fmt.Sprintf("TFJobSweep %s is invalid: %v", sweep.Name, err)
+		log.Warn(msg)
+		sc.recorder.Event(sweep, v1.EventTypeWarning, sweepInvalidReason, msg)
+		completeSweep(&sweep.Status, tfv1alpha2.TFJobFailed, sweepInvalidReason, msg)
+		return nil
+	}
+
+	// The trials are generated once, so that the sweep does not change when
+	// the controller restarts.
+	if sweep.Status.StartTime == nil {
+		sweep.Status.Trials = generateTrials(sweep)
+		now := metav1.Now()
+		sweep.Status.StartTime = &now
+		msg := fmt.Sprintf("TFJobSweep %s is created with %d trials.", sweep.Name, len(sweep.Status.Trials))
+		setSweepCondition(&sweep.Status, newCondition(tfv1alpha2.TFJobCreated, sweepCreatedReason, msg))
+	}
+
+	tfjobs, err := sc.getTFJobsForSweep(sweep)
+	if err != nil {
+		return err
+	}
+
+	trials := sweep.Status.Trials
+	for i := range trials {
+		trial := &trials[i]
+		if trial.Phase != tfv1alpha2.TrialRunning {
+			continue
+		}
+		tfjob, ok := tfjobs[trial.TFJobName]
+		if !ok {
+			// The tfjob is not observed yet, or was deleted: it is created again.
+			if err := sc.createTrial(sweep, trial); err != nil {
+				return err
+			}
+			continue
+		}
+		updateTrialPhase(trial, tfjob)
+	}
+
+	updateTrialCounts(&sweep.Status)
+	if target := sweep.Spec.TargetSucceededTrials; target != nil && sweep.Status.Succeeded >= *target {
+		return sc.stopTrials(sweep)
+	}
+
+	running := sweep.Status.Running
+	for i := range trials {
+		if running >= *sweep.Spec.Parallelism {
+			break
+		}
+		trial := &trials[i]
+		if trial.Phase != tfv1alpha2.TrialPending {
+			continue
+		}
+		if err := sc.createTrial(sweep, trial); err != nil {
+			updateTrialCounts(&sweep.Status)
+			return err
+		}
+		if trial.Phase == tfv1alpha2.TrialRunning {
+			running++
+		}
+	}
+
+	updateTrialCounts(&sweep.Status)
+	status := &sweep.Status
+	switch {
+	case status.Pending == 0 && status.Running == 0:
+		sc.completeTrials(sweep)
+	case status.Running > 0:
+		msg := fmt.Sprintf("TFJobSweep %s is running.", sweep.Name)
+		setSweepCondition(status, newCondition(tfv1alpha2.TFJobRunning, sweepRunningReason, msg))
+	}
+	return nil
+}
+
+// createTrial creates the tfjob of the trial and marks it running.
+func (sc *TFJobSweepController) createTrial(sweep *tfv1alpha2.TFJobSweep, trial *tfv1alpha2.SweepTrialStatus) error {
+	tfjob := newTrialTFJob(sweep, trial)
+	_, err := sc.tfJobClientSet.KubeflowV1alpha2().TFJobs(sweep.Namespace).Create(tfjob)
+	if errors.IsAlreadyExists(err) {
+		// The tfjob was created by a previous sync, unless another object took its name.
+		existing, getErr := sc.tfJobClientSet.KubeflowV1alpha2().TFJobs(sweep.Namespace).Get(tfjob.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		if ref := metav1.GetControllerOf(existing); ref == nil || ref.UID != sweep.UID {
+			msg := fmt.Sprintf("TFJob %s of trial %d already exists and is not controlled by TFJobSweep %s.", tfjob.Name, trial.Index, sweep.Name)
+			sc.recorder.Event(sweep, v1.EventTypeWarning, trialFailedReason, msg)
+			now := metav1.Now()
+			trial.Phase = tfv1alpha2.TrialFailed
+			trial.CompletionTime = &now
+			return nil
+		}
+	} else if err != nil {
+		sc.recorder.Eventf(sweep, v1.EventTypeWarning, trialFailedReason, "Error creating TFJob %s of trial %d: %v", tfjob.Name, trial.Index, err)
+		return err
+	} else {
+		sc.recorder.Eventf(sweep, v1.EventTypeNormal, trialCreatedReason, "Created TFJob %s of trial %d", tfjob.Name, trial.Index)
+	}
+	trial.TFJobName = tfjob.Name
+	trial.Phase = tfv1alpha2.TrialRunning
+	return nil
+}
+
+// stopTrials deletes the tfjobs of the running trials once the sweep reached
+// its target, and does not run the pending ones.
+func (sc *TFJobSweepController) stopTrials(sweep *tfv1alpha2.TFJobSweep) error {
+	now := metav1.Now()
+	policy := metav1.DeletePropagationBackground
+	for i := range sweep.Status.Trials {
+		trial := &sweep.Status.Trials[i]
+		switch trial.Phase {
+		case tfv1alpha2.TrialRunning:
+			err := sc.tfJobClientSet.KubeflowV1alpha2().TFJobs(sweep.Namespace).Delete(trial.TFJobName, &metav1.DeleteOptions{PropagationPolicy: &policy})
+			if err != nil && !errors.IsNotFound(err) {
+				updateTrialCounts(&sweep.Status)
+				return err
+			}
+			sc.recorder.Eventf(sweep, v1.EventTypeNormal, trialStoppedReason, "Stopped TFJob %s of trial %d", trial.TFJobName, trial.Index)
+		case tfv1alpha2.TrialPending:
+		default:
+			continue
+		}
+		trial.Phase = tfv1alpha2.TrialStopped
+		trial.CompletionTime = &now
+	}
+	updateTrialCounts(&sweep.Status)
+
+	msg := fmt.Sprintf("TFJobSweep %s reached its target of %d succeeded trials.", sweep.Name, *sweep.Spec.TargetSucceededTrials)
+	sc.recorder.Event(sweep, v1.EventTypeNormal, sweepSucceededReason, msg)
+	completeSweep(&sweep.Status, tfv1alpha2.TFJobSucceeded, sweepSucceededReason, msg)
+	return nil
+}
+
+// completeTrials completes the sweep once all its trials have completed.
+func (sc *TFJobSweepController) completeTrials(sweep *tfv1alpha2.TFJobSweep) {
+	status := &sweep.Status
+	if target := sweep.Spec.TargetSucceededTrials; target != nil {
+		msg := fmt.Sprintf("TFJobSweep %s completed with %d of the %d succeeded trials of its target.", sweep.Name, status.Succeeded, *target)
+		sc.recorder.Event(sweep, v1.EventTypeWarning, sweepFailedReason, msg)
+		completeSweep(status, tfv1alpha2.TFJobFailed, sweepFailedReason, msg)
+		return
+	}
+	if status.Succeeded == 0 {
+		msg := fmt.Sprintf("All the %d trials of TFJobSweep %s failed.", status.Failed, sweep.Name)
+		sc.recorder.Event(sweep, v1.EventTypeWarning, sweepFailedReason, msg)
+		completeSweep(status, tfv1alpha2.TFJobFailed, sweepFailedReason, msg)
+		return
+	}
+	msg := fmt.Sprintf("TFJobSweep %s is completed: %d trials succeeded and %d failed.", sweep.Name, status.Succeeded, status.Failed)
+	sc.recorder.Event(sweep, v1.EventTypeNormal, sweepSucceededReason, msg)
+	completeSweep(status, tfv1alpha2.TFJobSucceeded, sweepSucceededReason, msg)
+}
+
+// getTFJobsForSweep returns the tfjobs controlled by the sweep, by name.
+func (sc *TFJobSweepController) getTFJobsForSweep(sweep *tfv1alpha2.TFJobSweep) (map[string]*tfv1alpha2.TFJob, error) {
+	tfjobs := map[string]*tfv1alpha2.TFJob{}
+	for _, obj := range sc.tfJobInformer.GetIndexer().List() {
+		un, ok := obj.(*metav1unstructured.Unstructured)
+		if !ok || un.GetNamespace() != sweep.Namespace {
+			continue
+		}
+		if ref := metav1.GetControllerOf(un); ref == nil || ref.UID != sweep.UID {
+			continue
+		}
+		var tfjob tfv1alpha2.TFJob
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, &tfjob); err != nil {
+			return nil, err
+		}
+		tfjobs[tfjob.Name] = &tfjob
+	}
+	return tfjobs, nil
+}
+
+// updateTrialPhase updates the phase of a running trial from its tfjob.
+func updateTrialPhase(trial *tfv1alpha2.SweepTrialStatus, tfjob *tfv1alpha2.TFJob) {
+	for _, condition := range tfjob.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case tfv1alpha2.TFJobSucceeded:
+			trial.Phase = tfv1alpha2.TrialSucceeded
+		case tfv1alpha2.TFJobFailed:
+			trial.Phase = tfv1alpha2.TrialFailed
+		default:
+			continue
+		}
+		completionTime := condition.LastTransitionTime
+		trial.CompletionTime = &completionTime
+		return
+	}
+}
+
+// genTrialName returns the name of the tfjob of a trial.
+func genTrialName(sweepName string, index int32) string {
+	return fmt.Sprintf("%s-%d", sweepName, index)
+}
+
+// newTrialTFJob returns the tfjob of a trial, created from the template of
+// the sweep with the parameters of the trial.
+func newTrialTFJob(sweep *tfv1alpha2.TFJobSweep, trial *tfv1alpha2.SweepTrialStatus) *tfv1alpha2.TFJob {
+	template := sweep.Spec.Template.DeepCopy()
+	labels := template.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[generator.LabelGroupName] = tfv1alpha2.GroupName
+	labels[sweepNameLabel] = sweep.Name
+	labels[trialIndexLabel] = strconv.Itoa(int(trial.Index))
+
+	tfjob := &tfv1alpha2.TFJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            genTrialName(sweep.Name, trial.Index),
+			Namespace:       sweep.Namespace,
+			Labels:          labels,
+			Annotations:     template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sweep, controllerKind)},
+		},
+		Spec: template.Spec,
+	}
+	injectParameters(&tfjob.Spec, trial.Parameters, sweep.Spec.ParameterInjection)
+	return tfjob
+}
+
+// injectParameters passes the parameters to the tensorflow containers of
+// every replica, sorted by name.
+func injectParameters(spec *tfv1alpha2.TFJobSpec, parameters map[string]string, injection tfv1alpha2.ParameterInjection) {
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, replicaSpec := range spec.TFReplicaSpecs {
+		containers := replicaSpec.Template.Spec.Containers
+		for i := range containers {
+			if containers[i].Name != tfv1alpha2.DefaultContainerName {
+				continue
+			}
+			for _, name := range names {
+				if injection == tfv1alpha2.ParameterInjectionArgs {
+					containers[i].Args = append(containers[i].Args, fmt.Sprintf("--%s=%s", name, parameters[name]))
+				} else {
+					containers[i].Env = append(containers[i].Env, v1.EnvVar{Name: name, Value: parameters[name]})
+				}
+			}
+		}
+	}
+}
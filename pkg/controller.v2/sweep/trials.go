@@ -0,0 +1,147 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strconv"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+// generateTrials returns the trials of the sweep, in the order they are run.
+// The trials only depend on the spec and the UID of the sweep, so that they
+// are the same if they have to be generated again.
+func generateTrials(sweep *tfv1alpha2.TFJobSweep) []tfv1alpha2.SweepTrialStatus {
+	spec := &sweep.Spec
+	var parameters []map[string]string
+	switch spec.Algorithm {
+	case tfv1alpha2.SweepAlgorithmGrid:
+		parameters = gridTrials(spec.Parameters)
+	case tfv1alpha2.SweepAlgorithmRandom:
+		parameters = randomTrials(spec.Parameters, int(*spec.MaxTrials), seedOf(sweep))
+	case tfv1alpha2.SweepAlgorithmList:
+		parameters = spec.Trials
+	}
+	if spec.MaxTrials != nil && len(parameters) > int(*spec.MaxTrials) {
+		parameters = parameters[:*spec.MaxTrials]
+	}
+
+	trials := make([]tfv1alpha2.SweepTrialStatus, 0, len(parameters))
+	for i, p := range parameters {
+		trial := tfv1alpha2.SweepTrialStatus{
+			Index:      int32(i),
+			Parameters: map[string]string{},
+			Phase:      tfv1alpha2.TrialPending,
+		}
+		for name, value := range p {
+			trial.Parameters[name] = value
+		}
+		trials = append(trials, trial)
+	}
+	return trials
+}
+
+// seedOf returns the seed of the Random algorithm, which is derived from the
+// UID of the sweep if unspecified.
+func seedOf(sweep *tfv1alpha2.TFJobSweep) int64 {
+	if sweep.Spec.Seed != nil {
+		return *sweep.Spec.Seed
+	}
+	h := fnv.New64a()
+	h.Write([]byte(sweep.UID))
+	return int64(h.Sum64())
+}
+
+// gridTrials returns a trial for each combination of the values of the
+// parameters. The last parameter varies the fastest.
+func gridTrials(parameters []tfv1alpha2.SweepParameter) []map[string]string {
+	trials := []map[string]string{{}}
+	for _, p := range parameters {
+		values := p.Values
+		if len(values) == 0 {
+			values = rangeValues(p.Range)
+		}
+		var next []map[string]string
+		for _, trial := range trials {
+			for _, value := range values {
+				t := map[string]string{p.Name: value}
+				for name, v := range trial {
+					t[name] = v
+				}
+				next = append(next, t)
+			}
+		}
+		trials = next
+	}
+	return trials
+}
+
+// randomTrials returns n trials with values picked at random.
+func randomTrials(parameters []tfv1alpha2.SweepParameter, n int, seed int64) []map[string]string {
+	r := rand.New(rand.NewSource(seed))
+	trials := make([]map[string]string, 0, n)
+	for i := 0; i < n; i++ {
+		trial := map[string]string{}
+		for _, p := range parameters {
+			if len(p.Values) != 0 {
+				trial[p.Name] = p.Values[r.Intn(len(p.Values))]
+			} else {
+				trial[p.Name] = formatValue(p.Range, scaleValue(p.Range, r.Float64()))
+			}
+		}
+		trials = append(trials, trial)
+	}
+	return trials
+}
+
+// rangeValues returns the Steps values of the range, evenly spaced on its
+// scale from Min to Max included.
+func rangeValues(pr *tfv1alpha2.ParameterRange) []string {
+	steps := int(pr.Steps)
+	values := make([]string, 0, steps)
+	for i := 0; i < steps; i++ {
+		t := 0.0
+		if steps > 1 {
+			t = float64(i) / float64(steps-1)
+		}
+		value := formatValue(pr, scaleValue(pr, t))
+		// Integer ranges may round several steps to the same value.
+		if len(values) > 0 && values[len(values)-1] == value {
+			continue
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// scaleValue returns the value at the fraction t of the range.
+func scaleValue(pr *tfv1alpha2.ParameterRange, t float64) float64 {
+	if pr.Scale == tfv1alpha2.ParameterScaleLog {
+		min, max := math.Log(pr.Min), math.Log(pr.Max)
+		return math.Exp(min + t*(max-min))
+	}
+	return pr.Min + t*(pr.Max-pr.Min)
+}
+
+func formatValue(pr *tfv1alpha2.ParameterRange, value float64) string {
+	if pr.Integer {
+		return strconv.FormatInt(int64(math.Floor(value+0.5)), 10)
+	}
+	// Round off the errors of the logarithms, e.g. 0.010000000000000002.
+	return strconv.FormatFloat(value, 'g', 10, 64)
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	tfv1alpha2 "github.com/kubeflow/tf-operator/pkg/apis/tensorflow/v1alpha2"
+)
+
+func TestGenerateTrials(t *testing.T) {
+	parameters := []tfv1alpha2.SweepParameter{
+		{Name: "learning_rate", Range: &tfv1alpha2.ParameterRange{Min: 0.001, Max: 0.1, Steps: 3, Scale: tfv1alpha2.ParameterScaleLog}},
+		{Name: "optimizer", Values: []string{"adam", "sgd"}},
+	}
+
+	testCases := map[string]struct {
+		spec     tfv1alpha2.TFJobSweepSpec
+		expected []map[string]string
+	}{
+		"grid": {
+			spec: tfv1alpha2.TFJobSweepSpec{
+				Algorithm:  tfv1alpha2.SweepAlgorithmGrid,
+				Parameters: parameters,
+			},
+			expected: []map[string]string{
+				{"learning_rate": "0.001", "optimizer": "adam"},
+				{"learning_rate": "0.001", "optimizer": "sgd"},
+				{"learning_rate": "0.01", "optimizer": "adam"},
+				{"learning_rate": "0.01", "optimizer": "sgd"},
+				{"learning_rate": "0.1", "optimizer": "adam"},
+				{"learning_rate": "0.1", "optimizer": "sgd"},
+			},
+		},
+		"grid capped by maxTrials": {
+			spec: tfv1alpha2.TFJobSweepSpec{
+				Algorithm:  tfv1alpha2.SweepAlgorithmGrid,
+				Parameters: parameters,
+				MaxTrials:  tfv1alpha2.Int32(2),
+			},
+			expected: []map[string]string{
+				{"learning_rate": "0.001", "optimizer": "adam"},
+				{"learning_rate": "0.001", "optimizer": "sgd"},
+			},
+		},
+		"integer range": {
+			spec: tfv1alpha2.TFJobSweepSpec{
+				Algorithm: tfv1alpha2.SweepAlgorithmGrid,
+				Parameters: []tfv1alpha2.SweepParameter{
+					{Name: "batch_size", Range: &tfv1alpha2.ParameterRange{Min: 1, Max: 2, Steps: 4, Integer: true, Scale: tfv1alpha2.ParameterScaleLinear}},
+				},
+			},
+			expected: []map[string]string{
+				{"batch_size": "1"},
+				{"batch_size": "2"},
+			},
+		},
+		"list": {
+			spec: tfv1alpha2.TFJobSweepSpec{
+				Algorithm: tfv1alpha2.SweepAlgorithmList,
+				Trials:    []map[string]string{{"learning_rate": "0.5"}, {"learning_rate": "0.05"}},
+			},
+			expected: []map[string]string{
+				{"learning_rate": "0.5"},
+				{"learning_rate": "0.05"},
+			},
+		},
+	}
+
+	for name, c := range testCases {
+		trials := generateTrials(&tfv1alpha2.TFJobSweep{Spec: c.spec})
+		var actual []map[string]string
+		for i, trial := range trials {
+			if trial.Index != int32(i) || trial.Phase != tfv1alpha2.TrialPending {
+				t.Errorf("%s: expected trial %d to be pending, got %+v", name, i, trial)
+			}
+			actual = append(actual, trial.Parameters)
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected trials %v, got %v", name, c.expected, actual)
+		}
+	}
+}
+
+func TestRandomTrials(t *testing.T) {
+	sweep := &tfv1alpha2.TFJobSweep{
+		Spec: tfv1alpha2.TFJobSweepSpec{
+			Algorithm: tfv1alpha2.SweepAlgorithmRandom,
+			Parameters: []tfv1alpha2.SweepParameter{
+				{Name: "learning_rate", Range: &tfv1alpha2.ParameterRange{Min: 0.001, Max: 0.1, Scale: tfv1alpha2.ParameterScaleLog}},
+				{Name: "optimizer", Values: []string{"adam", "sgd"}},
+			},
+			MaxTrials: tfv1alpha2.Int32(20),
+		},
+	}
+	sweep.UID = "b6b5bd2c-6c2b-11e8-9c5a-42010a8a0002"
+
+	trials := generateTrials(sweep)
+	if len(trials) != 20 {
+		t.Fatalf("Expected 20 trials, got %d", len(trials))
+	}
+	for _, trial := range trials {
+		lr, err := strconv.ParseFloat(trial.Parameters["learning_rate"], 64)
+		if err != nil || lr < 0.001 || lr > 0.1 {
+			t.Errorf("Expected a learning rate in the range, got %q", trial.Parameters["learning_rate"])
+		}
+		if optimizer := trial.Parameters["optimizer"]; optimizer != "adam" && optimizer != "sgd" {
+			t.Errorf("Expected one of the values, got %q", optimizer)
+		}
+	}
+
+	// The trials are the same for the same sweep, and differ with the seed.
+	if again := generateTrials(sweep); !reflect.DeepEqual(trials, again) {
+		t.Errorf("Expected the same trials, got %v and %v", trials, again)
+	}
+	sweep.Spec.Seed = tfv1alpha2.Int64(42)
+	if other := generateTrials(sweep); reflect.DeepEqual(trials, other) {
+		t.Errorf("Expected other trials with another seed")
+	}
+}
@@ -27,7 +27,7 @@ import (
 
 const (
 	LabelGroupName = "group_name"
-	labelTFJobKey  = "tf_job_key"
+	LabelTFJobKey  = "tf_job_key"
 )
 
 var (
@@ -51,7 +51,7 @@ func GenOwnerReference(tfjob *tfv1alpha2.TFJob) *metav1.OwnerReference {
 func GenLabels(tfjobKey string) map[string]string {
 	return map[string]string{
 		LabelGroupName: tfv1alpha2.GroupName,
-		labelTFJobKey:  strings.Replace(tfjobKey, "/", "-", -1),
+		LabelTFJobKey:  strings.Replace(tfjobKey, "/", "-", -1),
 	}
 }
 
@@ -0,0 +1,74 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2018, time.June, 13, 10, 30, 15, 0, time.UTC)
+
+	testCases := []struct {
+		schedule string
+		expected time.Time
+	}{
+		{"*/15 * * * *", time.Date(2018, time.June, 13, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2018, time.June, 14, 10, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2018, time.June, 14, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2018, time.June, 13, 11, 0, 0, 0, time.UTC)},
+		{"0 2 * * SAT,sun", time.Date(2018, time.June, 16, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * 7", time.Date(2018, time.June, 17, 2, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan-mar *", time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"5/20 9-17/4 * * *", time.Date(2018, time.June, 13, 13, 5, 0, 0, time.UTC)},
+		// The day of month or the day of week matches, Friday is the first.
+		{"0 0 20 * 5", time.Date(2018, time.June, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, c := range testCases {
+		s, err := Parse(c.schedule)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.schedule, err)
+			continue
+		}
+		if next := s.Next(now); !next.Equal(c.expected) {
+			t.Errorf("%q: expected %v, got %v", c.schedule, c.expected, next)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, schedule := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every 5m",
+	} {
+		if _, err := Parse(schedule); err == nil {
+			t.Errorf("%q: expected an error", schedule)
+		}
+	}
+}